@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+
+	"quickflow/pkg/locale"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +19,9 @@ type Config struct {
 	Logging  LoggingConfig
 	API      APIConfig
 	Security SecurityConfig
+	Workflow WorkflowConfig
+	Content  ContentConfig
+	Storage  StorageConfig
 }
 
 // ServerConfig holds HTTP server specific configuration
@@ -49,11 +55,66 @@ type LoggingConfig struct {
 // APIConfig holds API specific configuration
 type APIConfig struct {
 	Version string
+	// MaxPageSize caps the number of records returned by a single listing
+	MaxPageSize int
 }
 
 // SecurityConfig holds security specific configuration
 type SecurityConfig struct {
 	JWTSecret string
+	// TokenTTLHours is the lifetime of issued access tokens
+	TokenTTLHours int
+}
+
+// WorkflowConfig holds content workflow specific configuration
+type WorkflowConfig struct {
+	// ScheduleIntervalSeconds is how often due publish/unpublish schedules are checked
+	ScheduleIntervalSeconds int
+}
+
+// ContentConfig holds localized content specific configuration
+type ContentConfig struct {
+	// Locales lists the locales localized columns may hold
+	Locales []string
+	// FallbackLocales is the order in which reads fall back when a translation is missing;
+	// empty falls back through Locales in order
+	FallbackLocales []string
+}
+
+// StorageConfig holds asset storage specific configuration
+type StorageConfig struct {
+	// Backend is local or s3
+	Backend string
+	// LocalDir is the directory the local backend keeps assets in
+	LocalDir string
+	// MaxUploadMB caps the size of a single upload
+	MaxUploadMB int
+	S3          S3Config
+	Transform   TransformConfig
+}
+
+// TransformConfig holds the limits of on-the-fly image transformations
+type TransformConfig struct {
+	// MaxDimension caps the width and height of a transformed image
+	MaxDimension int
+	// MaxSourceMegapixels caps the size of images that are decoded for a transformation
+	MaxSourceMegapixels int
+	// Sizes are the only widths and heights that may be requested
+	Sizes []int
+	// MaxVariants caps the number of derivatives kept per asset, since crops and
+	// qualities are not bounded by Sizes
+	MaxVariants int
+}
+
+// S3Config holds the settings of an S3 compatible bucket such as AWS S3 or MinIO
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// UsePathStyle addresses the bucket in the path, as MinIO expects, instead of the host name
+	UsePathStyle bool
 }
 
 // ConfigOption is a function type for configuration options
@@ -90,10 +151,38 @@ func LoadConfig(opts ...ConfigOption) (*Config, error) {
 			Level: getEnv("LOG_LEVEL", "info"),
 		},
 		API: APIConfig{
-			Version: getEnv("API_VERSION", "v1"),
+			Version:     getEnv("API_VERSION", "v1"),
+			MaxPageSize: getEnvAsInt("API_MAX_PAGE_SIZE", 100),
 		},
 		Security: SecurityConfig{
-			JWTSecret: getEnv("JWT_SECRET", ""),
+			JWTSecret:     getEnv("JWT_SECRET", ""),
+			TokenTTLHours: getEnvAsInt("JWT_TTL_HOURS", 24),
+		},
+		Workflow: WorkflowConfig{
+			ScheduleIntervalSeconds: getEnvAsInt("WORKFLOW_SCHEDULE_INTERVAL_SECONDS", 30),
+		},
+		Content: ContentConfig{
+			Locales:         getEnvAsList("CONTENT_LOCALES", []string{"ja", "en"}),
+			FallbackLocales: getEnvAsList("CONTENT_FALLBACK_LOCALES", nil),
+		},
+		Storage: StorageConfig{
+			Backend:     getEnv("ASSET_STORAGE", "local"),
+			LocalDir:    getEnv("ASSET_LOCAL_DIR", "./data/assets"),
+			MaxUploadMB: getEnvAsInt("ASSET_MAX_UPLOAD_MB", 20),
+			S3: S3Config{
+				Endpoint:        getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+				Region:          getEnv("S3_REGION", "us-east-1"),
+				Bucket:          getEnv("S3_BUCKET", ""),
+				AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+				SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+				UsePathStyle:    getEnvAsBool("S3_USE_PATH_STYLE", false),
+			},
+			Transform: TransformConfig{
+				MaxDimension:        getEnvAsInt("ASSET_TRANSFORM_MAX_DIMENSION", 2048),
+				MaxSourceMegapixels: getEnvAsInt("ASSET_TRANSFORM_MAX_SOURCE_MEGAPIXELS", 40),
+				Sizes:               getEnvAsIntList("ASSET_TRANSFORM_SIZES", []int{64, 128, 256, 512, 1024, 2048}),
+				MaxVariants:         getEnvAsInt("ASSET_TRANSFORM_MAX_VARIANTS", 32),
+			},
 		},
 	}
 
@@ -118,10 +207,66 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("JWT_SECRET must be set")
 	}
 
+	if c.Security.TokenTTLHours < 1 {
+		return fmt.Errorf("invalid token TTL: %d hours", c.Security.TokenTTLHours)
+	}
+
 	if c.Server.Port < 0 || c.Server.Port > 65535 {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
 	}
 
+	if c.API.MaxPageSize < 1 {
+		return fmt.Errorf("invalid API max page size: %d", c.API.MaxPageSize)
+	}
+
+	if c.Workflow.ScheduleIntervalSeconds < 1 {
+		return fmt.Errorf("invalid workflow schedule interval: %d seconds", c.Workflow.ScheduleIntervalSeconds)
+	}
+
+	if _, err := locale.NewSettings(c.Content.Locales, c.Content.FallbackLocales); err != nil {
+		return fmt.Errorf("invalid content locales: %w", err)
+	}
+
+	switch c.Storage.Backend {
+	case "local":
+		if c.Storage.LocalDir == "" {
+			return fmt.Errorf("ASSET_LOCAL_DIR must be set for local asset storage")
+		}
+	case "s3":
+		if c.Storage.S3.Bucket == "" || c.Storage.S3.AccessKeyID == "" || c.Storage.S3.SecretAccessKey == "" {
+			return fmt.Errorf("S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY must be set for s3 asset storage")
+		}
+	default:
+		return fmt.Errorf("invalid asset storage backend: %s", c.Storage.Backend)
+	}
+
+	if c.Storage.MaxUploadMB < 1 {
+		return fmt.Errorf("invalid asset max upload size: %d MB", c.Storage.MaxUploadMB)
+	}
+
+	// WebP の上限 16383 px を超える出力は書き出せない
+	if c.Storage.Transform.MaxDimension < 1 || c.Storage.Transform.MaxDimension > 16383 {
+		return fmt.Errorf("invalid asset transform max dimension: %d", c.Storage.Transform.MaxDimension)
+	}
+
+	if c.Storage.Transform.MaxSourceMegapixels < 1 {
+		return fmt.Errorf("invalid asset transform max source size: %d megapixels", c.Storage.Transform.MaxSourceMegapixels)
+	}
+
+	if len(c.Storage.Transform.Sizes) == 0 {
+		return fmt.Errorf("ASSET_TRANSFORM_SIZES must list at least one size")
+	}
+
+	for _, size := range c.Storage.Transform.Sizes {
+		if size < 1 || size > c.Storage.Transform.MaxDimension {
+			return fmt.Errorf("invalid asset transform size: %d", size)
+		}
+	}
+
+	if c.Storage.Transform.MaxVariants < 1 {
+		return fmt.Errorf("invalid asset transform max variants: %d", c.Storage.Transform.MaxVariants)
+	}
+
 	// Add more validation as needed
 	return nil
 }
@@ -148,6 +293,45 @@ func getEnvAsInt(name string, defaultVal int) int {
 	return defaultVal
 }
 
+// getEnvAsIntList reads a comma separated list of integers. Entries that are not
+// integers are kept as 0 so that validation reports them instead of dropping them.
+func getEnvAsIntList(name string, defaultVal []int) []int {
+	items := getEnvAsList(name, nil)
+	if items == nil {
+		return defaultVal
+	}
+
+	values := make([]int, len(items))
+	for i, item := range items {
+		values[i], _ = strconv.Atoi(item)
+	}
+	return values
+}
+
+// getEnvAsBool is a helper function to read an environment variable as boolean
+func getEnvAsBool(name string, defaultVal bool) bool {
+	if value, err := strconv.ParseBool(getEnv(name, "")); err == nil {
+		return value
+	}
+	return defaultVal
+}
+
+// getEnvAsList is a helper function to read a comma separated environment variable
+func getEnvAsList(name string, defaultVal []string) []string {
+	valueStr := getEnv(name, "")
+	if valueStr == "" {
+		return defaultVal
+	}
+
+	var values []string
+	for _, value := range strings.Split(valueStr, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // GetDSN returns a DSN string for database connection
 func (c *Config) GetDSN() string {
 	return fmt.Sprintf(
@@ -172,6 +356,12 @@ func WithCustomServerPort(port int) ConfigOption {
 	}
 }
 
+// LocaleSettings returns the validated content locales
+func (c *Config) LocaleSettings() locale.Settings {
+	settings, _ := locale.NewSettings(c.Content.Locales, c.Content.FallbackLocales)
+	return settings
+}
+
 // GetRedisAddr returns a formatted Redis address
 func (c *Config) GetRedisAddr() string {
 	return fmt.Sprintf("%s:%d", c.Redis.Host, c.Redis.Port)
@@ -0,0 +1,337 @@
+// File: internal/application/asset/service.go
+
+package asset
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"quickflow/internal/application/auth"
+	"quickflow/internal/domain/asset"
+	"quickflow/internal/domain/user"
+	"quickflow/pkg/errors"
+	"quickflow/pkg/logger"
+
+	// 寸法を読み取れる画像形式を登録する
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultPageSize and maxPageSize bound asset listings
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// maxFilenameLength matches the filename column of assets
+const maxFilenameLength = 255
+
+// extensionPattern accepts the file extensions kept in storage keys
+var extensionPattern = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+// Storage keeps asset content. Keys are slash separated paths chosen by the service.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Repository keeps asset metadata. List returns assets newest first; before, when set,
+// is the last asset of the previous page.
+type Repository interface {
+	Create(ctx context.Context, a *asset.Asset) error
+	Get(ctx context.Context, id uuid.UUID) (*asset.Asset, error)
+	List(ctx context.Context, before *asset.Asset, limit int) ([]*asset.Asset, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetDerivative(ctx context.Context, assetID uuid.UUID, variant string) (*asset.Derivative, error)
+	CreateDerivative(ctx context.Context, d *asset.Derivative, maxVariants int) error
+	CountDerivatives(ctx context.Context, assetID uuid.UUID) (int64, error)
+	ListDerivatives(ctx context.Context, assetID uuid.UUID) ([]*asset.Derivative, error)
+	IsReferencedByRecords(ctx context.Context, assetID uuid.UUID) (bool, error)
+}
+
+// Upload is an uploaded file. ContentType is the type declared by the client and is only
+// used when the content itself does not reveal its type.
+type Upload struct {
+	Filename    string
+	ContentType string
+	Body        io.Reader
+}
+
+// ListParams are the options of an asset listing
+type ListParams struct {
+	Limit  int
+	Cursor string
+}
+
+// ListResult is the response body of an asset listing
+type ListResult struct {
+	Data       []*asset.Asset `json:"data"`
+	Limit      int            `json:"limit"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+type Service struct {
+	repo    Repository
+	storage Storage
+	maxSize int64
+	limits  TransformLimits
+	// inflight and transforms deduplicate and bound image transformations
+	inflight   singleflight.Group
+	transforms chan struct{}
+}
+
+func NewService(repo Repository, storage Storage, maxSize int64, limits TransformLimits) *Service {
+	return &Service{
+		repo:       repo,
+		storage:    storage,
+		maxSize:    maxSize,
+		limits:     limits,
+		transforms: make(chan struct{}, runtime.NumCPU()),
+	}
+}
+
+// MaxSize is the largest upload accepted, in bytes
+func (s *Service) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload stores the content of a file and records its metadata
+func (s *Service) Upload(ctx context.Context, upload Upload) (*asset.Asset, error) {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return nil, errors.NewAppError(errors.ErrorTypeUnauthorized, "Uploading assets needs an authenticated user", nil)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(upload.Body, s.maxSize+1))
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "Failed to read uploaded file", err)
+	}
+	if len(data) == 0 {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "Uploaded file is empty", nil)
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, fmt.Sprintf("Uploaded file exceeds %d bytes", s.maxSize), nil)
+	}
+
+	sum := sha256.Sum256(data)
+	uploader := principal.UserID
+	filename := cleanFilename(upload.Filename)
+	a := &asset.Asset{
+		ID:         uuid.New(),
+		Filename:   filename,
+		MimeType:   detectMimeType(data, filename, upload.ContentType),
+		Size:       int64(len(data)),
+		Checksum:   hex.EncodeToString(sum[:]),
+		UploadedBy: &uploader,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if a.IsImage() {
+		// SVG や未対応の形式は寸法なしで保存する
+		if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+			a.Width, a.Height = &cfg.Width, &cfg.Height
+		}
+	}
+	a.StorageKey = storageKey(a)
+
+	if err := s.storage.Put(ctx, a.StorageKey, bytes.NewReader(data), a.Size, a.MimeType); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, a); err != nil {
+		if err := s.storage.Delete(ctx, a.StorageKey); err != nil {
+			logger.Warn("Failed to remove orphaned asset content", "key", a.StorageKey, "error", err)
+		}
+		return nil, err
+	}
+
+	a.URL = asset.ContentURL(a.ID)
+	return a, nil
+}
+
+// Get returns the metadata of an asset
+func (s *Service) Get(ctx context.Context, id string) (*asset.Asset, error) {
+	assetID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeNotFound, "Asset not found", nil)
+	}
+	a, err := s.repo.Get(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	a.URL = asset.ContentURL(a.ID)
+	return a, nil
+}
+
+// Content opens the content of an asset; the caller closes it
+func (s *Service) Content(ctx context.Context, a *asset.Asset) (io.ReadCloser, error) {
+	return s.storage.Open(ctx, a.StorageKey)
+}
+
+// List returns a page of assets, newest first. Anonymous callers reach assets only
+// through the content that references them, so listing needs an authenticated user.
+func (s *Service) List(ctx context.Context, params ListParams) (*ListResult, error) {
+	if _, ok := auth.PrincipalFrom(ctx); !ok {
+		return nil, errors.NewAppError(errors.ErrorTypeUnauthorized, "Listing assets needs an authenticated user", nil)
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	var before *asset.Asset
+	if params.Cursor != "" {
+		var err error
+		if before, err = decodeCursor(params.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// 1 件多く読み、次のページがあるかを判定する
+	assets, err := s.repo.List(ctx, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ListResult{Data: assets, Limit: limit}
+	if len(assets) > limit {
+		result.Data = assets[:limit]
+		result.NextCursor = encodeCursor(result.Data[limit-1])
+	}
+	for _, a := range result.Data {
+		a.URL = asset.ContentURL(a.ID)
+	}
+	return result, nil
+}
+
+// Delete removes an asset that no record, published version or revision references. Only the uploader and admins may delete it.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return errors.NewAppError(errors.ErrorTypeUnauthorized, "Deleting assets needs an authenticated user", nil)
+	}
+
+	a, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if principal.Role != user.RoleAdmin && (a.UploadedBy == nil || *a.UploadedBy != principal.UserID) {
+		return errors.NewAppError(errors.ErrorTypeForbidden, "Only the uploader or an admin may delete this asset", nil)
+	}
+
+	// 公開スナップショットや履歴は外部キーで守られないため、ここで参照を確かめる
+	referenced, err := s.repo.IsReferencedByRecords(ctx, a.ID)
+	if err != nil {
+		return err
+	}
+	if referenced {
+		return errors.NewAppError(errors.ErrorTypeValidation, "Asset is still in use by a published version or the history of a record", nil)
+	}
+
+	// 派生画像の行は削除時に連鎖して消えるため、先にキーを控えておく
+	derivatives, err := s.repo.ListDerivatives(ctx, a.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, a.ID); err != nil {
+		return err
+	}
+	// メタデータが消えた後の削除失敗は孤立ファイルになるだけなので警告に留める
+	keys := []string{a.StorageKey}
+	for _, d := range derivatives {
+		keys = append(keys, d.StorageKey)
+	}
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			logger.Warn("Failed to delete asset content", "key", key, "error", err)
+		}
+	}
+	return nil
+}
+
+// detectMimeType trusts the content first. Types the content cannot tell apart, such as
+// plain text, XML and unknown binaries, are taken from the file extension or the declared type.
+func detectMimeType(data []byte, filename, declared string) string {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	switch sniffed {
+	case "application/octet-stream", "text/plain", "text/xml":
+	default:
+		return sniffed
+	}
+	if byExtension, _, err := mime.ParseMediaType(mime.TypeByExtension(filepath.Ext(filename))); err == nil {
+		return byExtension
+	}
+	if d, _, err := mime.ParseMediaType(declared); err == nil {
+		return d
+	}
+	return sniffed
+}
+
+// cleanFilename drops any client side directories and caps the length of a file name
+func cleanFilename(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	if runes := []rune(name); len(runes) > maxFilenameLength {
+		name = string(runes[:maxFilenameLength])
+	}
+	return name
+}
+
+// storageKey spreads assets over monthly directories and keeps a safe extension for readability
+func storageKey(a *asset.Asset) string {
+	key := a.CreatedAt.Format("2006/01") + "/" + a.ID.String()
+	if ext := strings.ToLower(filepath.Ext(a.Filename)); extensionPattern.MatchString(ext) {
+		key += ext
+	}
+	return key
+}
+
+func encodeCursor(a *asset.Asset) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(a.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + a.ID.String()))
+}
+
+func decodeCursor(cursor string) (*asset.Asset, error) {
+	invalid := errors.NewAppError(errors.ErrorTypeValidation, "Invalid cursor", nil)
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	createdAt, id, ok := strings.Cut(string(b), "|")
+	if !ok {
+		return nil, invalid
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, invalid
+	}
+	assetID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, invalid
+	}
+	return &asset.Asset{ID: assetID, CreatedAt: t}, nil
+}
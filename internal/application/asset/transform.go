@@ -0,0 +1,304 @@
+// File: internal/application/asset/transform.go
+
+package asset
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"quickflow/internal/domain/asset"
+	"quickflow/pkg/errors"
+	"quickflow/pkg/imaging"
+	"quickflow/pkg/logger"
+)
+
+// defaultQuality is the JPEG quality of transformations that do not ask for one
+const defaultQuality = 80
+
+// TransformParams are the query parameters of an image transformation. Zero values keep
+// the original: no crop, its size and its format.
+type TransformParams struct {
+	Width  int
+	Height int
+	Fit    string
+	Format string
+	// Quality applies to JPEG output only
+	Quality int
+	// Crop selects a region of the original as "x,y,width,height" in pixels
+	Crop string
+}
+
+// IsZero reports whether no transformation was asked for
+func (p TransformParams) IsZero() bool {
+	return p == TransformParams{}
+}
+
+// TransformLimits bound what transformations may ask for
+type TransformLimits struct {
+	// MaxDimension caps the width and height of a transformed image
+	MaxDimension int
+	// MaxSourcePixels caps the size of the images that are decoded
+	MaxSourcePixels int64
+	// Sizes, when not empty, are the only widths and heights that may be requested
+	Sizes []int
+	// MaxVariants, when positive, caps the number of derivatives kept per asset
+	MaxVariants int
+}
+
+// transform is a validated transformation of one asset
+type transform struct {
+	options imaging.Options
+	format  imaging.Format
+	quality int
+	width   int
+	height  int
+	// region is the part of the original that is scaled to width x height
+	region image.Rectangle
+}
+
+// variant names a transformation by its result rather than by its parameters, so that
+// requests producing the same image share one derivative
+func (t *transform) variant() string {
+	name := fmt.Sprintf("%d,%d,%d,%d-%dx%d",
+		t.region.Min.X, t.region.Min.Y, t.region.Dx(), t.region.Dy(), t.width, t.height)
+	if t.format == imaging.JPEG {
+		name += "-q" + strconv.Itoa(t.quality)
+	}
+	return name + t.format.Extension()
+}
+
+// Transform returns the derivative of an image asset for the given parameters, making and
+// caching it on the first request. Anyone may ask for a variant, so that transformed URLs work
+// in plain <img> tags; the allowed sizes and MaxVariants bound what can be made. Animated GIFs
+// are transformed from their first frame.
+func (s *Service) Transform(ctx context.Context, a *asset.Asset, params TransformParams) (*asset.Derivative, error) {
+	t, err := s.prepareTransform(a, params)
+	if err != nil {
+		return nil, err
+	}
+	variant := t.variant()
+
+	d, err := s.repo.GetDerivative(ctx, a.ID, variant)
+	if err == nil {
+		return d, nil
+	}
+	var appErr *errors.AppError
+	if !errors.As(err, &appErr) || appErr.Type != errors.ErrorTypeNotFound {
+		return nil, err
+	}
+
+	// 上限に達した資産では変換の前に断る。厳密な判定は派生画像の登録時に行う
+	if s.limits.MaxVariants > 0 {
+		count, err := s.repo.CountDerivatives(ctx, a.ID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(s.limits.MaxVariants) {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Asset already has the maximum of %d image variants", s.limits.MaxVariants),
+				nil,
+			)
+		}
+	}
+
+	// 同じ派生画像への同時リクエストは 1 回の変換にまとめる。待っている誰かが切断しても
+	// 他のリクエストのために作り終えてキャッシュする
+	flight := s.inflight.DoChan(a.ID.String()+"/"+variant, func() (interface{}, error) {
+		s.transforms <- struct{}{}
+		defer func() { <-s.transforms }()
+		return s.derive(context.WithoutCancel(ctx), a, t, variant)
+	})
+	select {
+	case result := <-flight:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*asset.Derivative), nil
+	case <-ctx.Done():
+		return nil, errors.NewAppError(errors.ErrorTypeCanceled, "Image transformation was cancelled by the client", ctx.Err())
+	}
+}
+
+// DerivativeContent opens the content of a derivative; the caller closes it
+func (s *Service) DerivativeContent(ctx context.Context, d *asset.Derivative) (io.ReadCloser, error) {
+	return s.storage.Open(ctx, d.StorageKey)
+}
+
+// prepareTransform validates the parameters against the asset and the limits
+func (s *Service) prepareTransform(a *asset.Asset, params TransformParams) (*transform, error) {
+	source, ok := imaging.FormatOf(a.MimeType)
+	if !ok || a.Width == nil || a.Height == nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "Asset is not an image that can be transformed", nil)
+	}
+	if int64(*a.Width)*int64(*a.Height) > s.limits.MaxSourcePixels {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "Image is too large to transform", nil)
+	}
+
+	var violations []errors.Violation
+	for _, dim := range []struct {
+		field string
+		value int
+	}{{"w", params.Width}, {"h", params.Height}} {
+		switch {
+		case dim.value < 0 || dim.value > s.limits.MaxDimension:
+			violations = append(violations, errors.Violation{
+				Field:   dim.field,
+				Rule:    "max",
+				Message: fmt.Sprintf("%s must be between 1 and %d", dim.field, s.limits.MaxDimension),
+			})
+		case dim.value > 0 && len(s.limits.Sizes) > 0 && !containsSize(s.limits.Sizes, dim.value):
+			violations = append(violations, errors.Violation{
+				Field:   dim.field,
+				Rule:    "enum",
+				Message: fmt.Sprintf("%s must be one of %s", dim.field, formatSizes(s.limits.Sizes)),
+			})
+		}
+	}
+
+	fit, ok := imaging.ParseFit(params.Fit)
+	if !ok {
+		violations = append(violations, errors.Violation{Field: "fit", Rule: "enum", Message: "fit must be contain, cover or fill"})
+	}
+
+	format := source
+	if params.Format != "" {
+		if format, ok = imaging.ParseFormat(strings.ToLower(params.Format)); !ok {
+			violations = append(violations, errors.Violation{Field: "format", Rule: "enum", Message: "format must be jpeg, png, gif or webp"})
+		}
+	}
+
+	quality := 0
+	if params.Quality < 0 || params.Quality > 100 {
+		violations = append(violations, errors.Violation{Field: "q", Rule: "max", Message: "q must be between 1 and 100"})
+	} else if format == imaging.JPEG {
+		quality = params.Quality
+		if quality == 0 {
+			quality = defaultQuality
+		}
+	}
+
+	var crop image.Rectangle
+	if params.Crop != "" {
+		var err error
+		if crop, err = parseCrop(params.Crop, *a.Width, *a.Height); err != nil {
+			violations = append(violations, errors.Violation{Field: "crop", Rule: "format", Message: err.Error()})
+		}
+	}
+
+	if len(violations) > 0 {
+		return nil, errors.NewValidationError("Invalid image transformation", violations)
+	}
+
+	t := &transform{
+		options: imaging.Options{Crop: crop, Width: params.Width, Height: params.Height, Fit: fit},
+		format:  format,
+		quality: quality,
+	}
+	// 片方だけ指定した場合も、縦横比から求めた辺が上限を超えないようにする
+	t.width, t.height, t.region = imaging.Dimensions(*a.Width, *a.Height, t.options)
+	if t.width > s.limits.MaxDimension || t.height > s.limits.MaxDimension {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Transformed image would exceed %dx%d", s.limits.MaxDimension, s.limits.MaxDimension),
+			nil,
+		)
+	}
+	return t, nil
+}
+
+// derive makes a derivative and stores it. Callers hold a slot of s.transforms, which bounds
+// the transformations running at once since decoding and scaling hold whole images in memory.
+func (s *Service) derive(ctx context.Context, a *asset.Asset, t *transform, variant string) (*asset.Derivative, error) {
+	content, err := s.storage.Open(ctx, a.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	src, _, err := image.Decode(content)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "Asset image cannot be decoded", err)
+	}
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, imaging.Transform(src, t.options), t.format, t.quality); err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to encode transformed image", err)
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	d := &asset.Derivative{
+		AssetID:  a.ID,
+		Variant:  variant,
+		MimeType: t.format.MimeType(),
+		Size:     int64(buf.Len()),
+		Checksum: hex.EncodeToString(sum[:]),
+		Width:    t.width,
+		Height:   t.height,
+		// 元ファイルのキーから拡張子を除いたディレクトリの下に置く
+		StorageKey: strings.TrimSuffix(a.StorageKey, path.Ext(a.StorageKey)) + "/" + variant,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := s.storage.Put(ctx, d.StorageKey, &buf, d.Size, d.MimeType); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateDerivative(ctx, d, s.limits.MaxVariants); err != nil {
+		// 上限で断られた派生画像はどこからも参照されない
+		var appErr *errors.AppError
+		if errors.As(err, &appErr) && appErr.Type == errors.ErrorTypeValidation {
+			if err := s.storage.Delete(ctx, d.StorageKey); err != nil {
+				logger.Warn("Failed to remove rejected derivative content", "key", d.StorageKey, "error", err)
+			}
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+// parseCrop reads "x,y,width,height" and checks that the region lies within the image
+func parseCrop(value string, width, height int) (image.Rectangle, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}, fmt.Errorf("crop must be x,y,width,height")
+	}
+	var n [4]int
+	for i, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return image.Rectangle{}, fmt.Errorf("crop must be x,y,width,height")
+		}
+		n[i] = v
+	}
+
+	crop := image.Rect(n[0], n[1], n[0]+n[2], n[1]+n[3])
+	if n[0] < 0 || n[1] < 0 || n[2] < 1 || n[3] < 1 || !crop.In(image.Rect(0, 0, width, height)) {
+		return image.Rectangle{}, fmt.Errorf("crop must lie within the %dx%d image", width, height)
+	}
+	return crop, nil
+}
+
+func containsSize(sizes []int, size int) bool {
+	for _, s := range sizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+func formatSizes(sizes []int) string {
+	names := make([]string, len(sizes))
+	for i, size := range sizes {
+		names[i] = strconv.Itoa(size)
+	}
+	return strings.Join(names, ", ")
+}
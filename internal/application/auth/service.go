@@ -0,0 +1,112 @@
+// File: internal/application/auth/service.go
+
+package auth
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"quickflow/internal/domain/user"
+	"quickflow/pkg/errors"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Principal is the authenticated user of a request
+type Principal struct {
+	UserID uint
+	Role   string
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated user
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the authenticated user of the context, if any
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+type UserRepository interface {
+	GetByEmail(email string) (*user.User, error)
+}
+
+// Token is an issued access token
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type claims struct {
+	Role string `json:"role,omitempty"`
+	jwt.StandardClaims
+}
+
+// Service issues and verifies HS256 access tokens signed with the configured secret
+type Service struct {
+	users  UserRepository
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewService(users UserRepository, secret string, ttl time.Duration) *Service {
+	return &Service{users: users, secret: []byte(secret), ttl: ttl}
+}
+
+// Login checks the credentials and issues an access token
+func (s *Service) Login(email, password string) (*Token, error) {
+	invalid := errors.NewAppError(errors.ErrorTypeUnauthorized, "Invalid email or password", nil)
+
+	u, err := s.users.GetByEmail(email)
+	if err != nil || u == nil {
+		return nil, invalid
+	}
+	if !user.CheckPasswordHash(password, u.Password) {
+		return nil, invalid
+	}
+
+	expiresAt := time.Now().Add(s.ttl)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Role: u.Role,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   strconv.FormatUint(uint64(u.ID), 10),
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		},
+	})
+	signed, err := token.SignedString(s.secret)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to sign token", err)
+	}
+
+	return &Token{AccessToken: signed, TokenType: "Bearer", ExpiresAt: expiresAt}, nil
+}
+
+// Authenticate verifies an access token and returns its principal
+func (s *Service) Authenticate(tokenString string) (*Principal, error) {
+	invalid := errors.NewAppError(errors.ErrorTypeUnauthorized, "Invalid or expired token", nil)
+
+	var c claims
+	token, err := jwt.ParseWithClaims(tokenString, &c, func(t *jwt.Token) (interface{}, error) {
+		// alg=none や RS256 への差し替えを拒否する
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, invalid
+		}
+		return s.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, invalid
+	}
+
+	id, err := strconv.ParseUint(c.Subject, 10, 32)
+	if err != nil {
+		return nil, invalid
+	}
+	return &Principal{UserID: uint(id), Role: c.Role}, nil
+}
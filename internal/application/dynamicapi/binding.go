@@ -0,0 +1,491 @@
+// File: internal/application/dynamicapi/binding.go
+
+package dynamicapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+	"quickflow/pkg/locale"
+
+	"github.com/google/uuid"
+)
+
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+var timeLayouts = []string{
+	"15:04:05.999999",
+	"15:04:05",
+	"15:04",
+}
+
+// bindRecord converts a decoded JSON payload into column typed values.
+// When partial is false every NOT NULL column without a default must be present.
+func bindRecord(table *tableentity.Table, payload map[string]interface{}, partial bool) (record.Record, error) {
+	columns := columnsByName(table)
+
+	for name := range payload {
+		col, ok := columns[name]
+		if !ok {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Unknown column: %s", name),
+				nil,
+			)
+		}
+		if col.IsVirtual() {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Column '%s' is managed through the join table %s", name, tableentity.JoinTableName(table, col)),
+				nil,
+			)
+		}
+	}
+
+	rec := make(record.Record, len(payload))
+	for _, col := range table.Columns {
+		raw, ok := payload[col.Name]
+		if !ok {
+			if !partial && !col.IsVirtual() && col.NotNull && col.Default == nil && !col.AutoIncrement {
+				return nil, errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Column '%s' is required", col.Name),
+					nil,
+				)
+			}
+			continue
+		}
+
+		value, err := bindValue(col, raw)
+		if err != nil {
+			return nil, err
+		}
+		rec[col.Name] = value
+	}
+
+	return rec, nil
+}
+
+// bindValue converts a single decoded JSON value into the Go type expected for the column
+func bindValue(col tableentity.Column, raw interface{}) (interface{}, error) {
+	if raw == nil {
+		if col.NotNull {
+			return nil, invalidValue(col, "must not be null")
+		}
+		return nil, nil
+	}
+
+	if col.Localized {
+		return bindTranslations(col, raw)
+	}
+
+	switch col.Type {
+	case tableentity.TypeVARCHAR, tableentity.TypeTEXT:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, invalidValue(col, "must be a string")
+		}
+		if col.Type == tableentity.TypeVARCHAR && col.Length != nil && len([]rune(s)) > *col.Length {
+			return nil, invalidValue(col, fmt.Sprintf("must be at most %d characters", *col.Length))
+		}
+		return s, nil
+
+	case tableentity.TypeINT, tableentity.TypeBIGINT:
+		n, ok := raw.(json.Number)
+		if !ok {
+			return nil, invalidValue(col, "must be an integer")
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return nil, invalidValue(col, "must be an integer")
+		}
+		if col.Type == tableentity.TypeINT && (i < math.MinInt32 || i > math.MaxInt32) {
+			return nil, invalidValue(col, "is out of range for integer")
+		}
+		return i, nil
+
+	case tableentity.TypeFLOAT, tableentity.TypeDOUBLE:
+		n, ok := raw.(json.Number)
+		if !ok {
+			return nil, invalidValue(col, "must be a number")
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return nil, invalidValue(col, "must be a number")
+		}
+		return f, nil
+
+	case tableentity.TypeBOOLEAN:
+		b, ok := raw.(bool)
+		if !ok {
+			return nil, invalidValue(col, "must be a boolean")
+		}
+		return b, nil
+
+	case tableentity.TypeDATE, tableentity.TypeTIMESTAMP, tableentity.TypeTIMESTAMPTZ:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, invalidValue(col, "must be a date string")
+		}
+		t, err := parseDateTime(s)
+		if err != nil {
+			return nil, invalidValue(col, "must be an ISO 8601 date or timestamp")
+		}
+		return t, nil
+
+	case tableentity.TypeTIME:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, invalidValue(col, "must be a time string")
+		}
+		if !isTimeOfDay(s) {
+			return nil, invalidValue(col, "must be a time of day in HH:MM[:SS] format")
+		}
+		return s, nil
+
+	case tableentity.TypeUUID, tableentity.TypeASSET:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, invalidValue(col, "must be a UUID string")
+		}
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, invalidValue(col, "must be a valid UUID")
+		}
+		return id.String(), nil
+
+	case tableentity.TypeNUMERIC:
+		// 精度を落とさないよう数値は文字列のまま渡す
+		var s string
+		switch v := raw.(type) {
+		case json.Number:
+			s = v.String()
+		case string:
+			s = v
+		default:
+			return nil, invalidValue(col, "must be a number or numeric string")
+		}
+		if err := checkNumeric(col, s); err != nil {
+			return nil, err
+		}
+		return s, nil
+
+	case tableentity.TypeBYTEA:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, invalidValue(col, "must be a base64 string")
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, invalidValue(col, "must be valid base64")
+		}
+		return b, nil
+
+	case tableentity.TypeINET:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, invalidValue(col, "must be an IP address string")
+		}
+		if net.ParseIP(s) == nil {
+			if _, _, err := net.ParseCIDR(s); err != nil {
+				return nil, invalidValue(col, "must be a valid IP address")
+			}
+		}
+		return s, nil
+
+	case tableentity.TypeCIDR:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, invalidValue(col, "must be a network string")
+		}
+		ip, network, err := net.ParseCIDR(s)
+		if err != nil || !ip.Equal(network.IP) {
+			return nil, invalidValue(col, "must be a network address in CIDR notation with no bits set right of the mask")
+		}
+		return s, nil
+
+	case tableentity.TypeENUM:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, invalidValue(col, "must be a string")
+		}
+		for _, value := range col.EnumValues {
+			if value == s {
+				return s, nil
+			}
+		}
+		return nil, invalidValue(col, fmt.Sprintf("must be one of %s", strings.Join(col.EnumValues, ", ")))
+
+	case tableentity.TypeARRAY:
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil, invalidValue(col, "must be an array")
+		}
+		element := tableentity.Column{
+			Name:      col.Name,
+			Type:      col.ElementType,
+			Length:    col.Length,
+			Precision: col.Precision,
+			Scale:     col.Scale,
+		}
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			value, err := bindValue(element, item)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return arrayLiteral(col.ElementType, values), nil
+
+	case tableentity.TypeJSON:
+		b, err := json.Marshal(raw)
+		if err != nil {
+			return nil, invalidValue(col, "must be valid JSON")
+		}
+		return string(b), nil
+	}
+
+	return nil, invalidValue(col, fmt.Sprintf("has unsupported type %s", col.Type))
+}
+
+// bindTranslations converts an object of translations keyed by locale into the jsonb value of a
+// localized column. Each translation is bound like a value of the declared column type;
+// null translations are dropped.
+func bindTranslations(col tableentity.Column, raw interface{}) (interface{}, error) {
+	values, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, invalidValue(col, "must be an object of translations keyed by locale")
+	}
+
+	text := col
+	text.Localized = false
+	text.NotNull = false
+	translations := make(map[string]string, len(values))
+	for tag, v := range values {
+		normalized := locale.Normalize(tag)
+		if !locale.IsValid(normalized) {
+			return nil, invalidValue(col, fmt.Sprintf("has an invalid locale: %s", tag))
+		}
+		value, err := bindValue(text, v)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			translations[normalized] = value.(string)
+		}
+	}
+
+	b, err := json.Marshal(translations)
+	if err != nil {
+		return nil, invalidValue(col, "must be an object of translations keyed by locale")
+	}
+	return string(b), nil
+}
+
+// parseKey converts the path identifier into primary key values.
+// Composite keys are given as comma separated values in column order.
+func parseKey(table *tableentity.Table, id string) (record.Key, error) {
+	pkColumns := table.PrimaryKey()
+
+	parts := []string{id}
+	if len(pkColumns) > 1 {
+		parts = strings.Split(id, ",")
+	}
+	if len(parts) != len(pkColumns) {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Record ID must contain %d comma separated values", len(pkColumns)),
+			nil,
+		)
+	}
+
+	key := make(record.Key, len(pkColumns))
+	for i, col := range pkColumns {
+		value, err := parseKeyValue(col, parts[i])
+		if err != nil {
+			return nil, err
+		}
+		key[col.Name] = value
+	}
+	return key, nil
+}
+
+func parseKeyValue(col tableentity.Column, s string) (interface{}, error) {
+	switch col.Type {
+	case tableentity.TypeINT, tableentity.TypeBIGINT:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, errors.NewAppError(errors.ErrorTypeValidation, "Invalid record ID", err)
+		}
+		return i, nil
+	case tableentity.TypeFLOAT, tableentity.TypeDOUBLE:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, errors.NewAppError(errors.ErrorTypeValidation, "Invalid record ID", err)
+		}
+		return f, nil
+	case tableentity.TypeBOOLEAN:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, errors.NewAppError(errors.ErrorTypeValidation, "Invalid record ID", err)
+		}
+		return b, nil
+	case tableentity.TypeDATE, tableentity.TypeTIMESTAMP, tableentity.TypeTIMESTAMPTZ:
+		t, err := parseDateTime(s)
+		if err != nil {
+			return nil, errors.NewAppError(errors.ErrorTypeValidation, "Invalid record ID", err)
+		}
+		return t, nil
+	case tableentity.TypeUUID:
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, errors.NewAppError(errors.ErrorTypeValidation, "Invalid record ID", err)
+		}
+		return id.String(), nil
+	}
+	return s, nil
+}
+
+// normalizeRecord converts driver values into JSON friendly values.
+// Arrays are read as JSON, bytea is encoded as base64 and the remaining
+// types the driver does not decode (uuid, numeric, time, inet, enum) arrive as strings.
+// Localized values become maps of translations keyed by locale.
+func normalizeRecord(table *tableentity.Table, rec record.Record) record.Record {
+	columns := columnsByName(table)
+	for name, value := range rec {
+		b, ok := value.([]byte)
+		if !ok {
+			continue
+		}
+		if columns[name].Localized {
+			var translations map[string]string
+			if err := json.Unmarshal(b, &translations); err == nil {
+				rec[name] = translations
+				continue
+			}
+		}
+		switch columns[name].Type {
+		case tableentity.TypeJSON, tableentity.TypeARRAY:
+			rec[name] = json.RawMessage(b)
+		case tableentity.TypeBYTEA:
+			rec[name] = base64.StdEncoding.EncodeToString(b)
+		default:
+			rec[name] = string(b)
+		}
+	}
+	return rec
+}
+
+// takeVersion removes the version column from a record read or written with it and returns it.
+// Published snapshots taken before versions existed have none and report 0.
+func takeVersion(rec record.Record) int64 {
+	value, ok := rec[tableentity.VersionColumn]
+	if !ok {
+		return 0
+	}
+	delete(rec, tableentity.VersionColumn)
+	version, _ := value.(int64)
+	return version
+}
+
+// arrayLiteral renders bound element values as a PostgreSQL array literal
+func arrayLiteral(elementType tableentity.ColumnType, values []interface{}) string {
+	elements := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+			elements[i] = "NULL"
+		case time.Time:
+			elements[i] = `"` + formatDateTime(elementType, v) + `"`
+		case string:
+			elements[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+		default:
+			elements[i] = fmt.Sprint(v)
+		}
+	}
+	return "{" + strings.Join(elements, ",") + "}"
+}
+
+func formatDateTime(columnType tableentity.ColumnType, t time.Time) string {
+	switch columnType {
+	case tableentity.TypeDATE:
+		return t.Format("2006-01-02")
+	case tableentity.TypeTIMESTAMP:
+		return t.Format("2006-01-02 15:04:05.999999")
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// checkNumeric verifies that s is a decimal number that fits the column precision and scale
+func checkNumeric(col tableentity.Column, s string) error {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return invalidValue(col, "must be a decimal number")
+	}
+	if col.Precision == nil {
+		return nil
+	}
+
+	scale := 0
+	if col.Scale != nil {
+		scale = *col.Scale
+	}
+	integerPart := strings.TrimLeft(strings.SplitN(r.FloatString(scale), ".", 2)[0], "-0")
+	if len(integerPart) > *col.Precision-scale {
+		return invalidValue(col, fmt.Sprintf("must have at most %d digits before the decimal point", *col.Precision-scale))
+	}
+	return nil
+}
+
+func isTimeOfDay(s string) bool {
+	for _, layout := range timeLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func parseDateTime(s string) (time.Time, error) {
+	var lastErr error
+	for _, layout := range dateTimeLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t, nil
+		}
+		lastErr = err
+	}
+	return time.Time{}, lastErr
+}
+
+func columnsByName(table *tableentity.Table) map[string]tableentity.Column {
+	columns := make(map[string]tableentity.Column, len(table.Columns))
+	for _, col := range table.Columns {
+		columns[col.Name] = col
+	}
+	return columns
+}
+
+func invalidValue(col tableentity.Column, reason string) error {
+	return errors.NewAppError(
+		errors.ErrorTypeValidation,
+		fmt.Sprintf("Column '%s' %s", col.Name, reason),
+		nil,
+	)
+}
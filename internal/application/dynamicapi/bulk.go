@@ -0,0 +1,289 @@
+// File: internal/application/dynamicapi/bulk.go
+
+package dynamicapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+)
+
+// maxBulkRecords bounds the number of records in a single bulk request
+const maxBulkRecords = 1000
+
+// errBulkRolledBack aborts the transaction of an atomic bulk write after a record failed
+var errBulkRolledBack = errors.NewAppError(errors.ErrorTypeValidation, "Bulk write rolled back", nil)
+
+// Bulk applies one operation to many records in a single transaction.
+// Each record runs under its own savepoint so that every failure is reported,
+// whichever mode is used.
+func (s *RecordService) Bulk(ctx context.Context, tableName string, req BulkRequest) (*BulkResult, error) {
+	table, err := s.getTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := requireEditor(ctx, table); err != nil {
+		return nil, err
+	}
+
+	if req.Mode == "" {
+		req.Mode = BulkAtomic
+	}
+	if err := validateBulkRequest(table, req); err != nil {
+		return nil, err
+	}
+
+	result := &BulkResult{
+		Operation: req.Operation,
+		Mode:      req.Mode,
+		Results:   make([]BulkItemResult, len(req.Records)),
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i, payload := range req.Records {
+			var rec record.Record
+			err := s.tx.WithinSavepoint(ctx, func(ctx context.Context) error {
+				var err error
+				rec, err = s.applyBulkItem(ctx, table, req, payload)
+				return err
+			})
+
+			item := BulkItemResult{Index: i, Status: BulkItemSucceeded}
+			if err != nil {
+				item.Status = BulkItemFailed
+				setBulkError(&item, err)
+				result.Failed++
+			} else {
+				item.Record = normalizeRecord(table, rec)
+				takeVersion(item.Record)
+				result.Succeeded++
+			}
+			result.Results[i] = item
+		}
+
+		if req.Mode == BulkAtomic && result.Failed > 0 {
+			return errBulkRolledBack
+		}
+		return nil
+	})
+	if err == errBulkRolledBack {
+		for i := range result.Results {
+			if result.Results[i].Status == BulkItemSucceeded {
+				result.Results[i].Status = BulkItemRolledBack
+				result.Results[i].Record = nil
+			}
+		}
+		result.Succeeded = 0
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Committed = true
+	return result, nil
+}
+
+func (s *RecordService) applyBulkItem(ctx context.Context, table *tableentity.Table, req BulkRequest, payload map[string]interface{}) (record.Record, error) {
+	switch req.Operation {
+	case BulkInsert:
+		values, err := s.bindChecked(ctx, table, payload, nil)
+		if err != nil {
+			return nil, err
+		}
+		return s.writeCreate(ctx, table, values, record.OperationCreate, nil)
+
+	case BulkUpsert:
+		// 衝突は更新として扱うため一意性の事前チェックは行わない
+		if err := s.validator.Check(table, payload, false); err != nil {
+			return nil, err
+		}
+		values, err := bindRecord(table, payload, false)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.validator.CheckExpressions(ctx, table, payload, nil); err != nil {
+			return nil, err
+		}
+		if err := s.validator.CheckAssets(ctx, table, values); err != nil {
+			return nil, err
+		}
+		for _, name := range req.OnConflict {
+			if values[name] == nil {
+				return nil, errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Conflict column '%s' must be provided", name),
+					nil,
+				)
+			}
+		}
+		return s.writeUpsert(ctx, table, values, req.OnConflict)
+
+	case BulkUpdate:
+		key, rest, err := bindPayloadKey(table, payload)
+		if err != nil {
+			return nil, err
+		}
+		values, err := s.bindChecked(ctx, table, rest, key)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				"At least one column besides the primary key must be provided",
+				nil,
+			)
+		}
+		return s.writeUpdate(ctx, table, key, values, nil, record.OperationUpdate, nil)
+
+	case BulkDelete:
+		key, rest, err := bindPayloadKey(table, payload)
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) > 0 {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				"Delete records may only contain primary key columns",
+				nil,
+			)
+		}
+		if err := s.deleteRecord(ctx, table, key); err != nil {
+			return nil, err
+		}
+		return record.Record(key), nil
+	}
+	return nil, errors.NewAppError(errors.ErrorTypeInternal, "Unknown bulk operation", nil)
+}
+
+func validateBulkRequest(table *tableentity.Table, req BulkRequest) error {
+	switch req.Operation {
+	case BulkInsert, BulkUpdate, BulkUpsert, BulkDelete:
+	default:
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Unknown bulk operation: %s", req.Operation),
+			nil,
+		)
+	}
+	if req.Mode != BulkAtomic && req.Mode != BulkBestEffort {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Unknown bulk mode: %s", req.Mode),
+			nil,
+		)
+	}
+	if len(req.Records) == 0 {
+		return errors.NewAppError(errors.ErrorTypeValidation, "At least one record must be provided", nil)
+	}
+	if len(req.Records) > maxBulkRecords {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("At most %d records can be written at once", maxBulkRecords),
+			nil,
+		)
+	}
+
+	if req.Operation != BulkUpsert {
+		if len(req.OnConflict) > 0 {
+			return errors.NewAppError(errors.ErrorTypeValidation, "on_conflict is only used by upsert", nil)
+		}
+		return nil
+	}
+	if !isUniqueKey(table, req.OnConflict) {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			"on_conflict must name the primary key, a unique column or the columns of a unique index",
+			nil,
+		)
+	}
+	return nil
+}
+
+// isUniqueKey reports whether the columns match a unique constraint that ON CONFLICT can infer.
+// Partial unique indexes are left out because inferring them needs their predicate.
+func isUniqueKey(table *tableentity.Table, columns []string) bool {
+	if len(columns) == 0 {
+		return false
+	}
+	for _, name := range columns {
+		if col, ok := table.Column(name); !ok || col.IsVirtual() {
+			return false
+		}
+	}
+	key := columnSet(columns)
+
+	var pk []string
+	for _, col := range table.PrimaryKey() {
+		pk = append(pk, col.Name)
+	}
+	if key == columnSet(pk) {
+		return true
+	}
+	if len(columns) == 1 {
+		if col, _ := table.Column(columns[0]); col.Unique {
+			return true
+		}
+	}
+	for _, idx := range table.Indexes {
+		if idx.Unique && len(idx.Where) == 0 && key == columnSet(idx.Columns) {
+			return true
+		}
+	}
+	return false
+}
+
+func columnSet(columns []string) string {
+	sorted := append([]string(nil), columns...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// bindPayloadKey takes the primary key values out of a bulk record and returns the remaining columns
+func bindPayloadKey(table *tableentity.Table, payload map[string]interface{}) (record.Key, map[string]interface{}, error) {
+	rest := make(map[string]interface{}, len(payload))
+	for name, value := range payload {
+		rest[name] = value
+	}
+
+	key := make(record.Key)
+	for _, col := range table.PrimaryKey() {
+		raw, ok := rest[col.Name]
+		if !ok || raw == nil {
+			return nil, nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Primary key column '%s' is required", col.Name),
+				nil,
+			)
+		}
+		value, err := bindValue(col, raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		key[col.Name] = value
+		delete(rest, col.Name)
+	}
+	return key, rest, nil
+}
+
+// setBulkError reports an error on a record in the same shape as HTTP error responses
+func setBulkError(item *BulkItemResult, err error) {
+	var appErr *errors.AppError
+	if !errors.As(err, &appErr) {
+		item.Error = "An unexpected error occurred"
+		return
+	}
+	item.Error = appErr.Message
+	if len(appErr.Violations) > 0 {
+		item.Violations = appErr.Violations
+	} else {
+		item.Details = err.Error()
+	}
+}
@@ -0,0 +1,124 @@
+// File: internal/application/dynamicapi/cursor.go
+
+package dynamicapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+)
+
+// cursorPayload is the content of an opaque pagination cursor.
+// The sort expression is kept so a cursor cannot be reused with a different order.
+type cursorPayload struct {
+	Sort  string        `json:"s"`
+	After []interface{} `json:"a"`
+}
+
+// parseSort reads a sort expression such as "-created_at,title" and appends the
+// primary key columns so that every record has a unique position
+func parseSort(table *tableentity.Table, sort string) ([]record.SortKey, error) {
+	var keys []record.SortKey
+	used := make(map[string]bool)
+
+	if sort != "" {
+		for _, field := range strings.Split(sort, ",") {
+			key := record.SortKey{Column: strings.TrimSpace(field)}
+			if strings.HasPrefix(key.Column, "-") {
+				key.Descending = true
+				key.Column = key.Column[1:]
+			}
+
+			col, ok := table.Column(key.Column)
+			if !ok || col.IsVirtual() {
+				return nil, errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Cannot sort by unknown column: %s", key.Column),
+					nil,
+				)
+			}
+			if col.Localized {
+				return nil, errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Cannot sort by localized column: %s", col.Name),
+					nil,
+				)
+			}
+			if !orderedTypes[col.Type] && col.Type != tableentity.TypeBOOLEAN && col.Type != tableentity.TypeUUID {
+				return nil, errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Cannot sort by %s column: %s", col.Type, col.Name),
+					nil,
+				)
+			}
+			key.Nullable = !col.NotNull && !col.PrimaryKey
+			if used[col.Name] {
+				return nil, errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Column '%s' appears more than once in sort", col.Name),
+					nil,
+				)
+			}
+			used[col.Name] = true
+			keys = append(keys, key)
+		}
+	}
+
+	for _, col := range table.PrimaryKey() {
+		if !used[col.Name] {
+			keys = append(keys, record.SortKey{Column: col.Name})
+		}
+	}
+	return keys, nil
+}
+
+// encodeCursor builds the cursor pointing after rec
+func encodeCursor(sort string, keys []record.SortKey, rec record.Record) (string, error) {
+	payload := cursorPayload{Sort: sort, After: make([]interface{}, len(keys))}
+	for i, key := range keys {
+		payload.After[i] = rec[key.Column]
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return "", errors.NewAppError(errors.ErrorTypeInternal, "Failed to encode cursor", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCursor reads a cursor and binds its values to the sort key column types
+func decodeCursor(table *tableentity.Table, sort string, keys []record.SortKey, cursor string) ([]interface{}, error) {
+	invalid := errors.NewAppError(errors.ErrorTypeValidation, "Invalid cursor", nil)
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var payload cursorPayload
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, invalid
+	}
+	if payload.Sort != sort || len(payload.After) != len(keys) {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "Cursor does not match the requested sort", nil)
+	}
+
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		col, _ := table.Column(key.Column)
+		value, err := bindValue(col, payload.After[i])
+		if err != nil {
+			return nil, invalid
+		}
+		values[i] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,146 @@
+// File: internal/application/dynamicapi/cursor_test.go
+
+package dynamicapi
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+)
+
+var cursorTable = &tableentity.Table{
+	Name: "articles",
+	Columns: []tableentity.Column{
+		{Name: "id", Type: tableentity.TypeBIGINT, PrimaryKey: true},
+		{Name: "title", Type: tableentity.TypeTEXT},
+		{Name: "views", Type: tableentity.TypeINT, NotNull: true},
+		{Name: "published_at", Type: tableentity.TypeTIMESTAMPTZ},
+		{Name: "name", Type: tableentity.TypeTEXT, Localized: true},
+		{Name: "meta", Type: tableentity.TypeJSON},
+		{Name: "tags", Type: tableentity.TypeBIGINT, Relation: &tableentity.Relation{Type: tableentity.RelationManyToMany, Table: "tags", Column: "id"}},
+	},
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		sort    string
+		want    []record.SortKey
+		wantErr bool
+	}{
+		{sort: "", want: []record.SortKey{{Column: "id"}}},
+		{sort: "-views,title", want: []record.SortKey{
+			{Column: "views", Descending: true},
+			{Column: "title", Nullable: true},
+			{Column: "id"},
+		}},
+		{sort: " published_at ", want: []record.SortKey{{Column: "published_at", Nullable: true}, {Column: "id"}}},
+		// 主キーを指定した場合は末尾に重ねて足さない
+		{sort: "-id", want: []record.SortKey{{Column: "id", Descending: true}}},
+		{sort: "missing", wantErr: true},
+		{sort: "name", wantErr: true},
+		{sort: "meta", wantErr: true},
+		{sort: "tags", wantErr: true},
+		{sort: "title,-title", wantErr: true},
+		{sort: "title,", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			keys, err := parseSort(cursorTable, tt.sort)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSort error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("keys = %+v, want %+v", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	publishedAt := time.Date(2026, 10, 18, 9, 30, 0, 123000000, time.UTC)
+	tests := []struct {
+		sort string
+		rec  record.Record
+		want []interface{}
+	}{
+		{
+			sort: "",
+			rec:  record.Record{"id": int64(42), "title": "ignored"},
+			want: []interface{}{int64(42)},
+		},
+		{
+			sort: "-views,title",
+			rec:  record.Record{"id": int64(3), "views": int64(10), "title": "it's"},
+			want: []interface{}{int64(10), "it's", int64(3)},
+		},
+		{
+			sort: "title,-published_at",
+			rec:  record.Record{"id": int64(7), "title": nil, "published_at": publishedAt},
+			want: []interface{}{nil, publishedAt, int64(7)},
+		},
+		{
+			// 2^53 を超える整数も丸めずに戻る
+			sort: "",
+			rec:  record.Record{"id": int64(9007199254740993)},
+			want: []interface{}{int64(9007199254740993)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			keys, err := parseSort(cursorTable, tt.sort)
+			if err != nil {
+				t.Fatalf("parseSort: %v", err)
+			}
+			cursor, err := encodeCursor(tt.sort, keys, tt.rec)
+			if err != nil {
+				t.Fatalf("encodeCursor: %v", err)
+			}
+			got, err := decodeCursor(cursorTable, tt.sort, keys, cursor)
+			if err != nil {
+				t.Fatalf("decodeCursor: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("after = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorErrors(t *testing.T) {
+	keys, err := parseSort(cursorTable, "-views")
+	if err != nil {
+		t.Fatalf("parseSort: %v", err)
+	}
+	encode := func(payload string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(payload))
+	}
+	valid, err := encodeCursor("-views", keys, record.Record{"id": int64(1), "views": int64(2)})
+	if err != nil {
+		t.Fatalf("encodeCursor: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		sort   string
+		cursor string
+	}{
+		{"not base64", "-views", "***"},
+		{"not json", "-views", encode("{")},
+		{"other sort", "views", valid},
+		{"wrong number of values", "-views", encode(`{"s":"-views","a":[2]}`)},
+		{"value of the wrong type", "-views", encode(`{"s":"-views","a":["2",1]}`)},
+		{"null for a not null key", "-views", encode(`{"s":"-views","a":[null,1]}`)},
+		{"fractional key", "-views", encode(`{"s":"-views","a":[2.5,1]}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(cursorTable, tt.sort, keys, tt.cursor); err == nil {
+				t.Error("decodeCursor succeeded, want an error")
+			}
+		})
+	}
+}
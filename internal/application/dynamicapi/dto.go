@@ -1,7 +1,149 @@
+// File: internal/application/dynamicapi/dto.go
+
 package dynamicapi
 
-import "fmt"
+import (
+	"quickflow/internal/domain/record"
+	"quickflow/pkg/errors"
+)
+
+// ListResult is the response body for record listings
+type ListResult struct {
+	Data  []record.Record `json:"data"`
+	Limit int             `json:"limit"`
+	// NextCursor is set when more records follow this page
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// ListParams are the options of a record listing
+type ListParams struct {
+	Limit   int
+	Filters []FilterParam
+	// Sort is a comma separated list of columns, each optionally prefixed with - for descending order
+	Sort         string
+	Cursor       string
+	IncludeTotal bool
+	// Fields limits the returned columns; empty returns every column
+	Fields []string
+	// Expand names relation columns whose related records are embedded
+	Expand []string
+	// Locale is the locale localized columns are read in; empty returns every translation
+	Locale string
+}
+
+// GetParams are the options of a single record read
+type GetParams struct {
+	Fields []string
+	Expand []string
+	Locale string
+}
+
+// FilterParam is an unbound filter taken from the query string, such as filter[price][gte]=100
+type FilterParam struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+// BulkOperation is the write applied to every record of a bulk request
+type BulkOperation string
+
+const (
+	BulkInsert BulkOperation = "insert"
+	BulkUpdate BulkOperation = "update"
+	BulkUpsert BulkOperation = "upsert"
+	BulkDelete BulkOperation = "delete"
+)
+
+// BulkMode decides what happens to the other records when one of them fails
+type BulkMode string
+
+const (
+	// BulkAtomic commits nothing unless every record succeeds
+	BulkAtomic BulkMode = "atomic"
+	// BulkBestEffort commits the records that succeed and reports the rest
+	BulkBestEffort BulkMode = "best_effort"
+)
+
+// BulkRequest is the request body of a bulk write.
+// Update and delete records identify their row by the primary key columns they contain.
+type BulkRequest struct {
+	Operation BulkOperation `json:"operation"`
+	Mode      BulkMode      `json:"mode,omitempty"`
+	// OnConflict lists the unique columns an upsert matches existing rows on
+	OnConflict []string                 `json:"on_conflict,omitempty"`
+	Records    []map[string]interface{} `json:"records"`
+}
+
+// BulkItemStatus is the outcome of a single record in a bulk write
+type BulkItemStatus string
+
+const (
+	BulkItemSucceeded BulkItemStatus = "succeeded"
+	BulkItemFailed    BulkItemStatus = "failed"
+	// BulkItemRolledBack marks a record that succeeded but was undone because another record failed
+	BulkItemRolledBack BulkItemStatus = "rolled_back"
+)
+
+// BulkItemResult reports one record of a bulk write, in request order
+type BulkItemResult struct {
+	Index   int            `json:"index"`
+	Status  BulkItemStatus `json:"status"`
+	Record  record.Record  `json:"record,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Details string         `json:"details,omitempty"`
+	// Violations lists every failed rule when the record did not pass validation
+	Violations []errors.Violation `json:"violations,omitempty"`
+}
+
+// BulkResult is the response body of a bulk write
+type BulkResult struct {
+	Operation BulkOperation    `json:"operation"`
+	Mode      BulkMode         `json:"mode"`
+	Committed bool             `json:"committed"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// StageRequest is the request body of a review stage move
+type StageRequest struct {
+	To      string `json:"to"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// CommentRequest is the request body of a review comment.
+// RequestChanges sends the record back to the first review stage.
+type CommentRequest struct {
+	Body           string `json:"body"`
+	RequestChanges bool   `json:"request_changes,omitempty"`
+}
+
+// RevisionDiff is the response body of a revision comparison
+type RevisionDiff struct {
+	From    int             `json:"from"`
+	To      int             `json:"to"`
+	Changes []record.Change `json:"changes"`
+}
+
+// TranslationParams are the options of a missing translation listing
+type TranslationParams struct {
+	// Locale restricts the listing to one locale; empty checks every supported locale
+	Locale string
+	Limit  int
+	Cursor string
+}
+
+// MissingTranslation names the localized columns of a record and the locales they lack
+type MissingTranslation struct {
+	ID      string              `json:"id"`
+	Missing map[string][]string `json:"missing"`
+}
 
-func CreateDynamicApi() {
-	fmt.Println("Hello, DynamicAPI!")
+// TranslationResult is the response body of a missing translation listing
+type TranslationResult struct {
+	Data       []MissingTranslation `json:"data"`
+	Limit      int                  `json:"limit"`
+	NextCursor string               `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,215 @@
+// File: internal/application/dynamicapi/expand.go
+
+package dynamicapi
+
+import (
+	"context"
+	"fmt"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+)
+
+// maxExpand bounds the number of relations embedded in a single read
+const maxExpand = 5
+
+// shape describes which columns a read selects and returns
+type shape struct {
+	// columns are the physical columns to select; nil selects every column
+	columns []string
+	// fields are the keys kept in the response; nil keeps every key
+	fields map[string]bool
+	expand []tableentity.Column
+}
+
+// buildShape validates ?fields= and ?expand= against the table definition.
+// required lists columns needed internally, such as sort keys, that are selected but not returned.
+func buildShape(table *tableentity.Table, fields, expand []string, required []string) (*shape, error) {
+	sh := &shape{}
+
+	if len(expand) > maxExpand {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("At most %d relations can be expanded", maxExpand),
+			nil,
+		)
+	}
+	expanded := make(map[string]bool, len(expand))
+	for _, name := range expand {
+		col, ok := table.Column(name)
+		if !ok || col.Relation == nil {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Cannot expand '%s': not a relation column", name),
+				nil,
+			)
+		}
+		if !expanded[name] {
+			expanded[name] = true
+			sh.expand = append(sh.expand, col)
+		}
+	}
+
+	if len(fields) == 0 {
+		return sh, nil
+	}
+
+	sh.fields = make(map[string]bool, len(fields))
+	selected := make(map[string]bool)
+	for _, name := range fields {
+		col, ok := table.Column(name)
+		if !ok {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Unknown field: %s", name),
+				nil,
+			)
+		}
+		if col.IsVirtual() && !expanded[name] {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Field '%s' is only available with expand=%s", name, name),
+				nil,
+			)
+		}
+		sh.fields[name] = true
+		if !col.IsVirtual() {
+			selected[name] = true
+		}
+	}
+
+	// 主キーは多対多の展開に、ソートキーはカーソルに必要なので常に読む
+	for _, col := range table.PrimaryKey() {
+		selected[col.Name] = true
+	}
+	for _, name := range required {
+		selected[name] = true
+	}
+	// 展開したリレーションは fields に無くても返す
+	for _, col := range sh.expand {
+		sh.fields[col.Name] = true
+		if !col.IsVirtual() {
+			selected[col.Name] = true
+		}
+	}
+
+	for _, col := range table.Columns {
+		if selected[col.Name] {
+			sh.columns = append(sh.columns, col.Name)
+		}
+	}
+	return sh, nil
+}
+
+// trim removes the keys that were selected only for internal use
+func (sh *shape) trim(rec record.Record) record.Record {
+	if sh.fields == nil {
+		return rec
+	}
+	for name := range rec {
+		if !sh.fields[name] {
+			delete(rec, name)
+		}
+	}
+	return rec
+}
+
+// expandRelations embeds related rows into normalized records with one query per relation
+func (s *RecordService) expandRelations(ctx context.Context, reader RecordReader, table *tableentity.Table, records []record.Record, columns []tableentity.Column) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	for _, col := range columns {
+		target, err := s.tables.GetTable(ctx, col.Relation.Table)
+		if err != nil {
+			return err
+		}
+
+		if col.IsVirtual() {
+			if err := s.expandManyToMany(ctx, reader, table, target, col, records); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var values []interface{}
+		seen := make(map[string]bool)
+		for _, rec := range records {
+			if v := rec[col.Name]; v != nil && !seen[keyString(v)] {
+				seen[keyString(v)] = true
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		related, err := reader.ListByValues(ctx, target, col.Relation.Column, values)
+		if err != nil {
+			return err
+		}
+		byKey := make(map[string]record.Record, len(related))
+		for _, rel := range related {
+			normalizeRecord(target, rel)
+			s.localize(ctx, target, rel)
+			byKey[keyString(rel[col.Relation.Column])] = rel
+		}
+		for _, rec := range records {
+			if v := rec[col.Name]; v != nil {
+				if rel, ok := byKey[keyString(v)]; ok {
+					rec[col.Name] = rel
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *RecordService) expandManyToMany(ctx context.Context, reader RecordReader, table, target *tableentity.Table, col tableentity.Column, records []record.Record) error {
+	pk := table.PrimaryKey()
+	key, ok := target.Column(col.Relation.Column)
+	if len(pk) != 1 || !ok {
+		return errors.NewAppError(
+			errors.ErrorTypeInternal,
+			fmt.Sprintf("Relation '%s' cannot be expanded", col.Name),
+			nil,
+		)
+	}
+	join := tableentity.JoinTable(table, col, key)
+
+	var values []interface{}
+	for _, rec := range records {
+		rec[col.Name] = []record.Record{}
+		if v := rec[pk[0].Name]; v != nil {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	related, err := reader.ListRelated(ctx, target, join, values)
+	if err != nil {
+		return err
+	}
+	bySource := make(map[string][]record.Record)
+	for _, rel := range related {
+		normalizeRecord(target, rel.Record)
+		s.localize(ctx, target, rel.Record)
+		k := keyString(rel.Source)
+		bySource[k] = append(bySource[k], rel.Record)
+	}
+	for _, rec := range records {
+		if rows, ok := bySource[keyString(rec[pk[0].Name])]; ok {
+			rec[col.Name] = rows
+		}
+	}
+	return nil
+}
+
+// keyString gives key values of the same column a comparable form
+func keyString(v interface{}) string {
+	return fmt.Sprint(v)
+}
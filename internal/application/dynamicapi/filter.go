@@ -0,0 +1,239 @@
+// File: internal/application/dynamicapi/filter.go
+
+package dynamicapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+	"quickflow/pkg/locale"
+)
+
+// maxFilters bounds the number of predicates in a single listing
+const maxFilters = 20
+
+// orderedTypes can be compared with gt, gte, lt and lte
+var orderedTypes = map[tableentity.ColumnType]bool{
+	tableentity.TypeVARCHAR:     true,
+	tableentity.TypeTEXT:        true,
+	tableentity.TypeINT:         true,
+	tableentity.TypeBIGINT:      true,
+	tableentity.TypeFLOAT:       true,
+	tableentity.TypeDOUBLE:      true,
+	tableentity.TypeNUMERIC:     true,
+	tableentity.TypeDATE:        true,
+	tableentity.TypeTIMESTAMP:   true,
+	tableentity.TypeTIMESTAMPTZ: true,
+	tableentity.TypeTIME:        true,
+	tableentity.TypeENUM:        true,
+	tableentity.TypeINET:        true,
+}
+
+// bindFilters checks each query string filter against the table definition and
+// converts its value to the column type
+func bindFilters(table *tableentity.Table, params []FilterParam) ([]record.Filter, error) {
+	if len(params) > maxFilters {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("At most %d filters are allowed", maxFilters),
+			nil,
+		)
+	}
+
+	filters := make([]record.Filter, 0, len(params))
+	for _, param := range params {
+		filter, err := bindFilter(table, param)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+func bindFilter(table *tableentity.Table, param FilterParam) (record.Filter, error) {
+	// meta.author.name は jsonb カラム meta の author.name を指す
+	parts := strings.Split(param.Field, ".")
+	col, ok := table.Column(parts[0])
+	if !ok || col.IsVirtual() {
+		return record.Filter{}, invalidFilter(param, "unknown column")
+	}
+
+	filter := record.Filter{
+		Column:   col.Name,
+		Operator: record.FilterOperator(param.Operator),
+	}
+	if filter.Operator == "" {
+		filter.Operator = record.FilterEqual
+	}
+	if len(parts) > 1 {
+		if col.Type != tableentity.TypeJSON && !col.Localized {
+			return record.Filter{}, invalidFilter(param, "paths can only be used on jsonb and localized columns")
+		}
+		for _, key := range parts[1:] {
+			if key == "" {
+				return record.Filter{}, invalidFilter(param, "path contains an empty key")
+			}
+		}
+		filter.Path = parts[1:]
+	}
+
+	if filter.Operator == record.FilterIsNull {
+		isNull, err := strconv.ParseBool(param.Value)
+		if err != nil {
+			return record.Filter{}, invalidFilter(param, "is_null needs true or false")
+		}
+		filter.Value = isNull
+		return filter, nil
+	}
+
+	if filter.Path != nil {
+		return bindPathFilter(filter, param)
+	}
+
+	if filter.Operator == record.FilterMissing {
+		if !col.Localized {
+			return record.Filter{}, invalidFilter(param, "missing can only be used on localized columns")
+		}
+		tag := locale.Normalize(param.Value)
+		if !locale.IsValid(tag) {
+			return record.Filter{}, invalidFilter(param, "missing needs a locale")
+		}
+		filter.Value = tag
+		return filter, nil
+	}
+	if col.Localized {
+		// 翻訳ごとに比較する: filter[title.ja]=...
+		return record.Filter{}, invalidFilter(param, fmt.Sprintf("localized columns are compared per locale, such as %s.<locale>", col.Name))
+	}
+
+	switch filter.Operator {
+	case record.FilterEqual, record.FilterNotEqual:
+		if col.Type == tableentity.TypeJSON || col.Type == tableentity.TypeARRAY || col.Type == tableentity.TypeBYTEA {
+			return record.Filter{}, invalidFilter(param, fmt.Sprintf("%s columns cannot be compared with %s", col.Type, filter.Operator))
+		}
+		value, err := bindFilterValue(col, param.Value)
+		if err != nil {
+			return record.Filter{}, err
+		}
+		filter.Value = value
+
+	case record.FilterGreater, record.FilterGreaterEqual, record.FilterLess, record.FilterLessEqual:
+		if !orderedTypes[col.Type] {
+			return record.Filter{}, invalidFilter(param, fmt.Sprintf("%s columns cannot be ordered", col.Type))
+		}
+		value, err := bindFilterValue(col, param.Value)
+		if err != nil {
+			return record.Filter{}, err
+		}
+		filter.Value = value
+
+	case record.FilterIn:
+		if col.Type == tableentity.TypeJSON || col.Type == tableentity.TypeARRAY || col.Type == tableentity.TypeBYTEA {
+			return record.Filter{}, invalidFilter(param, fmt.Sprintf("%s columns cannot be used with in", col.Type))
+		}
+		values, err := bindFilterList(col, param.Value)
+		if err != nil {
+			return record.Filter{}, err
+		}
+		filter.Value = values
+
+	case record.FilterContains:
+		switch col.Type {
+		case tableentity.TypeVARCHAR, tableentity.TypeTEXT:
+			filter.Value = param.Value
+		case tableentity.TypeARRAY:
+			element := tableentity.Column{
+				Name:      col.Name,
+				Type:      col.ElementType,
+				Length:    col.Length,
+				Precision: col.Precision,
+				Scale:     col.Scale,
+			}
+			values, err := bindFilterList(element, param.Value)
+			if err != nil {
+				return record.Filter{}, err
+			}
+			filter.Value = values
+		case tableentity.TypeJSON:
+			if !json.Valid([]byte(param.Value)) {
+				return record.Filter{}, invalidFilter(param, "contains on jsonb needs a JSON value")
+			}
+			filter.Value = param.Value
+		default:
+			return record.Filter{}, invalidFilter(param, fmt.Sprintf("%s columns cannot be used with contains", col.Type))
+		}
+
+	default:
+		return record.Filter{}, invalidFilter(param, "unknown operator")
+	}
+
+	return filter, nil
+}
+
+// bindPathFilter binds a predicate on a nested jsonb value. Nested values are compared as text,
+// except that ordering comparisons with a numeric value compare numerically.
+func bindPathFilter(filter record.Filter, param FilterParam) (record.Filter, error) {
+	switch filter.Operator {
+	case record.FilterEqual, record.FilterNotEqual, record.FilterContains:
+		filter.Value = param.Value
+	case record.FilterGreater, record.FilterGreaterEqual, record.FilterLess, record.FilterLessEqual:
+		if f, err := strconv.ParseFloat(param.Value, 64); err == nil {
+			filter.Value = f
+		} else {
+			filter.Value = param.Value
+		}
+	case record.FilterIn:
+		var values []interface{}
+		for _, v := range strings.Split(param.Value, ",") {
+			values = append(values, v)
+		}
+		filter.Value = values
+	default:
+		return record.Filter{}, invalidFilter(param, "unknown operator")
+	}
+	return filter, nil
+}
+
+// bindFilterValue converts a query string value through the same rules as JSON payloads
+func bindFilterValue(col tableentity.Column, s string) (interface{}, error) {
+	col.NotNull = false
+
+	var raw interface{} = s
+	switch col.Type {
+	case tableentity.TypeINT, tableentity.TypeBIGINT, tableentity.TypeFLOAT, tableentity.TypeDOUBLE, tableentity.TypeNUMERIC:
+		raw = json.Number(s)
+	case tableentity.TypeBOOLEAN:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, invalidValue(col, "must be a boolean")
+		}
+		raw = b
+	}
+	return bindValue(col, raw)
+}
+
+func bindFilterList(col tableentity.Column, s string) ([]interface{}, error) {
+	var values []interface{}
+	for _, item := range strings.Split(s, ",") {
+		value, err := bindFilterValue(col, item)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func invalidFilter(param FilterParam, reason string) error {
+	return errors.NewAppError(
+		errors.ErrorTypeValidation,
+		fmt.Sprintf("Invalid filter on '%s' (%s): %s", param.Field, param.Operator, reason),
+		nil,
+	)
+}
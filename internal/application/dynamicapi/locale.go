@@ -0,0 +1,173 @@
+// File: internal/application/dynamicapi/locale.go
+
+package dynamicapi
+
+import (
+	"context"
+	"fmt"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+	"quickflow/pkg/locale"
+)
+
+// AllLocales asks reads for every translation of localized columns instead of one locale
+const AllLocales = "*"
+
+type localeKey struct{}
+
+// withLocale carries the locale reads resolve localized columns to; empty keeps every translation
+func withLocale(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, localeKey{}, tag)
+}
+
+// ResolveLocale picks the locale of a read from the locale query parameter or, without one,
+// the Accept-Language header. AllLocales resolves to the empty locale, which keeps every translation.
+func (s *RecordService) ResolveLocale(requested, acceptLanguage string) (string, error) {
+	if requested == AllLocales {
+		return "", nil
+	}
+	if requested == "" {
+		return s.locales.Negotiate(acceptLanguage), nil
+	}
+
+	tag := locale.Normalize(requested)
+	if !s.locales.IsSupported(tag) {
+		return "", errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Unsupported locale: %s", requested),
+			nil,
+		)
+	}
+	return tag, nil
+}
+
+// localize replaces the translations of localized columns with the value for the locale of
+// the context, falling back through the configured chain. Values without any translation become null.
+func (s *RecordService) localize(ctx context.Context, table *tableentity.Table, rec record.Record) {
+	tag, _ := ctx.Value(localeKey{}).(string)
+	if tag == "" {
+		return
+	}
+	for _, col := range table.LocalizedColumns() {
+		translations, ok := rec[col.Name].(map[string]string)
+		if !ok {
+			continue
+		}
+		if value, _, found := s.locales.Resolve(translations, tag); found {
+			rec[col.Name] = value
+		} else {
+			rec[col.Name] = nil
+		}
+	}
+}
+
+// MissingTranslations lists the records whose localized columns lack a translation in one of
+// the given locales, or in any supported locale when params.Locale is empty. Columns without any
+// value are not reported. Drafts are listed, so editors see what still needs translating.
+func (s *RecordService) MissingTranslations(ctx context.Context, tableName string, params TranslationParams) (*TranslationResult, error) {
+	table, err := s.getTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := requireEditor(ctx, table); err != nil {
+		return nil, err
+	}
+
+	columns := table.LocalizedColumns()
+	if len(columns) == 0 {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Table '%s' has no localized columns", table.Name),
+			nil,
+		)
+	}
+
+	locales := s.locales.Supported
+	if params.Locale != "" {
+		tag := locale.Normalize(params.Locale)
+		if !s.locales.IsSupported(tag) {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Unsupported locale: %s", params.Locale),
+				nil,
+			)
+		}
+		locales = []string{tag}
+	}
+
+	var alternatives []record.Filter
+	fields := primaryKeyNames(table)
+	for _, col := range columns {
+		fields = append(fields, col.Name)
+		for _, tag := range locales {
+			alternatives = append(alternatives, record.Filter{Column: col.Name, Operator: record.FilterMissing, Value: tag})
+		}
+	}
+
+	sortKeys, err := parseSort(table, "")
+	if err != nil {
+		return nil, err
+	}
+	var after []interface{}
+	if params.Cursor != "" {
+		after, err = decodeCursor(table, "", sortKeys, params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > s.maxPageSize {
+		limit = s.maxPageSize
+	}
+
+	records, err := s.repo.List(ctx, table, record.Query{
+		Filters: []record.Filter{{Operator: record.FilterAny, Value: alternatives}},
+		Sort:    sortKeys,
+		After:   after,
+		Limit:   limit + 1,
+		Fields:  fields,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := records
+	if len(records) > limit {
+		page = records[:limit]
+	}
+	result := &TranslationResult{Data: []MissingTranslation{}, Limit: limit}
+	for _, rec := range page {
+		normalizeRecord(table, rec)
+		key := make(record.Key)
+		for _, name := range primaryKeyNames(table) {
+			key[name] = rec[name]
+		}
+
+		item := MissingTranslation{ID: recordKey(table, key), Missing: make(map[string][]string)}
+		for _, col := range columns {
+			translations, ok := rec[col.Name].(map[string]string)
+			if !ok {
+				continue
+			}
+			for _, tag := range locales {
+				if translations[tag] == "" {
+					item.Missing[col.Name] = append(item.Missing[col.Name], tag)
+				}
+			}
+		}
+		result.Data = append(result.Data, item)
+	}
+	if len(records) > limit {
+		result.NextCursor, err = encodeCursor("", sortKeys, page[limit-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
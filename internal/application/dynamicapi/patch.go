@@ -0,0 +1,132 @@
+// File: internal/application/dynamicapi/patch.go
+
+package dynamicapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"quickflow/internal/domain/record"
+	"quickflow/pkg/errors"
+	"quickflow/pkg/jsonpatch"
+)
+
+// Patch applies a merge patch or JSON patch to the current row and writes the columns
+// it changed. The changed columns are validated like an update; a removed member sets
+// its column to null. The row must not change between the read and the write.
+func (s *RecordService) Patch(ctx context.Context, tableName, id string, patch jsonpatch.Patcher, ifMatch []int64) (record.Record, int64, error) {
+	table, err := s.getTable(ctx, tableName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := requireEditor(ctx, table); err != nil {
+		return nil, 0, err
+	}
+
+	key, err := parseKey(table, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var rec record.Record
+	var version int64
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.repo.Get(ctx, table, key, nil)
+		if err != nil {
+			return err
+		}
+		normalizeRecord(table, current)
+		currentVersion := takeVersion(current)
+		if len(ifMatch) > 0 && !containsVersion(ifMatch, currentVersion) {
+			return errors.NewAppError(errors.ErrorTypePreconditionFailed, "Record was modified by another request", nil)
+		}
+
+		doc, err := jsonDocument(current)
+		if err != nil {
+			return err
+		}
+		patched, err := patch.Apply(doc)
+		if err != nil {
+			return errors.NewAppError(errors.ErrorTypeValidation, fmt.Sprintf("Invalid patch: %v", err), nil)
+		}
+		object, ok := patched.(map[string]interface{})
+		if !ok {
+			return errors.NewAppError(errors.ErrorTypeValidation, "Patched record must be a JSON object", nil)
+		}
+
+		payload := changedMembers(doc, object)
+		for name := range key {
+			if _, ok := payload[name]; ok {
+				return errors.NewAppError(
+					errors.ErrorTypeValidation,
+					"Primary key columns cannot be updated",
+					nil,
+				)
+			}
+		}
+		if len(payload) == 0 {
+			rec, version = current, currentVersion
+			return nil
+		}
+
+		values, err := s.bindChecked(ctx, table, payload, key)
+		if err != nil {
+			return err
+		}
+		rec, err = s.writeUpdate(ctx, table, key, values, []int64{currentVersion}, record.OperationUpdate, nil)
+		if err != nil {
+			return err
+		}
+		version = takeVersion(rec)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return rec, version, nil
+}
+
+// jsonDocument converts a normalized record into the generic JSON value a patch applies to
+func jsonDocument(rec record.Record) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(rec)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to encode record", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to decode record", err)
+	}
+	return doc, nil
+}
+
+// changedMembers returns the members of after that differ from before, with removed members as nil
+func changedMembers(before, after map[string]interface{}) map[string]interface{} {
+	changed := make(map[string]interface{})
+	for name, value := range after {
+		if old, ok := before[name]; !ok || !reflect.DeepEqual(old, value) {
+			changed[name] = value
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changed[name] = nil
+		}
+	}
+	return changed
+}
+
+func containsVersion(versions []int64, version int64) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
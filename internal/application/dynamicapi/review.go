@@ -0,0 +1,215 @@
+// File: internal/application/dynamicapi/review.go
+
+package dynamicapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"quickflow/internal/application/auth"
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+)
+
+// ReviewStore keeps the transition log and review comments of workflow records
+type ReviewStore interface {
+	LogTransition(ctx context.Context, tableName, recordKey string, t record.Transition) error
+	ListTransitions(ctx context.Context, tableName, recordKey string) ([]record.Transition, error)
+	AddComment(ctx context.Context, tableName, recordKey string, comment *record.Comment) error
+	ListComments(ctx context.Context, tableName, recordKey string) ([]record.Comment, error)
+	DeleteComments(ctx context.Context, tableName, recordKey string) error
+}
+
+// MoveStage moves a draft to another review stage along a configured transition
+func (s *RecordService) MoveStage(ctx context.Context, tableName, id string, req StageRequest) (*record.State, error) {
+	table, key, principal, err := s.workflowRecord(ctx, tableName, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireStages(table); err != nil {
+		return nil, err
+	}
+	if _, ok := table.Workflow.FindStage(req.To); !ok {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Unknown workflow stage: %s", req.To),
+			nil,
+		)
+	}
+	rk := recordKey(table, key)
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.Get(ctx, table, key, primaryKeyNames(table)); err != nil {
+			return err
+		}
+		return s.moveStage(ctx, table, rk, principal, req.To, ActionMoveStage, strings.TrimSpace(req.Comment))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.recordState(ctx, table, rk)
+}
+
+// AddComment leaves a review comment on a record. A comment requesting changes also
+// sends the record back to the first stage, which needs a transition the reviewer may make.
+func (s *RecordService) AddComment(ctx context.Context, tableName, id string, req CommentRequest) (*record.Comment, error) {
+	table, key, principal, err := s.workflowRecord(ctx, tableName, id)
+	if err != nil {
+		return nil, err
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, errors.NewValidationError("Invalid comment", []errors.Violation{{
+			Field:   "body",
+			Rule:    "required",
+			Message: "body is required",
+		}})
+	}
+	if req.RequestChanges {
+		if err := requireStages(table); err != nil {
+			return nil, err
+		}
+	}
+	rk := recordKey(table, key)
+
+	comment := &record.Comment{
+		UserID:           &principal.UserID,
+		Body:             body,
+		ChangesRequested: req.RequestChanges,
+	}
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.Get(ctx, table, key, primaryKeyNames(table)); err != nil {
+			return err
+		}
+		if req.RequestChanges {
+			if err := s.moveStage(ctx, table, rk, principal, table.Workflow.InitialStage(), ActionRequestChanges, body); err != nil {
+				return err
+			}
+		}
+		return s.reviews.AddComment(ctx, table.Name, rk, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Comments returns the review comments of a record, oldest first
+func (s *RecordService) Comments(ctx context.Context, tableName, id string) ([]record.Comment, error) {
+	table, key, _, err := s.workflowRecord(ctx, tableName, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.Get(ctx, table, key, primaryKeyNames(table)); err != nil {
+		return nil, err
+	}
+	return s.reviews.ListComments(ctx, table.Name, recordKey(table, key))
+}
+
+// History returns the transition log of a record, oldest first. The log outlives
+// deleted records, so it is also available for keys that no longer exist.
+func (s *RecordService) History(ctx context.Context, tableName, id string) ([]record.Transition, error) {
+	table, key, _, err := s.workflowRecord(ctx, tableName, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.reviews.ListTransitions(ctx, table.Name, recordKey(table, key))
+}
+
+// moveStage checks the transition from the current stage and the role of the principal,
+// then moves the record and logs the move
+func (s *RecordService) moveStage(ctx context.Context, table *tableentity.Table, rk string, principal *auth.Principal, to string, action WorkflowAction, comment string) error {
+	before, err := s.recordState(ctx, table, rk)
+	if err != nil {
+		return err
+	}
+	if before.Stage == to {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Record is already in stage '%s'", to),
+			nil,
+		)
+	}
+
+	transition, ok := table.Workflow.FindTransition(before.Stage, to)
+	if !ok {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Records cannot move from stage '%s' to '%s'", before.Stage, to),
+			nil,
+		)
+	}
+	if !tableentity.RoleAllowed(transition.Roles, principal.Role) {
+		return errors.NewAppError(
+			errors.ErrorTypeForbidden,
+			fmt.Sprintf("Role '%s' may not move records from stage '%s' to '%s'", principal.Role, before.Stage, to),
+			nil,
+		)
+	}
+
+	if err := s.publications.SetStage(ctx, table.Name, rk, to, principal.UserID); err != nil {
+		return err
+	}
+	return s.reviews.LogTransition(ctx, table.Name, rk, record.Transition{
+		Action:     string(action),
+		FromStage:  before.Stage,
+		ToStage:    to,
+		FromStatus: before.Status,
+		ToStatus:   before.Status,
+		UserID:     &principal.UserID,
+		Comment:    comment,
+	})
+}
+
+// resetStage sends a record that was written back to the first review stage, since the
+// changes have not been reviewed yet. Records already there are left alone.
+func (s *RecordService) resetStage(ctx context.Context, table *tableentity.Table, rec record.Record) error {
+	if !table.Workflow.HasStages() {
+		return nil
+	}
+	key := make(record.Key)
+	for _, col := range table.PrimaryKey() {
+		key[col.Name] = rec[col.Name]
+	}
+	rk := recordKey(table, key)
+
+	before, err := s.recordState(ctx, table, rk)
+	if err != nil {
+		return err
+	}
+	initial := table.Workflow.InitialStage()
+	if before.Stage == initial {
+		return nil
+	}
+
+	var userID *uint
+	var setBy uint
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		userID, setBy = &principal.UserID, principal.UserID
+	}
+	if err := s.publications.SetStage(ctx, table.Name, rk, initial, setBy); err != nil {
+		return err
+	}
+	return s.reviews.LogTransition(ctx, table.Name, rk, record.Transition{
+		Action:     string(ActionEdit),
+		FromStage:  before.Stage,
+		ToStage:    initial,
+		FromStatus: before.Status,
+		ToStatus:   before.Status,
+		UserID:     userID,
+	})
+}
+
+func requireStages(table *tableentity.Table) error {
+	if !table.Workflow.HasStages() {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Table '%s' has no review stages", table.Name),
+			nil,
+		)
+	}
+	return nil
+}
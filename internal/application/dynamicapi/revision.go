@@ -0,0 +1,193 @@
+// File: internal/application/dynamicapi/revision.go
+
+package dynamicapi
+
+import (
+	"context"
+	"fmt"
+
+	"quickflow/internal/application/auth"
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+)
+
+// RevisionStore keeps the immutable revision history of records.
+// Add assigns the next revision number of the record.
+type RevisionStore interface {
+	Add(ctx context.Context, tableName, recordKey string, rev *record.Revision) error
+	List(ctx context.Context, tableName, recordKey string) ([]record.Revision, error)
+	Get(ctx context.Context, tableName, recordKey string, revision int) (*record.Revision, error)
+}
+
+// Revisions lists the revisions of a record, newest first. History outlives the record,
+// so revisions of deleted records can still be listed and restored.
+func (s *RecordService) Revisions(ctx context.Context, tableName, id string) ([]record.Revision, error) {
+	table, key, err := s.revisionRecord(ctx, tableName, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.revisions.List(ctx, table.Name, recordKey(table, key))
+}
+
+// Revision returns one revision of a record with its snapshot
+func (s *RecordService) Revision(ctx context.Context, tableName, id string, revision int) (*record.Revision, error) {
+	table, key, err := s.revisionRecord(ctx, tableName, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.revisions.Get(ctx, table.Name, recordKey(table, key), revision)
+}
+
+// DiffRevisions compares the snapshots of two revisions of a record
+func (s *RecordService) DiffRevisions(ctx context.Context, tableName, id string, from, to int) (*RevisionDiff, error) {
+	table, key, err := s.revisionRecord(ctx, tableName, id)
+	if err != nil {
+		return nil, err
+	}
+	rk := recordKey(table, key)
+
+	before, err := s.revisions.Get(ctx, table.Name, rk, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.revisions.Get(ctx, table.Name, rk, to)
+	if err != nil {
+		return nil, err
+	}
+	return &RevisionDiff{From: from, To: to, Changes: record.Diff(before.Data, after.Data)}, nil
+}
+
+// RestoreRevision writes the snapshot of an old revision back, recreating the record
+// if it was deleted. The snapshot is validated like any other write and produces a new revision.
+func (s *RecordService) RestoreRevision(ctx context.Context, tableName, id string, revision int) (record.Record, int64, error) {
+	table, key, err := s.revisionRecord(ctx, tableName, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	rk := recordKey(table, key)
+
+	var rec record.Record
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		rev, err := s.revisions.Get(ctx, table.Name, rk, revision)
+		if err != nil {
+			return err
+		}
+
+		// 定義から消えた列は復元しない
+		payload := make(map[string]interface{}, len(rev.Data))
+		columns := columnsByName(table)
+		for name, value := range rev.Data {
+			if col, ok := columns[name]; ok && !col.IsVirtual() {
+				payload[name] = value
+			}
+		}
+
+		_, err = s.repo.Get(ctx, table, key, primaryKeyNames(table))
+		var appErr *errors.AppError
+		switch {
+		case err == nil:
+			for name := range key {
+				delete(payload, name)
+			}
+			values, err := s.bindChecked(ctx, table, payload, key)
+			if err != nil {
+				return err
+			}
+			rec, err = s.writeUpdate(ctx, table, key, values, nil, record.OperationRestore, &revision)
+			return err
+		case errors.As(err, &appErr) && appErr.Type == errors.ErrorTypeNotFound:
+			values, err := s.bindChecked(ctx, table, payload, nil)
+			if err != nil {
+				return err
+			}
+			rec, err = s.writeCreate(ctx, table, values, record.OperationRestore, &revision)
+			return err
+		default:
+			return err
+		}
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return rec, takeVersion(rec), nil
+}
+
+func (s *RecordService) revisionRecord(ctx context.Context, tableName, id string) (*tableentity.Table, record.Key, error) {
+	table, err := s.getTable(ctx, tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := requireEditor(ctx, table); err != nil {
+		return nil, nil, err
+	}
+	key, err := parseKey(table, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return table, key, nil
+}
+
+// The write helpers below return the normalized record including its version column.
+
+// writeCreate inserts a record and stores its first revision
+func (s *RecordService) writeCreate(ctx context.Context, table *tableentity.Table, values record.Record, op record.Operation, restoredFrom *int) (record.Record, error) {
+	rec, err := s.repo.Create(ctx, table, values)
+	if err != nil {
+		return nil, err
+	}
+	normalizeRecord(table, rec)
+	return rec, s.addRevision(ctx, table, rec, op, restoredFrom)
+}
+
+// writeUpdate updates a record, stores the resulting revision and returns the record to
+// the first review stage
+func (s *RecordService) writeUpdate(ctx context.Context, table *tableentity.Table, key record.Key, values record.Record, ifMatch []int64, op record.Operation, restoredFrom *int) (record.Record, error) {
+	rec, err := s.repo.Update(ctx, table, key, values, ifMatch)
+	if err != nil {
+		return nil, err
+	}
+	normalizeRecord(table, rec)
+	if err := s.addRevision(ctx, table, rec, op, restoredFrom); err != nil {
+		return nil, err
+	}
+	return rec, s.resetStage(ctx, table, rec)
+}
+
+// writeUpsert inserts or updates a record, stores the resulting revision and returns the
+// record to the first review stage
+func (s *RecordService) writeUpsert(ctx context.Context, table *tableentity.Table, values record.Record, conflict []string) (record.Record, error) {
+	rec, err := s.repo.Upsert(ctx, table, values, conflict)
+	if err != nil {
+		return nil, err
+	}
+	normalizeRecord(table, rec)
+	if err := s.addRevision(ctx, table, rec, record.OperationUpsert, nil); err != nil {
+		return nil, err
+	}
+	return rec, s.resetStage(ctx, table, rec)
+}
+
+// addRevision stores a snapshot of a written record on behalf of the request's user.
+// The version column is left out so that diffs only show user data.
+func (s *RecordService) addRevision(ctx context.Context, table *tableentity.Table, rec record.Record, op record.Operation, restoredFrom *int) error {
+	data := make(record.Record, len(rec))
+	for name, value := range rec {
+		if name != tableentity.VersionColumn {
+			data[name] = value
+		}
+	}
+	rev := &record.Revision{Operation: op, RestoredFrom: restoredFrom, Data: data}
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		rev.UserID = &principal.UserID
+	}
+
+	key := make(record.Key)
+	for _, col := range table.PrimaryKey() {
+		if _, ok := rec[col.Name]; !ok {
+			return errors.NewAppError(errors.ErrorTypeInternal, fmt.Sprintf("Written record lacks primary key column %s", col.Name), nil)
+		}
+		key[col.Name] = rec[col.Name]
+	}
+	return s.revisions.Add(ctx, table.Name, recordKey(table, key), rev)
+}
@@ -0,0 +1,212 @@
+// File: internal/application/dynamicapi/schedule.go
+
+package dynamicapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"quickflow/internal/domain/record"
+	"quickflow/pkg/errors"
+	"quickflow/pkg/logger"
+)
+
+// scheduleBatchSize caps the schedules one instance claims per run
+const scheduleBatchSize = 100
+
+// scheduleRetryDelay is the wait after the first failed run of a schedule. It doubles with
+// every further failure up to maxScheduleRetryDelay.
+const (
+	scheduleRetryDelay    = time.Minute
+	maxScheduleRetryDelay = time.Hour
+)
+
+// Schedule replaces the publish and unpublish times of a record. Both times may be
+// set at once for a campaign; nil clears the corresponding transition.
+func (s *RecordService) Schedule(ctx context.Context, tableName, id string, schedule record.Schedule) (*record.State, error) {
+	table, key, principal, err := s.workflowRecord(ctx, tableName, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.PublishAt != nil && schedule.UnpublishAt != nil && !schedule.UnpublishAt.After(*schedule.PublishAt) {
+		return nil, errors.NewValidationError("Invalid schedule", []errors.Violation{{
+			Field:   "unpublish_at",
+			Rule:    "after_publish_at",
+			Message: "unpublish_at must be after publish_at",
+		}})
+	}
+	rk := recordKey(table, key)
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.Get(ctx, table, key, primaryKeyNames(table)); err != nil {
+			return err
+		}
+		return s.publications.SetSchedule(ctx, table.Name, rk, schedule, principal.UserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.publications.GetState(ctx, table.Name, rk)
+}
+
+// RunDueSchedules fires the schedules that are due at now and returns how many were processed.
+// Claimed rows stay locked until the transaction ends, so concurrent instances never fire
+// the same schedule twice, and schedules missed while no instance was running fire late.
+func (s *RecordService) RunDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	processed := 0
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		due, err := s.publications.ClaimDue(ctx, now, scheduleBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, schedule := range due {
+			// 1 件の失敗でバッチ全体を巻き戻さない
+			err := s.tx.WithinSavepoint(ctx, func(ctx context.Context) error {
+				return s.fireSchedule(ctx, schedule, now)
+			})
+			if err == nil {
+				processed++
+				continue
+			}
+
+			var appErr *errors.AppError
+			if !errors.As(err, &appErr) || appErr.Type != errors.ErrorTypeNotFound {
+				// 失敗した予約は間隔を空けて再試行し、後続の予約を妨げないようにする
+				retryAt := now.Add(scheduleRetryBackoff(schedule.Attempts))
+				if appErr != nil && appErr.Type == errors.ErrorTypePreconditionFailed {
+					logger.Warn("Record schedule is waiting", "table", schedule.TableName, "record", schedule.RecordKey, "retry_at", retryAt, "reason", err.Error())
+				} else {
+					logger.Error("Failed to run record schedule", "table", schedule.TableName, "record", schedule.RecordKey, "attempts", schedule.Attempts+1, "retry_at", retryAt, "error", err.Error())
+				}
+				if failErr := s.publications.FailDue(ctx, schedule.TableName, schedule.RecordKey, err.Error(), retryAt); failErr != nil {
+					return failErr
+				}
+				continue
+			}
+			// テーブルやレコードが消えた予約は実行できないので破棄する
+			logger.Warn("Dropping schedule of a missing record", "table", schedule.TableName, "record", schedule.RecordKey)
+			if err := s.publications.ClearDue(ctx, schedule.TableName, schedule.RecordKey, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return processed, err
+}
+
+// scheduleRetryBackoff is the wait before a schedule that failed attempts times is run again
+func scheduleRetryBackoff(attempts int) time.Duration {
+	delay := scheduleRetryDelay
+	for i := 0; i < attempts && delay < maxScheduleRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxScheduleRetryDelay)
+}
+
+// fireSchedule applies the due transitions of one schedule in time order
+func (s *RecordService) fireSchedule(ctx context.Context, schedule record.DueSchedule, now time.Time) error {
+	table, err := s.tables.GetTable(ctx, schedule.TableName)
+	if err != nil {
+		return err
+	}
+	if !table.HasWorkflow() {
+		return errors.NewAppError(errors.ErrorTypeNotFound, "Scheduled table no longer uses the content workflow", nil)
+	}
+	key, err := parseKey(table, schedule.RecordKey)
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeNotFound, "Scheduled record key no longer matches the table", err)
+	}
+
+	var userID uint
+	if schedule.ScheduledBy != nil {
+		userID = *schedule.ScheduledBy
+	}
+
+	state, err := s.recordState(ctx, table, schedule.RecordKey)
+	if err != nil {
+		return err
+	}
+	log := func(action WorkflowAction, to record.Status) error {
+		t := record.Transition{
+			Action:     string(action),
+			FromStage:  state.Stage,
+			ToStage:    state.Stage,
+			FromStatus: state.Status,
+			ToStatus:   to,
+			UserID:     schedule.ScheduledBy,
+		}
+		state.Status = to
+		return s.reviews.LogTransition(ctx, table.Name, schedule.RecordKey, t)
+	}
+
+	if schedule.PublishAt != nil && !schedule.PublishAt.After(now) {
+		if stage, ok := table.Workflow.FindStage(state.Stage); table.Workflow.HasStages() && (!ok || !stage.Publishable) {
+			// 承認前のレコードは公開しない。予約は残し、理由を schedule_error に記録して再試行する
+			return errors.NewAppError(
+				errors.ErrorTypePreconditionFailed,
+				fmt.Sprintf("Scheduled publish is waiting for the record to leave stage '%s', which is not publishable", state.Stage),
+				nil,
+			)
+		}
+		if err := s.publications.Publish(ctx, table, key, schedule.RecordKey, userID); err != nil {
+			return err
+		}
+		if err := log(ActionScheduledPublish, record.StatusPublished); err != nil {
+			return err
+		}
+	}
+	if schedule.UnpublishAt != nil && !schedule.UnpublishAt.After(now) {
+		if err := s.publications.SetStatus(ctx, table.Name, schedule.RecordKey, record.StatusDraft, userID); err != nil {
+			return err
+		}
+		if err := log(ActionScheduledUnpublish, record.StatusDraft); err != nil {
+			return err
+		}
+	}
+	return s.publications.ClearDue(ctx, table.Name, schedule.RecordKey, now)
+}
+
+// Scheduler periodically fires due publish and unpublish schedules. Schedules live in
+// the database, so they survive restarts and may be served by any number of instances.
+type Scheduler struct {
+	service  *RecordService
+	interval time.Duration
+}
+
+func NewScheduler(service *RecordService, interval time.Duration) *Scheduler {
+	return &Scheduler{service: service, interval: interval}
+}
+
+// Run fires due schedules every interval until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		// バッチが埋まった場合は待たずに続きを処理する
+		for {
+			processed, err := s.service.RunDueSchedules(ctx, time.Now())
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Error("Failed to run record schedules", "error", err.Error())
+				}
+				break
+			}
+			if processed > 0 {
+				logger.Info("Ran record schedules", "count", processed)
+			}
+			if processed < scheduleBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1 +1,342 @@
+// File: internal/application/dynamicapi/service.go
+
 package dynamicapi
+
+import (
+	"context"
+	"fmt"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+	"quickflow/pkg/locale"
+)
+
+// defaultPageSize is used when a listing does not ask for a limit
+const defaultPageSize = 20
+
+// TableSource resolves the definition of a user-defined table by name
+type TableSource interface {
+	GetTable(ctx context.Context, name string) (*tableentity.Table, error)
+}
+
+// RecordReader is the read side of RecordRepository
+type RecordReader interface {
+	List(ctx context.Context, table *tableentity.Table, query record.Query) ([]record.Record, error)
+	Count(ctx context.Context, table *tableentity.Table, filters []record.Filter) (int64, error)
+	Get(ctx context.Context, table *tableentity.Table, key record.Key, fields []string) (record.Record, error)
+	ListByValues(ctx context.Context, table *tableentity.Table, column string, values []interface{}) ([]record.Record, error)
+	ListRelated(ctx context.Context, target *tableentity.Table, join *tableentity.Table, sources []interface{}) ([]record.Related, error)
+}
+
+type RecordRepository interface {
+	RecordReader
+	Create(ctx context.Context, table *tableentity.Table, values record.Record) (record.Record, error)
+	Upsert(ctx context.Context, table *tableentity.Table, values record.Record, conflict []string) (record.Record, error)
+	Update(ctx context.Context, table *tableentity.Table, key record.Key, values record.Record, expected []int64) (record.Record, error)
+	Delete(ctx context.Context, table *tableentity.Table, key record.Key) error
+}
+
+// RecordValidator checks payloads before they are bound and written.
+// Each method reports every violation it finds in a single validation error.
+type RecordValidator interface {
+	Check(table *tableentity.Table, payload map[string]interface{}, partial bool) error
+	CheckExpressions(ctx context.Context, table *tableentity.Table, payload map[string]interface{}, key record.Key) error
+	CheckUnique(ctx context.Context, table *tableentity.Table, values record.Record, key record.Key) error
+	CheckAssets(ctx context.Context, table *tableentity.Table, values record.Record) error
+}
+
+// Transactor runs functions inside a database transaction carried by the context.
+// WithinSavepoint undoes only the work of fn when it fails.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type RecordService struct {
+	tables TableSource
+	repo   RecordRepository
+	// published reads workflow-enabled tables from their published snapshots
+	published    RecordReader
+	publications PublicationStore
+	reviews      ReviewStore
+	revisions    RevisionStore
+	validator    RecordValidator
+	tx           Transactor
+	maxPageSize  int
+	// locales resolve localized columns on reads
+	locales locale.Settings
+}
+
+func NewRecordService(tables TableSource, repo RecordRepository, published RecordReader, publications PublicationStore, reviews ReviewStore, revisions RevisionStore, validator RecordValidator, tx Transactor, maxPageSize int, locales locale.Settings) *RecordService {
+	return &RecordService{
+		tables:       tables,
+		repo:         repo,
+		published:    published,
+		publications: publications,
+		reviews:      reviews,
+		revisions:    revisions,
+		validator:    validator,
+		tx:           tx,
+		maxPageSize:  maxPageSize,
+		locales:      locales,
+	}
+}
+
+// getTable resolves a table whose records the API serves. The rows of join tables are only
+// reachable through their many-to-many column.
+func (s *RecordService) getTable(ctx context.Context, tableName string) (*tableentity.Table, error) {
+	table, err := s.tables.GetTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if table.JoinFor != "" {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Table '%s' is managed through the many-to-many column %s", table.Name, table.JoinFor),
+			nil,
+		)
+	}
+	return table, nil
+}
+
+func (s *RecordService) List(ctx context.Context, tableName string, params ListParams) (*ListResult, error) {
+	table, err := s.getTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	filters, err := bindFilters(table, params.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	sortKeys, err := parseSort(table, params.Sort)
+	if err != nil {
+		return nil, err
+	}
+	sortColumns := make([]string, len(sortKeys))
+	for i, key := range sortKeys {
+		sortColumns[i] = key.Column
+	}
+	sh, err := buildShape(table, params.Fields, params.Expand, sortColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	var after []interface{}
+	if params.Cursor != "" {
+		after, err = decodeCursor(table, params.Sort, sortKeys, params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > s.maxPageSize {
+		limit = s.maxPageSize
+	}
+
+	reader := s.reader(ctx)
+	ctx = withLocale(ctx, params.Locale)
+
+	// 1 件多く読み、次のページがあるかを判定する
+	records, err := reader.List(ctx, table, record.Query{
+		Filters: filters,
+		Sort:    sortKeys,
+		After:   after,
+		Limit:   limit + 1,
+		Fields:  sh.columns,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ListResult{Data: records, Limit: limit}
+	if len(records) > limit {
+		result.Data = records[:limit]
+	}
+	for _, rec := range result.Data {
+		normalizeRecord(table, rec)
+		s.localize(ctx, table, rec)
+	}
+	if len(records) > limit {
+		result.NextCursor, err = encodeCursor(params.Sort, sortKeys, result.Data[limit-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := s.expandRelations(ctx, reader, table, result.Data, sh.expand); err != nil {
+		return nil, err
+	}
+	for _, rec := range result.Data {
+		sh.trim(rec)
+	}
+
+	if params.IncludeTotal {
+		total, err := reader.Count(ctx, table, filters)
+		if err != nil {
+			return nil, err
+		}
+		result.Total = &total
+	}
+
+	return result, nil
+}
+
+// Get reads one record and returns it with its version
+func (s *RecordService) Get(ctx context.Context, tableName, id string, params GetParams) (record.Record, int64, error) {
+	table, err := s.getTable(ctx, tableName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	key, err := parseKey(table, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sh, err := buildShape(table, params.Fields, params.Expand, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	reader := s.reader(ctx)
+	ctx = withLocale(ctx, params.Locale)
+	rec, err := reader.Get(ctx, table, key, sh.columns)
+	if err != nil {
+		return nil, 0, err
+	}
+	normalizeRecord(table, rec)
+	s.localize(ctx, table, rec)
+	version := takeVersion(rec)
+	if err := s.expandRelations(ctx, reader, table, []record.Record{rec}, sh.expand); err != nil {
+		return nil, 0, err
+	}
+	return sh.trim(rec), version, nil
+}
+
+// Create inserts a record and returns it with its version
+func (s *RecordService) Create(ctx context.Context, tableName string, payload map[string]interface{}) (record.Record, int64, error) {
+	table, err := s.getTable(ctx, tableName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := requireEditor(ctx, table); err != nil {
+		return nil, 0, err
+	}
+
+	values, err := s.bindChecked(ctx, table, payload, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var rec record.Record
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		rec, err = s.writeCreate(ctx, table, values, record.OperationCreate, nil)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return rec, takeVersion(rec), nil
+}
+
+// Update writes the payload columns of a record and returns it with its new version.
+// A non-empty ifMatch makes the update fail with a precondition error unless the
+// current version is one of the given versions.
+func (s *RecordService) Update(ctx context.Context, tableName, id string, payload map[string]interface{}, ifMatch []int64) (record.Record, int64, error) {
+	table, err := s.getTable(ctx, tableName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := requireEditor(ctx, table); err != nil {
+		return nil, 0, err
+	}
+
+	key, err := parseKey(table, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for name := range key {
+		if _, ok := payload[name]; ok {
+			return nil, 0, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				"Primary key columns cannot be updated",
+				nil,
+			)
+		}
+	}
+
+	values, err := s.bindChecked(ctx, table, payload, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(values) == 0 {
+		return nil, 0, errors.NewAppError(
+			errors.ErrorTypeValidation,
+			"At least one column must be provided",
+			nil,
+		)
+	}
+
+	var rec record.Record
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		rec, err = s.writeUpdate(ctx, table, key, values, ifMatch, record.OperationUpdate, nil)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return rec, takeVersion(rec), nil
+}
+
+// bindChecked validates a payload, binds it to column types and runs the expression rules,
+// unique and asset pre-checks.
+// key is set for updates, which only bind the columns present in the payload.
+func (s *RecordService) bindChecked(ctx context.Context, table *tableentity.Table, payload map[string]interface{}, key record.Key) (record.Record, error) {
+	partial := key != nil
+	if err := s.validator.Check(table, payload, partial); err != nil {
+		return nil, err
+	}
+	values, err := bindRecord(table, payload, partial)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validator.CheckExpressions(ctx, table, payload, key); err != nil {
+		return nil, err
+	}
+	if err := s.validator.CheckUnique(ctx, table, values, key); err != nil {
+		return nil, err
+	}
+	if err := s.validator.CheckAssets(ctx, table, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (s *RecordService) Delete(ctx context.Context, tableName, id string) error {
+	table, err := s.getTable(ctx, tableName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := requireEditor(ctx, table); err != nil {
+		return err
+	}
+
+	key, err := parseKey(table, id)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.deleteRecord(ctx, table, key)
+	})
+}
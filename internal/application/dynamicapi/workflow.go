@@ -0,0 +1,246 @@
+// File: internal/application/dynamicapi/workflow.go
+
+package dynamicapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"quickflow/internal/application/auth"
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/internal/domain/user"
+	"quickflow/pkg/errors"
+)
+
+// PublicationStore keeps the workflow state and published snapshot of records.
+// Records are identified by their table name and the key string of recordKey.
+type PublicationStore interface {
+	Publish(ctx context.Context, table *tableentity.Table, key record.Key, recordKey string, userID uint) error
+	SetStatus(ctx context.Context, tableName, recordKey string, status record.Status, userID uint) error
+	GetState(ctx context.Context, tableName, recordKey string) (*record.State, error)
+	Delete(ctx context.Context, tableName, recordKey string) error
+	SetStage(ctx context.Context, tableName, recordKey, stage string, userID uint) error
+	SetSchedule(ctx context.Context, tableName, recordKey string, schedule record.Schedule, userID uint) error
+	ClaimDue(ctx context.Context, now time.Time, limit int) ([]record.DueSchedule, error)
+	ClearDue(ctx context.Context, tableName, recordKey string, now time.Time) error
+	FailDue(ctx context.Context, tableName, recordKey, message string, retryAt time.Time) error
+}
+
+// WorkflowAction moves a record between workflow states
+type WorkflowAction string
+
+const (
+	// ActionPublish snapshots the current draft and serves it to readers
+	ActionPublish WorkflowAction = "publish"
+	// ActionUnpublish withdraws the snapshot and returns the record to draft
+	ActionUnpublish WorkflowAction = "unpublish"
+	// ActionArchive withdraws the record and keeps its last snapshot
+	ActionArchive WorkflowAction = "archive"
+	// ActionMoveStage moves a draft to another review stage
+	ActionMoveStage WorkflowAction = "move_stage"
+	// ActionRequestChanges sends a draft back to the first review stage with a comment
+	ActionRequestChanges WorkflowAction = "request_changes"
+	// ActionEdit returns an edited draft to the first review stage
+	ActionEdit WorkflowAction = "edit"
+	// ActionScheduledPublish and ActionScheduledUnpublish are fired by the scheduler
+	ActionScheduledPublish   WorkflowAction = "scheduled_publish"
+	ActionScheduledUnpublish WorkflowAction = "scheduled_unpublish"
+)
+
+// reader picks the records a request may see: editors see the drafts, everyone else only
+// sees the published snapshots of workflow-enabled tables
+func (s *RecordService) reader(ctx context.Context) RecordReader {
+	if principal, ok := auth.PrincipalFrom(ctx); ok && user.IsEditor(principal.Role) {
+		return s.repo
+	}
+	return s.published
+}
+
+// requireEditor rejects record writes from anonymous callers. Workflow-enabled tables
+// additionally need an editor role.
+func requireEditor(ctx context.Context, table *tableentity.Table) (*auth.Principal, error) {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeUnauthorized,
+			fmt.Sprintf("Writing records of table '%s' needs an authenticated user", table.Name),
+			nil,
+		)
+	}
+	if table.HasWorkflow() && !user.IsEditor(principal.Role) {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeForbidden,
+			fmt.Sprintf("Role '%s' may not edit records of table '%s'", principal.Role, table.Name),
+			nil,
+		)
+	}
+	return principal, nil
+}
+
+// deleteRecord removes a record together with its workflow state. The record as it was
+// before the delete is kept as its last revision.
+func (s *RecordService) deleteRecord(ctx context.Context, table *tableentity.Table, key record.Key) error {
+	rec, err := s.repo.Get(ctx, table, key, nil)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, table, key); err != nil {
+		return err
+	}
+	if err := s.addRevision(ctx, table, normalizeRecord(table, rec), record.OperationDelete, nil); err != nil {
+		return err
+	}
+	if !table.HasWorkflow() {
+		return nil
+	}
+	rk := recordKey(table, key)
+	if err := s.publications.Delete(ctx, table.Name, rk); err != nil {
+		return err
+	}
+	return s.reviews.DeleteComments(ctx, table.Name, rk)
+}
+
+// Transition applies a workflow action to a record and returns its new state.
+// In tables with review stages only records in a publishable stage may be published.
+func (s *RecordService) Transition(ctx context.Context, tableName, id string, action WorkflowAction) (*record.State, error) {
+	table, key, principal, err := s.workflowRecord(ctx, tableName, id)
+	if err != nil {
+		return nil, err
+	}
+	if !tableentity.RoleAllowed(table.Workflow.PublishRoles, principal.Role) {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeForbidden,
+			fmt.Sprintf("Role '%s' may not %s records of table '%s'", principal.Role, action, table.Name),
+			nil,
+		)
+	}
+	rk := recordKey(table, key)
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// 存在しないレコードの状態は作らない
+		if _, err := s.repo.Get(ctx, table, key, primaryKeyNames(table)); err != nil {
+			return err
+		}
+		before, err := s.recordState(ctx, table, rk)
+		if err != nil {
+			return err
+		}
+
+		var status record.Status
+		switch action {
+		case ActionPublish:
+			if stage, ok := table.Workflow.FindStage(before.Stage); table.Workflow.HasStages() && (!ok || !stage.Publishable) {
+				return errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Records in stage '%s' cannot be published", before.Stage),
+					nil,
+				)
+			}
+			status = record.StatusPublished
+			err = s.publications.Publish(ctx, table, key, rk, principal.UserID)
+		case ActionUnpublish:
+			status = record.StatusDraft
+			err = s.publications.SetStatus(ctx, table.Name, rk, status, principal.UserID)
+		case ActionArchive:
+			status = record.StatusArchived
+			err = s.publications.SetStatus(ctx, table.Name, rk, status, principal.UserID)
+		default:
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Unknown workflow action: %s", action),
+				nil,
+			)
+		}
+		if err != nil {
+			return err
+		}
+
+		return s.reviews.LogTransition(ctx, table.Name, rk, record.Transition{
+			Action:     string(action),
+			FromStage:  before.Stage,
+			ToStage:    before.Stage,
+			FromStatus: before.Status,
+			ToStatus:   status,
+			UserID:     &principal.UserID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.recordState(ctx, table, rk)
+}
+
+// State returns the workflow state of a record
+func (s *RecordService) State(ctx context.Context, tableName, id string) (*record.State, error) {
+	table, key, _, err := s.workflowRecord(ctx, tableName, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.Get(ctx, table, key, primaryKeyNames(table)); err != nil {
+		return nil, err
+	}
+	return s.recordState(ctx, table, recordKey(table, key))
+}
+
+// recordState reads the stored state of a record. Records that never entered
+// a stage are in the initial stage of the workflow.
+func (s *RecordService) recordState(ctx context.Context, table *tableentity.Table, rk string) (*record.State, error) {
+	state, err := s.publications.GetState(ctx, table.Name, rk)
+	if err != nil {
+		return nil, err
+	}
+	if state.Stage == "" {
+		state.Stage = table.Workflow.InitialStage()
+	}
+	return state, nil
+}
+
+func (s *RecordService) workflowRecord(ctx context.Context, tableName, id string) (*tableentity.Table, record.Key, *auth.Principal, error) {
+	table, err := s.getTable(ctx, tableName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !table.HasWorkflow() {
+		return nil, nil, nil, errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Table '%s' does not use the content workflow", table.Name),
+			nil,
+		)
+	}
+	principal, err := requireEditor(ctx, table)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	key, err := parseKey(table, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return table, key, principal, nil
+}
+
+// recordKey renders primary key values in the comma separated form of record IDs
+func recordKey(table *tableentity.Table, key record.Key) string {
+	var parts []string
+	for _, col := range table.PrimaryKey() {
+		switch v := key[col.Name].(type) {
+		case time.Time:
+			parts = append(parts, v.UTC().Format(time.RFC3339Nano))
+		default:
+			parts = append(parts, fmt.Sprint(v))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func primaryKeyNames(table *tableentity.Table) []string {
+	var names []string
+	for _, col := range table.PrimaryKey() {
+		names = append(names, col.Name)
+	}
+	return names
+}
@@ -1 +1,15 @@
+// File: internal/application/schema/dto.go
+
 package schema
+
+// UpdateMetadataRequest changes descriptive metadata without touching the physical table
+type UpdateMetadataRequest struct {
+	DisplayName *string                   `json:"display_name,omitempty"`
+	Description *string                   `json:"description,omitempty"`
+	Columns     map[string]ColumnMetadata `json:"columns,omitempty"`
+}
+
+type ColumnMetadata struct {
+	DisplayName *string `json:"display_name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
@@ -1 +1,105 @@
+// File: internal/application/schema/service.go
+
 package schema
+
+import (
+	"context"
+	"strings"
+
+	"quickflow/internal/domain/schema"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+)
+
+type Repository interface {
+	Create(ctx context.Context, s *schema.Schema) error
+	GetByTableName(ctx context.Context, tableName string) (*schema.Schema, error)
+	List(ctx context.Context) ([]*schema.Schema, error)
+	Update(ctx context.Context, s *schema.Schema) error
+	Delete(ctx context.Context, tableName string) error
+}
+
+type SchemaService struct {
+	repo Repository
+}
+
+func NewSchemaService(repo Repository) *SchemaService {
+	return &SchemaService{repo: repo}
+}
+
+// Register stores the definition of a newly created table in the catalog
+func (s *SchemaService) Register(ctx context.Context, table *tableentity.Table, ownerID *uint) error {
+	return s.repo.Create(ctx, schema.NewSchema(table, ownerID))
+}
+
+func (s *SchemaService) GetSchema(ctx context.Context, tableName string) (*schema.Schema, error) {
+	return s.repo.GetByTableName(ctx, strings.ToLower(tableName))
+}
+
+func (s *SchemaService) ListSchemas(ctx context.Context) ([]*schema.Schema, error) {
+	return s.repo.List(ctx)
+}
+
+// GetTable returns the catalog definition of a user-defined table
+func (s *SchemaService) GetTable(ctx context.Context, tableName string) (*tableentity.Table, error) {
+	entry, err := s.GetSchema(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return entry.Table(), nil
+}
+
+// ListTables returns the catalog definitions of every user-defined table
+func (s *SchemaService) ListTables(ctx context.Context) ([]*tableentity.Table, error) {
+	entries, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]*tableentity.Table, len(entries))
+	for i, entry := range entries {
+		tables[i] = entry.Table()
+	}
+	return tables, nil
+}
+
+// Unregister removes the definition of a dropped table from the catalog
+func (s *SchemaService) Unregister(ctx context.Context, tableName string) error {
+	return s.repo.Delete(ctx, strings.ToLower(tableName))
+}
+
+// UpdateTable replaces the catalog definition after the physical table has been altered
+func (s *SchemaService) UpdateTable(ctx context.Context, table *tableentity.Table) error {
+	entry, err := s.GetSchema(ctx, table.Name)
+	if err != nil {
+		return err
+	}
+
+	entry.UpdateDefinition(table)
+	return s.repo.Update(ctx, entry)
+}
+
+func (s *SchemaService) UpdateMetadata(ctx context.Context, tableName string, req UpdateMetadataRequest) (*schema.Schema, error) {
+	entry, err := s.GetSchema(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DisplayName != nil {
+		entry.SetDisplayName(*req.DisplayName)
+	}
+	if req.Description != nil {
+		entry.SetDescription(*req.Description)
+	}
+	for columnName, meta := range req.Columns {
+		if err := entry.SetColumnMetadata(columnName, meta.DisplayName, meta.Description); err != nil {
+			return nil, errors.NewAppError(errors.ErrorTypeValidation, err.Error(), nil)
+		}
+	}
+
+	entry.UpdateDefinition(entry.Table())
+	if err := s.repo.Update(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
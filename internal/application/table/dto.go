@@ -0,0 +1,44 @@
+// File: internal/application/table/dto.go
+
+package table
+
+import (
+	"fmt"
+	"strings"
+
+	"quickflow/internal/domain/tableentity"
+)
+
+// AlterResult lists what AlterTable changed
+type AlterResult struct {
+	Changes        []tableentity.ColumnChange `json:"changes"`
+	DroppedIndexes []tableentity.Index        `json:"dropped_indexes,omitempty"`
+	AddedIndexes   []tableentity.Index        `json:"added_indexes,omitempty"`
+}
+
+// Plan is the outcome of a dry run: the SQL that would be executed and a readable summary of each step
+type Plan struct {
+	Statements  []string `json:"statements"`
+	Steps       []string `json:"steps"`
+	Destructive []string `json:"destructive,omitempty"`
+}
+
+func (p *Plan) add(step string, statements ...string) {
+	p.Steps = append(p.Steps, step)
+	p.Statements = append(p.Statements, statements...)
+}
+
+func (p *Plan) addCreateTable(table *tableentity.Table, statements []string) {
+	p.add("create table "+table.Name, statements...)
+	for _, index := range table.Indexes {
+		p.Steps = append(p.Steps, indexStep(index))
+	}
+}
+
+func indexStep(index tableentity.Index) string {
+	step := "create index"
+	if index.Unique {
+		step = "create unique index"
+	}
+	return fmt.Sprintf("%s %s on (%s) using %s", step, index.Name, strings.Join(index.Columns, ", "), index.Method)
+}
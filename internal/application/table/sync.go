@@ -0,0 +1,409 @@
+// File: internal/application/table/sync.go
+
+package table
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaFile is the declarative format of a schema kept in version control.
+// YAML and JSON files share the JSON field names of tableentity.Table.
+type SchemaFile struct {
+	Tables []*tableentity.Table `json:"tables"`
+}
+
+type SyncAction string
+
+const (
+	SyncCreate    SyncAction = "create"
+	SyncAlter     SyncAction = "alter"
+	SyncUnchanged SyncAction = "unchanged"
+)
+
+// SyncPlan describes how the database differs from a schema file
+type SyncPlan struct {
+	Tables []TableSync `json:"tables"`
+	// Drift lists differences the schema file does not account for: tables outside the file
+	// and live tables that no longer match their catalog definitions
+	Drift []string `json:"drift,omitempty"`
+}
+
+type TableSync struct {
+	Table  string     `json:"table"`
+	Action SyncAction `json:"action"`
+	Plan   *Plan      `json:"plan,omitempty"`
+}
+
+// HasChanges reports whether applying the plan would change anything
+func (p *SyncPlan) HasChanges() bool {
+	for _, t := range p.Tables {
+		if t.Action != SyncUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSchemaFile reads a YAML or JSON schema file
+func ParseSchemaFile(data []byte) (*SchemaFile, error) {
+	// YAML は JSON の上位互換なので一度汎用値に読み、JSON タグでテーブル定義へ変換する
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "Invalid schema file", err)
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "Invalid schema file", err)
+	}
+
+	var file SchemaFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "Invalid schema file", err)
+	}
+	if len(file.Tables) == 0 {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "Schema file defines no tables", nil)
+	}
+	return &file, nil
+}
+
+// PlanSchema diffs the tables of a schema file against the catalog and the live database
+// without changing anything
+func (s *TableService) PlanSchema(ctx context.Context, file *SchemaFile) (*SyncPlan, error) {
+	tables, err := prepareSchemaFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return s.planSchema(ctx, tables)
+}
+
+func (s *TableService) planSchema(ctx context.Context, tables []*tableentity.Table) (*SyncPlan, error) {
+	// 未作成のテーブルへのリレーションを検証できるよう、計画中のテーブルをカタログに重ねる
+	pending := make(map[string]*tableentity.Table)
+	planner := &TableService{repo: s.repo, catalog: &pendingCatalog{Catalog: s.catalog, pending: pending}, tx: s.tx}
+
+	plan := &SyncPlan{Tables: []TableSync{}}
+	for _, table := range tables {
+		action, err := s.syncAction(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+
+		var tablePlan *Plan
+		switch action {
+		case SyncCreate:
+			tablePlan, err = planner.PlanCreateTable(ctx, table)
+			pending[table.Name] = table
+		default:
+			tablePlan, err = planner.PlanAlterTable(ctx, table)
+			if err == nil && len(tablePlan.Statements) == 0 {
+				tablePlan.Steps = append(tablePlan.Steps, "update catalog definition")
+			}
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "table "+table.Name)
+		}
+
+		entry := TableSync{Table: table.Name, Action: action, Plan: tablePlan}
+		if action == SyncAlter {
+			unchanged, err := s.catalogMatches(ctx, table)
+			if err != nil {
+				return nil, err
+			}
+			if unchanged && len(tablePlan.Statements) == 0 {
+				entry.Action = SyncUnchanged
+				entry.Plan = nil
+			}
+		}
+		plan.Tables = append(plan.Tables, entry)
+	}
+
+	drift, err := s.detectDrift(ctx, tables)
+	if err != nil {
+		return nil, err
+	}
+	plan.Drift = drift
+	return plan, nil
+}
+
+// ApplySchema creates and alters the tables of a schema file in a single transaction.
+// Index builds that must run concurrently are started after the transaction commits.
+func (s *TableService) ApplySchema(ctx context.Context, file *SchemaFile, confirmDestructive bool, ownerID *uint) (*SyncPlan, error) {
+	tables, err := prepareSchemaFile(file)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.planSchema(ctx, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	type concurrentWork struct {
+		table *tableentity.Table
+		alter *alterPlan
+	}
+	var deferred []concurrentWork
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		deferred = nil
+		for i, table := range tables {
+			switch plan.Tables[i].Action {
+			case SyncCreate:
+				if err := s.createWithJoinTables(ctx, table, ownerID); err != nil {
+					return errors.Wrap(err, "table "+table.Name)
+				}
+			case SyncAlter:
+				alter, err := s.alterTable(ctx, table, confirmDestructive)
+				if err != nil {
+					return errors.Wrap(err, "table "+table.Name)
+				}
+				if alter.concurrent {
+					deferred = append(deferred, concurrentWork{table: table, alter: alter})
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, work := range deferred {
+		if err := s.applyIndexesConcurrently(ctx, work.table, work.alter.result); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// prepareSchemaFile normalizes and validates the file tables and orders them so that
+// referenced tables come before the tables referencing them
+func prepareSchemaFile(file *SchemaFile) ([]*tableentity.Table, error) {
+	byName := make(map[string]*tableentity.Table, len(file.Tables))
+	for _, table := range file.Tables {
+		normalizeTable(table)
+		if err := validateTable(table); err != nil {
+			return nil, err
+		}
+		if byName[table.Name] != nil {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Table '%s' is defined more than once", table.Name),
+				nil,
+			)
+		}
+		if systemTables[table.Name] {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Table '%s' is managed by migrations", table.Name),
+				nil,
+			)
+		}
+		byName[table.Name] = table
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(byName))
+	ordered := make([]*tableentity.Table, 0, len(byName))
+
+	var visit func(table *tableentity.Table) error
+	visit = func(table *tableentity.Table) error {
+		switch state[table.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Relations between tables form a cycle at '%s'", table.Name),
+				nil,
+			)
+		}
+		state[table.Name] = visiting
+		for _, col := range table.Columns {
+			if col.Relation == nil || col.Relation.Table == table.Name {
+				continue
+			}
+			if target, ok := byName[col.Relation.Table]; ok {
+				if err := visit(target); err != nil {
+					return err
+				}
+			}
+		}
+		state[table.Name] = visited
+		ordered = append(ordered, table)
+		return nil
+	}
+
+	for _, table := range file.Tables {
+		if err := visit(table); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+func (s *TableService) syncAction(ctx context.Context, table *tableentity.Table) (SyncAction, error) {
+	if _, err := s.catalog.GetTable(ctx, table.Name); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Type == errors.ErrorTypeNotFound {
+			return SyncCreate, nil
+		}
+		return "", err
+	}
+	return SyncAlter, nil
+}
+
+// catalogMatches reports whether the catalog already holds exactly the file definition
+func (s *TableService) catalogMatches(ctx context.Context, table *tableentity.Table) (bool, error) {
+	current, err := s.catalog.GetTable(ctx, table.Name)
+	if err != nil {
+		return false, err
+	}
+	a, err := json.Marshal(current)
+	if err != nil {
+		return false, err
+	}
+	b, err := json.Marshal(table)
+	if err != nil {
+		return false, err
+	}
+	return string(a) == string(b), nil
+}
+
+// detectDrift reports catalog tables missing from the file, tables that exist only in the
+// database, and live tables whose structure no longer matches the catalog
+func (s *TableService) detectDrift(ctx context.Context, fileTables []*tableentity.Table) ([]string, error) {
+	managed := make(map[string]bool)
+	for _, table := range fileTables {
+		managed[table.Name] = true
+		for _, col := range table.Columns {
+			if col.IsVirtual() {
+				managed[tableentity.JoinTableName(table, col)] = true
+			}
+		}
+	}
+
+	catalogTables, err := s.catalog.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	liveNames, err := s.repo.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]bool, len(liveNames))
+	for _, name := range liveNames {
+		live[name] = true
+	}
+
+	var liveCatalogNames []string
+	for _, expected := range catalogTables {
+		if live[expected.Name] {
+			liveCatalogNames = append(liveCatalogNames, expected.Name)
+		}
+	}
+	described, err := s.repo.DescribeTables(ctx, liveCatalogNames)
+	if err != nil {
+		return nil, err
+	}
+	actualTables := make(map[string]*tableentity.Table, len(described))
+	for _, table := range described {
+		actualTables[table.Name] = table
+	}
+
+	var drift []string
+	inCatalog := make(map[string]bool, len(catalogTables))
+	for _, expected := range catalogTables {
+		inCatalog[expected.Name] = true
+		if !managed[expected.Name] {
+			drift = append(drift, fmt.Sprintf("table %s is in the catalog but not in the schema file", expected.Name))
+		}
+		actual, ok := actualTables[expected.Name]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("table %s is in the catalog but missing from the database", expected.Name))
+			continue
+		}
+
+		comparable := comparableTable(expected)
+		dropped, added := tableentity.DiffIndexes(actual.Indexes, comparable.Indexes)
+		for _, index := range dropped {
+			drift = append(drift, fmt.Sprintf("table %s: database has index %s that the catalog does not match", expected.Name, index.Name))
+		}
+		for _, index := range added {
+			drift = append(drift, fmt.Sprintf("table %s: database needs index %s to match the catalog", expected.Name, index.Name))
+		}
+		changes, err := tableentity.DiffTables(actual, comparable)
+		if err != nil {
+			drift = append(drift, fmt.Sprintf("table %s: %v", expected.Name, err))
+			continue
+		}
+		for _, change := range changes {
+			drift = append(drift, fmt.Sprintf("table %s: database needs %s to match the catalog", expected.Name, change.String()))
+		}
+	}
+
+	for _, name := range liveNames {
+		if !inCatalog[name] && !systemTables[name] {
+			drift = append(drift, fmt.Sprintf("table %s exists in the database but not in the catalog", name))
+		}
+	}
+	return drift, nil
+}
+
+// comparableTable adjusts a catalog definition to what introspection can report
+func comparableTable(table *tableentity.Table) *tableentity.Table {
+	t := *table
+	t.Columns = make([]tableentity.Column, len(table.Columns))
+	for i, col := range table.Columns {
+		// PostgreSQL の float は double precision の別名
+		if col.Type == tableentity.TypeFLOAT {
+			col.Type = tableentity.TypeDOUBLE
+		}
+		// アセットカラムの実体は assets を参照する uuid
+		if col.Type == tableentity.TypeASSET {
+			col.Type = tableentity.TypeUUID
+			col.Relation = tableentity.AssetRelation()
+		}
+		// 多言語カラムの実体は jsonb
+		if col.Localized {
+			col.Type = tableentity.TypeJSON
+			col.Length = nil
+			col.Localized = false
+		}
+		// 以前のカタログには gen_random_uuid の既定値として保存されたものがある
+		normalizeUUIDDefault(&col)
+		col.RenamedFrom = ""
+		t.Columns[i] = col
+	}
+	// 部分インデックスの条件は読み戻せないため比較しない
+	t.Indexes = make([]tableentity.Index, len(table.Indexes))
+	for i, index := range table.Indexes {
+		if index.Method == "" {
+			index.Method = tableentity.IndexBTree
+		}
+		index.Where = nil
+		t.Indexes[i] = index
+	}
+	return &t
+}
+
+// pendingCatalog answers lookups for tables planned in the same schema file before they exist
+type pendingCatalog struct {
+	Catalog
+	pending map[string]*tableentity.Table
+}
+
+func (c *pendingCatalog) GetTable(ctx context.Context, tableName string) (*tableentity.Table, error) {
+	if table, ok := c.pending[tableName]; ok {
+		return table, nil
+	}
+	return c.Catalog.GetTable(ctx, tableName)
+}
@@ -6,43 +6,606 @@ import (
 	"fmt"
 	"quickflow/internal/domain/tableentity"
 	"quickflow/pkg/errors"
+	"quickflow/pkg/logger"
 
 	"regexp"
+	"strings"
 )
 
 type TableRepository interface {
 	CreateTable(ctx context.Context, table *tableentity.Table) error
 	TableExists(ctx context.Context, tableName string) (bool, error)
+	AlterTable(ctx context.Context, tableName string, changes []tableentity.ColumnChange) error
+	ColumnHasData(ctx context.Context, tableName, columnName string) (bool, error)
+	CreateIndex(ctx context.Context, tableName string, index tableentity.Index, concurrently bool) error
+	DropIndex(ctx context.Context, indexName string, concurrently bool) error
+	DropTable(ctx context.Context, tableName string) error
+	TableHasData(ctx context.Context, tableName string) (bool, error)
+	EstimateRowCount(ctx context.Context, tableName string) (int64, error)
+	ListTables(ctx context.Context) ([]string, error)
+	DescribeTable(ctx context.Context, tableName string) (*tableentity.Table, error)
+	DescribeTables(ctx context.Context, tableNames []string) ([]*tableentity.Table, error)
+
+	// 以下は dry run 用に実行せず SQL 文だけを組み立てる
+	CreateTableStatements(ctx context.Context, table *tableentity.Table) ([]string, error)
+	AlterTableStatements(ctx context.Context, tableName string, changes []tableentity.ColumnChange) ([]string, error)
+	CreateIndexStatement(tableName string, index tableentity.Index, concurrently bool) string
+	DropIndexStatement(indexName string, concurrently bool) string
+	DropTableStatement(tableName string) string
+}
+
+// concurrentIndexThreshold is the estimated row count above which indexes are built concurrently
+const concurrentIndexThreshold = 100000
+
+// systemTables are managed by migrations and are not reported as user tables
+var systemTables = map[string]bool{
+	"users":               true,
+	"loginhistory":        true,
+	"shortenlink":         true,
+	"schema_migrations":   true,
+	"table_schemas":       true,
+	"record_publications": true,
+	"record_transitions":  true,
+	"record_comments":     true,
+	"record_revisions":    true,
+	"assets":              true,
+	"asset_derivatives":   true,
+}
+
+// Catalog keeps the definitions of user-defined tables
+type Catalog interface {
+	Register(ctx context.Context, table *tableentity.Table, ownerID *uint) error
+	GetTable(ctx context.Context, tableName string) (*tableentity.Table, error)
+	ListTables(ctx context.Context) ([]*tableentity.Table, error)
+	UpdateTable(ctx context.Context, table *tableentity.Table) error
+	Unregister(ctx context.Context, tableName string) error
+}
+
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type TableService struct {
-	repo TableRepository
+	repo    TableRepository
+	catalog Catalog
+	tx      Transactor
+}
+
+func NewTableService(repo TableRepository, catalog Catalog, tx Transactor) *TableService {
+	return &TableService{repo: repo, catalog: catalog, tx: tx}
 }
 
-func NewTableService(repo TableRepository) *TableService {
-	return &TableService{repo: repo}
+// CreateTable creates the physical table and registers its definition in the catalog
+func (s *TableService) CreateTable(ctx context.Context, table *tableentity.Table, ownerID *uint) error {
+	normalizeTable(table)
+	if err := validateTable(table); err != nil {
+		return err
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.createWithJoinTables(ctx, table, ownerID)
+	})
+}
+
+func (s *TableService) createWithJoinTables(ctx context.Context, table *tableentity.Table, ownerID *uint) error {
+	joinTables, err := s.validateRelations(ctx, table, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range append([]*tableentity.Table{table}, joinTables...) {
+		if err := s.createTable(ctx, t, ownerID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (s *TableService) CreateTable(ctx context.Context, table *tableentity.Table) error {
+// PlanCreateTable runs the same validation and existence checks as CreateTable and
+// returns the statements it would execute without changing the database
+func (s *TableService) PlanCreateTable(ctx context.Context, table *tableentity.Table) (*Plan, error) {
+	normalizeTable(table)
 	if err := validateTable(table); err != nil {
+		return nil, err
+	}
+
+	joinTables, err := s.validateRelations(ctx, table, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Statements: []string{}, Steps: []string{}}
+	for _, t := range append([]*tableentity.Table{table}, joinTables...) {
+		if err := s.checkTableNotExists(ctx, t.Name); err != nil {
+			return nil, err
+		}
+		statements, err := s.repo.CreateTableStatements(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		plan.addCreateTable(t, statements)
+	}
+	return plan, nil
+}
+
+func (s *TableService) createTable(ctx context.Context, table *tableentity.Table, ownerID *uint) error {
+	if err := s.checkTableNotExists(ctx, table.Name); err != nil {
 		return err
 	}
 
-	exists, err := s.repo.TableExists(ctx, table.Name)
+	if err := s.repo.CreateTable(ctx, table); err != nil {
+		return err
+	}
+
+	return s.catalog.Register(ctx, table, ownerID)
+}
+
+func (s *TableService) checkTableNotExists(ctx context.Context, name string) error {
+	exists, err := s.repo.TableExists(ctx, name)
 	if err != nil {
 		return err
 	}
 	if exists {
 		return errors.NewAppError(
 			errors.ErrorTypeValidation,
-			fmt.Sprintf("Table '%s' already exists", table.Name),
+			fmt.Sprintf("Table '%s' already exists", name),
+			nil,
+		)
+	}
+	return nil
+}
+
+// validateRelations checks that every referenced table and key exists and returns
+// the join tables needed by many-to-many columns. Columns listed in existing already
+// have their join tables and are skipped.
+func (s *TableService) validateRelations(ctx context.Context, table *tableentity.Table, existing map[string]bool) ([]*tableentity.Table, error) {
+	var joinTables []*tableentity.Table
+
+	for _, col := range table.Columns {
+		if col.Relation == nil {
+			continue
+		}
+
+		target := table
+		if col.Relation.Table != table.Name {
+			var err error
+			target, err = s.catalog.GetTable(ctx, col.Relation.Table)
+			if err != nil {
+				if appErr, ok := err.(*errors.AppError); ok && appErr.Type == errors.ErrorTypeNotFound {
+					return nil, errors.NewAppError(
+						errors.ErrorTypeValidation,
+						fmt.Sprintf("Column '%s' references unknown table '%s'", col.Name, col.Relation.Table),
+						nil,
+					)
+				}
+				return nil, err
+			}
+		}
+
+		key, ok := target.Column(col.Relation.Column)
+		if !ok || key.IsVirtual() {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Column '%s' references unknown column '%s.%s'", col.Name, col.Relation.Table, col.Relation.Column),
+				nil,
+			)
+		}
+		if !key.Unique && !(key.PrimaryKey && len(target.PrimaryKey()) == 1) {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Referenced column '%s.%s' must be a single column primary key or unique", col.Relation.Table, col.Relation.Column),
+				nil,
+			)
+		}
+
+		switch col.Relation.Type {
+		case tableentity.RelationOneToMany:
+			if col.Type != key.Type {
+				return nil, errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Column '%s' must have the same type as '%s.%s' (%s)", col.Name, col.Relation.Table, col.Relation.Column, key.Type),
+					nil,
+				)
+			}
+		case tableentity.RelationManyToMany:
+			if !existing[col.Name] {
+				joinTable := tableentity.JoinTable(table, col, key)
+				if err := s.validateJoinTable(ctx, joinTable, col); err != nil {
+					return nil, err
+				}
+				joinTables = append(joinTables, joinTable)
+			}
+		}
+	}
+
+	return joinTables, nil
+}
+
+// validateJoinTable checks the generated names of a join table. PostgreSQL would silently
+// truncate names that are too long, so they are rejected like index and enum names.
+func (s *TableService) validateJoinTable(ctx context.Context, joinTable *tableentity.Table, col tableentity.Column) error {
+	if len(joinTable.Name) > maxIdentifierLength {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Join table name '%s' of column '%s' is longer than %d characters", joinTable.Name, col.Name, maxIdentifierLength),
+			nil,
+		)
+	}
+	for _, joinCol := range joinTable.Columns {
+		if len(joinCol.Name) > maxIdentifierLength {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Join table column name '%s' of column '%s' is longer than %d characters", joinCol.Name, col.Name, maxIdentifierLength),
+				nil,
+			)
+		}
+	}
+	return s.checkTableNotExists(ctx, joinTable.Name)
+}
+
+// ListTables introspects every user table in the database
+func (s *TableService) ListTables(ctx context.Context) ([]*tableentity.Table, error) {
+	names, err := s.repo.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userTables := make([]string, 0, len(names))
+	for _, name := range names {
+		if !systemTables[name] {
+			userTables = append(userTables, name)
+		}
+	}
+	return s.repo.DescribeTables(ctx, userTables)
+}
+
+// DescribeTable introspects a single table from the live database
+func (s *TableService) DescribeTable(ctx context.Context, name string) (*tableentity.Table, error) {
+	name = strings.ToLower(name)
+	if systemTables[name] {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeNotFound,
+			fmt.Sprintf("Table '%s' not found", name),
+			nil,
+		)
+	}
+	return s.repo.DescribeTable(ctx, name)
+}
+
+// AlterTable changes an existing table to match the desired definition in a single transaction.
+// Dropping columns that hold data and narrowing column types are refused unless confirmDestructive is set.
+// Index changes on large tables are applied concurrently after the transaction commits.
+func (s *TableService) AlterTable(ctx context.Context, desired *tableentity.Table, confirmDestructive bool) (*AlterResult, error) {
+	normalizeTable(desired)
+	if err := validateTable(desired); err != nil {
+		return nil, err
+	}
+
+	var alter *alterPlan
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		alter, err = s.alterTable(ctx, desired, confirmDestructive)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if alter.concurrent {
+		if err := s.applyIndexesConcurrently(ctx, desired, alter.result); err != nil {
+			return nil, err
+		}
+	}
+
+	return alter.result, nil
+}
+
+// alterTable applies everything except concurrent index builds; the caller runs it inside a transaction
+func (s *TableService) alterTable(ctx context.Context, desired *tableentity.Table, confirmDestructive bool) (*alterPlan, error) {
+	alter, err := s.prepareAlter(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+	result := alter.result
+	concurrent := alter.concurrent
+
+	if len(alter.destructive) > 0 && !confirmDestructive {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Destructive changes require confirmation: %s", strings.Join(alter.destructive, "; ")),
+			nil,
+		)
+	}
+
+	if !concurrent {
+		for _, index := range result.DroppedIndexes {
+			if err := s.repo.DropIndex(ctx, index.Name, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(result.Changes) > 0 {
+		if err := s.repo.AlterTable(ctx, desired.Name, result.Changes); err != nil {
+			return nil, err
+		}
+	}
+	if !concurrent {
+		for _, index := range result.AddedIndexes {
+			if err := s.repo.CreateIndex(ctx, desired.Name, index, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, name := range alter.droppedJoinTables {
+		if err := s.repo.DropTable(ctx, name); err != nil {
+			return nil, err
+		}
+		if err := s.catalog.Unregister(ctx, name); err != nil {
+			return nil, err
+		}
+	}
+	for _, joinTable := range alter.joinTables {
+		if err := s.createTable(ctx, joinTable, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range desired.Columns {
+		desired.Columns[i].RenamedFrom = ""
+	}
+
+	// 並行して作成・削除するインデックスは、すべて成功してからカタログへ記録する
+	catalogTable := *desired
+	if concurrent {
+		catalogTable.Indexes = alter.currentIndexes
+	}
+	if err := s.catalog.UpdateTable(ctx, &catalogTable); err != nil {
+		return nil, err
+	}
+	return alter, nil
+}
+
+// PlanAlterTable runs the same checks as AlterTable and returns the statements it would execute.
+// Destructive changes are reported in the plan instead of being refused.
+func (s *TableService) PlanAlterTable(ctx context.Context, desired *tableentity.Table) (*Plan, error) {
+	normalizeTable(desired)
+	if err := validateTable(desired); err != nil {
+		return nil, err
+	}
+
+	alter, err := s.prepareAlter(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+	result := alter.result
+
+	plan := &Plan{Statements: []string{}, Steps: []string{}, Destructive: alter.destructive}
+	if !alter.concurrent {
+		for _, index := range result.DroppedIndexes {
+			plan.add("drop index "+index.Name, s.repo.DropIndexStatement(index.Name, false))
+		}
+	}
+	if len(result.Changes) > 0 {
+		statements, err := s.repo.AlterTableStatements(ctx, desired.Name, result.Changes)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range result.Changes {
+			plan.Steps = append(plan.Steps, change.String())
+		}
+		plan.Statements = append(plan.Statements, statements...)
+	}
+	if !alter.concurrent {
+		for _, index := range result.AddedIndexes {
+			plan.add(indexStep(index), s.repo.CreateIndexStatement(desired.Name, index, false))
+		}
+	}
+	for _, name := range alter.droppedJoinTables {
+		plan.add("drop join table "+name, s.repo.DropTableStatement(name))
+	}
+	for _, joinTable := range alter.joinTables {
+		statements, err := s.repo.CreateTableStatements(ctx, joinTable)
+		if err != nil {
+			return nil, err
+		}
+		plan.addCreateTable(joinTable, statements)
+	}
+
+	// CONCURRENTLY はトランザクション外でコミット後に実行される
+	if alter.concurrent {
+		for _, index := range result.DroppedIndexes {
+			plan.add("drop index "+index.Name+" concurrently after commit", s.repo.DropIndexStatement(index.Name, true))
+		}
+		for _, index := range result.AddedIndexes {
+			plan.add(indexStep(index)+" concurrently after commit", s.repo.CreateIndexStatement(desired.Name, index, true))
+		}
+	}
+
+	return plan, nil
+}
+
+// alterPlan is what AlterTable and PlanAlterTable work out before touching the table
+type alterPlan struct {
+	result     *AlterResult
+	joinTables []*tableentity.Table
+	// droppedJoinTables back many-to-many columns that were removed
+	droppedJoinTables []string
+	destructive       []string
+	concurrent        bool
+	// currentIndexes are the catalog indexes before the change
+	currentIndexes []tableentity.Index
+}
+
+func (s *TableService) prepareAlter(ctx context.Context, desired *tableentity.Table) (*alterPlan, error) {
+	current, err := s.catalog.GetTable(ctx, desired.Name)
+	if err != nil {
+		return nil, err
+	}
+	if current.JoinFor != "" {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Table '%s' is the join table of %s and changes with that column", current.Name, current.JoinFor),
 			nil,
 		)
 	}
 
-	return s.repo.CreateTable(ctx, table)
+	result := &AlterResult{}
+	result.Changes, err = tableentity.DiffTables(current, desired)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, err.Error(), nil)
+	}
+	result.DroppedIndexes, result.AddedIndexes = tableentity.DiffIndexes(current.Indexes, desired.Indexes)
+
+	kept := make(map[string]bool)
+	for _, col := range desired.Columns {
+		if col.IsVirtual() {
+			kept[col.Name] = true
+		}
+	}
+	existing := make(map[string]bool)
+	var droppedJoinTables []string
+	for _, col := range current.Columns {
+		if !col.IsVirtual() {
+			continue
+		}
+		existing[col.Name] = true
+		if !kept[col.Name] {
+			droppedJoinTables = append(droppedJoinTables, tableentity.JoinTableName(current, col))
+		}
+	}
+	joinTables, err := s.validateRelations(ctx, desired, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	destructive, err := s.destructiveChanges(ctx, desired.Name, result.Changes)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range droppedJoinTables {
+		hasData, err := s.repo.TableHasData(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if hasData {
+			destructive = append(destructive, "drop join table "+name+" (table contains data)")
+		}
+	}
+
+	rows, err := s.repo.EstimateRowCount(ctx, desired.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &alterPlan{
+		result:            result,
+		joinTables:        joinTables,
+		droppedJoinTables: droppedJoinTables,
+		destructive:       destructive,
+		concurrent:        rows >= concurrentIndexThreshold,
+		currentIndexes:    current.Indexes,
+	}, nil
 }
 
+// applyIndexesConcurrently builds and drops indexes without holding long locks on large tables.
+// CONCURRENTLY cannot run inside a transaction, so the catalog is updated only once every step
+// has succeeded. When a build fails, the indexes built before it are dropped again so that
+// retrying the change starts from the indexes the catalog still lists.
+func (s *TableService) applyIndexesConcurrently(ctx context.Context, desired *tableentity.Table, result *AlterResult) error {
+	for _, index := range result.DroppedIndexes {
+		if err := s.repo.DropIndex(ctx, index.Name, true); err != nil {
+			return err
+		}
+	}
+
+	for i, index := range result.AddedIndexes {
+		if err := s.repo.CreateIndex(ctx, desired.Name, index, true); err != nil {
+			for _, built := range result.AddedIndexes[:i] {
+				if dropErr := s.repo.DropIndex(ctx, built.Name, true); dropErr != nil {
+					logger.Warn("Failed to drop index after a failed concurrent build", "index", built.Name, "error", dropErr)
+				}
+			}
+			return err
+		}
+	}
+
+	return s.catalog.UpdateTable(ctx, desired)
+}
+
+// destructiveChanges describes the changes that may lose existing data
+func (s *TableService) destructiveChanges(ctx context.Context, tableName string, changes []tableentity.ColumnChange) ([]string, error) {
+	var destructive []string
+	for _, change := range changes {
+		switch change.Kind {
+		case tableentity.ChangeDropColumn:
+			hasData, err := s.repo.ColumnHasData(ctx, tableName, change.Column)
+			if err != nil {
+				return nil, err
+			}
+			if hasData {
+				destructive = append(destructive, change.String()+" (column contains data)")
+			}
+		case tableentity.ChangeAlterType:
+			if tableentity.IsNarrowing(*change.From, *change.To) {
+				destructive = append(destructive, change.String()+" (narrowing conversion)")
+			}
+		}
+	}
+	return destructive, nil
+}
+
+// normalizeTable lowercases identifiers the way PostgreSQL folds unquoted names
+func normalizeTable(table *tableentity.Table) {
+	table.Name = strings.ToLower(table.Name)
+	for i := range table.Columns {
+		table.Columns[i].Name = strings.ToLower(table.Columns[i].Name)
+		table.Columns[i].RenamedFrom = strings.ToLower(table.Columns[i].RenamedFrom)
+		if relation := table.Columns[i].Relation; relation != nil {
+			relation.Table = strings.ToLower(relation.Table)
+			relation.Column = strings.ToLower(relation.Column)
+		}
+		normalizeUUIDDefault(&table.Columns[i])
+		if table.Columns[i].Type == tableentity.TypeENUM {
+			if table.Columns[i].EnumName == "" {
+				table.Columns[i].EnumName = table.Name + "_" + table.Columns[i].Name
+			}
+			table.Columns[i].EnumName = strings.ToLower(table.Columns[i].EnumName)
+		}
+	}
+
+	for i := range table.Indexes {
+		index := &table.Indexes[i]
+		for j := range index.Columns {
+			index.Columns[j] = strings.ToLower(index.Columns[j])
+		}
+		for j := range index.Where {
+			index.Where[j].Column = strings.ToLower(index.Where[j].Column)
+		}
+		if index.Name == "" {
+			index.Name = tableentity.DefaultIndexName(table.Name, index.Columns)
+		}
+		index.Name = strings.ToLower(index.Name)
+		if index.Method == "" {
+			index.Method = tableentity.IndexBTree
+		}
+	}
+}
+
+// normalizeUUIDDefault turns a gen_random_uuid default of a uuid column into auto increment,
+// the form tables are described in
+func normalizeUUIDDefault(col *tableentity.Column) {
+	if col.Type == tableentity.TypeUUID && col.Default != nil &&
+		col.Default.Function == tableentity.DefaultGenRandomUUID && col.Default.Value == nil {
+		col.AutoIncrement = true
+		col.Default = nil
+	}
+}
+
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// maxIdentifierLength is PostgreSQL's NAMEDATALEN - 1
+const maxIdentifierLength = 63
+
 func validateTable(table *tableentity.Table) error {
 	// テーブル名のバリデーション
 	if table.Name == "" {
@@ -54,7 +617,7 @@ func validateTable(table *tableentity.Table) error {
 	}
 
 	// テーブル名は英数字とアンダースコアのみ許可
-	if !regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`).MatchString(table.Name) {
+	if !identifierPattern.MatchString(table.Name) {
 		return errors.NewAppError(
 			errors.ErrorTypeValidation,
 			"Table name must start with a letter and contain only letters, numbers, and underscores",
@@ -62,6 +625,14 @@ func validateTable(table *tableentity.Table) error {
 		)
 	}
 
+	if table.JoinFor != "" {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			"Join tables are created along with their many-to-many column",
+			nil,
+		)
+	}
+
 	if len(table.Columns) == 0 {
 		return errors.NewAppError(
 			errors.ErrorTypeValidation,
@@ -83,7 +654,7 @@ func validateTable(table *tableentity.Table) error {
 			)
 		}
 
-		if !regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`).MatchString(col.Name) {
+		if !identifierPattern.MatchString(col.Name) {
 			return errors.NewAppError(
 				errors.ErrorTypeValidation,
 				fmt.Sprintf("Invalid column name: %s", col.Name),
@@ -103,6 +674,18 @@ func validateTable(table *tableentity.Table) error {
 		if col.PrimaryKey {
 			hasPrimaryKey = true
 		}
+
+		if col.Relation != nil {
+			if err := validateRelation(table, col); err != nil {
+				return err
+			}
+		}
+
+		if !col.IsVirtual() {
+			if err := validateColumnType(col); err != nil {
+				return err
+			}
+		}
 	}
 
 	if !hasPrimaryKey {
@@ -113,5 +696,292 @@ func validateTable(table *tableentity.Table) error {
 		)
 	}
 
+	if err := tableentity.ValidateChecks(table); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), nil)
+	}
+	if err := tableentity.ValidateWorkflow(table); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), nil)
+	}
+	if err := tableentity.ValidateLocalized(table); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), nil)
+	}
+
+	return validateIndexes(table)
+}
+
+func validateIndexes(table *tableentity.Table) error {
+	indexNames := make(map[string]bool)
+
+	for _, index := range table.Indexes {
+		if !identifierPattern.MatchString(index.Name) || len(index.Name) > maxIdentifierLength {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Invalid index name: %s", index.Name),
+				nil,
+			)
+		}
+		if indexNames[index.Name] {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Duplicate index name: %s", index.Name),
+				nil,
+			)
+		}
+		indexNames[index.Name] = true
+
+		if len(index.Columns) == 0 {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Index '%s' must have at least one column", index.Name),
+				nil,
+			)
+		}
+		if !index.Method.IsValid() {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Index '%s' has invalid method: %s", index.Name, index.Method),
+				nil,
+			)
+		}
+		if index.Unique && index.Method != tableentity.IndexBTree {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Unique index '%s' must use the btree method", index.Name),
+				nil,
+			)
+		}
+
+		for _, name := range index.Columns {
+			col, ok := table.Column(name)
+			if !ok || col.IsVirtual() {
+				return errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Index '%s' references unknown column: %s", index.Name, name),
+					nil,
+				)
+			}
+			if index.Method == tableentity.IndexGIN && col.Type != tableentity.TypeJSON && col.Type != tableentity.TypeARRAY && !col.Localized {
+				return errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("GIN index '%s' can only include jsonb, array or localized columns", index.Name),
+					nil,
+				)
+			}
+		}
+
+		for _, cond := range index.Where {
+			if col, ok := table.Column(cond.Column); !ok || col.IsVirtual() {
+				return errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Index '%s' condition references unknown column: %s", index.Name, cond.Column),
+					nil,
+				)
+			}
+			switch cond.Operator {
+			case tableentity.ConditionIsNull, tableentity.ConditionIsNotNull:
+			case tableentity.ConditionEqual, tableentity.ConditionNotEqual:
+				switch cond.Value.(type) {
+				case string, float64, bool:
+				default:
+					return errors.NewAppError(
+						errors.ErrorTypeValidation,
+						fmt.Sprintf("Index '%s' condition on %s needs a string, number or boolean value", index.Name, cond.Column),
+						nil,
+					)
+				}
+			default:
+				return errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Index '%s' has invalid condition operator: %s", index.Name, cond.Operator),
+					nil,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// maxNumericPrecision is the largest precision PostgreSQL accepts for numeric
+const maxNumericPrecision = 1000
+
+func validateColumnType(col tableentity.Column) error {
+	if !col.Type.IsValid() {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Column '%s' has unsupported type: %s", col.Name, col.Type),
+			nil,
+		)
+	}
+
+	// 配列は要素の型に対して長さや精度を指定する
+	elementType := col.Type
+	if col.Type == tableentity.TypeARRAY {
+		if !col.ElementType.IsScalar() {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Array column '%s' needs a scalar element_type", col.Name),
+				nil,
+			)
+		}
+		elementType = col.ElementType
+	} else if col.ElementType != "" {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Column '%s' is not an array and cannot have an element_type", col.Name),
+			nil,
+		)
+	}
+
+	if col.Length != nil && (elementType != tableentity.TypeVARCHAR || *col.Length < 1) {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Column '%s' can only have a positive length on varchar", col.Name),
+			nil,
+		)
+	}
+
+	if col.Precision != nil || col.Scale != nil {
+		if elementType != tableentity.TypeNUMERIC {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Column '%s' can only have precision and scale on numeric", col.Name),
+				nil,
+			)
+		}
+		if col.Precision == nil || *col.Precision < 1 || *col.Precision > maxNumericPrecision {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Column '%s' needs a precision between 1 and %d", col.Name, maxNumericPrecision),
+				nil,
+			)
+		}
+		if col.Scale != nil && (*col.Scale < 0 || *col.Scale > *col.Precision) {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Column '%s' needs a scale between 0 and its precision", col.Name),
+				nil,
+			)
+		}
+	}
+
+	if col.Type == tableentity.TypeENUM {
+		if !identifierPattern.MatchString(col.EnumName) || len(col.EnumName) > maxIdentifierLength {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Invalid enum name: %s", col.EnumName),
+				nil,
+			)
+		}
+		if len(col.EnumValues) == 0 {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Enum column '%s' needs at least one value", col.Name),
+				nil,
+			)
+		}
+		seen := make(map[string]bool, len(col.EnumValues))
+		for _, value := range col.EnumValues {
+			if value == "" || seen[value] {
+				return errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Enum column '%s' has an empty or duplicate value", col.Name),
+					nil,
+				)
+			}
+			seen[value] = true
+		}
+	} else if col.EnumName != "" || len(col.EnumValues) > 0 {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Column '%s' is not an enum and cannot have enum values", col.Name),
+			nil,
+		)
+	}
+
+	// アセットカラムの外部キーは固定で assets を参照する
+	if col.Type == tableentity.TypeASSET && (col.Relation != nil || col.Default != nil || col.PrimaryKey) {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Asset column '%s' cannot declare a relation, a default or be a primary key", col.Name),
+			nil,
+		)
+	}
+
+	if col.AutoIncrement {
+		switch col.Type {
+		case tableentity.TypeINT, tableentity.TypeBIGINT, tableentity.TypeUUID:
+		default:
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Column '%s' can only auto increment as integer, bigint or uuid", col.Name),
+				nil,
+			)
+		}
+	}
+
+	// デフォルト値は SQL として埋め込まず、型に合うリテラルか許可された関数のみ受け付ける
+	if err := tableentity.ValidateDefault(col); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), nil)
+	}
+	if err := tableentity.ValidateRules(col); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), nil)
+	}
+
+	return nil
+}
+
+func validateRelation(table *tableentity.Table, col tableentity.Column) error {
+	relation := col.Relation
+
+	if relation.Type != tableentity.RelationOneToMany && relation.Type != tableentity.RelationManyToMany {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Column '%s' has invalid relation type: %s", col.Name, relation.Type),
+			nil,
+		)
+	}
+
+	if !identifierPattern.MatchString(relation.Table) || !identifierPattern.MatchString(relation.Column) {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Column '%s' must reference a valid table and column", col.Name),
+			nil,
+		)
+	}
+
+	if !relation.OnDelete.IsValid() || !relation.OnUpdate.IsValid() {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Column '%s' has an invalid ON DELETE or ON UPDATE action", col.Name),
+			nil,
+		)
+	}
+
+	if relation.OnDelete == tableentity.ActionSetNull && col.NotNull && relation.Type == tableentity.RelationOneToMany {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Column '%s' cannot use ON DELETE SET NULL because it is NOT NULL", col.Name),
+			nil,
+		)
+	}
+
+	if relation.Type == tableentity.RelationManyToMany {
+		if col.PrimaryKey || col.Unique {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Many-to-many column '%s' cannot be a key", col.Name),
+				nil,
+			)
+		}
+		if len(table.PrimaryKey()) != 1 {
+			return errors.NewAppError(
+				errors.ErrorTypeValidation,
+				fmt.Sprintf("Many-to-many column '%s' requires a single column primary key", col.Name),
+				nil,
+			)
+		}
+	}
+
 	return nil
 }
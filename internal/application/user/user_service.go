@@ -3,9 +3,15 @@
 package user
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 
+	"quickflow/internal/domain/asset"
 	"quickflow/internal/domain/user"
+	"quickflow/pkg/jsonpatch"
 )
 
 type UserRepository interface {
@@ -16,12 +22,25 @@ type UserRepository interface {
 	Delete(id uint) error
 }
 
+// AssetFinder looks up uploaded assets, such as the one chosen as a profile image
+type AssetFinder interface {
+	Get(ctx context.Context, id string) (*asset.Asset, error)
+}
+
+// ErrVersionMismatch is returned when an update names a stale version or the user
+// changed while it was being updated
+var ErrVersionMismatch = user.ErrVersionConflict
+
+// ErrNotAnImage is returned when a profile image asset does not hold an image
+var ErrNotAnImage = errors.New("profile image must be an image asset")
+
 type UserService struct {
-	repo UserRepository
+	repo   UserRepository
+	assets AssetFinder
 }
 
-func NewUserService(repo UserRepository) *UserService {
-	return &UserService{repo: repo}
+func NewUserService(repo UserRepository, assets AssetFinder) *UserService {
+	return &UserService{repo: repo, assets: assets}
 }
 
 func (s *UserService) CreateUser(username, firstName, lastName, email, password, phoneNumber string) (*user.User, error) {
@@ -45,12 +64,18 @@ func (s *UserService) GetUserByEmail(email string) (*user.User, error) {
 	return s.repo.GetByEmail(email)
 }
 
-func (s *UserService) UpdateUser(id uint, username, firstName, lastName, email, phoneNumber string) (*user.User, error) {
+// UpdateUser changes the given profile fields. When ifMatch is not empty the user must
+// currently have one of the listed versions.
+func (s *UserService) UpdateUser(id uint, ifMatch []int64, username, firstName, lastName, email, phoneNumber string) (*user.User, error) {
 	existingUser, err := s.repo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(ifMatch) > 0 && !containsVersion(ifMatch, existingUser.Version) {
+		return nil, ErrVersionMismatch
+	}
+
 	if username != "" {
 		if err := user.ValidateUsername(username); err != nil {
 			return nil, err
@@ -93,6 +118,54 @@ func (s *UserService) UpdateUser(id uint, username, firstName, lastName, email,
 	return existingUser, nil
 }
 
+// PatchUser applies a merge patch or JSON patch to the user's profile and validates
+// the result as a whole, so optional fields can be cleared
+func (s *UserService) PatchUser(id uint, ifMatch []int64, patch jsonpatch.Patcher) (*user.User, error) {
+	existingUser, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ifMatch) > 0 && !containsVersion(ifMatch, existingUser.Version) {
+		return nil, ErrVersionMismatch
+	}
+
+	current, err := json.Marshal(existingUser.Profile())
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(current, &doc); err != nil {
+		return nil, err
+	}
+
+	patched, err := patch.Apply(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch: %v", err)
+	}
+	encoded, err := json.Marshal(patched)
+	if err != nil {
+		return nil, err
+	}
+
+	// プロフィール以外のフィールドはパッチで変更させない
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	var profile user.Profile
+	if err := decoder.Decode(&profile); err != nil {
+		return nil, fmt.Errorf("invalid patched user: %v", err)
+	}
+
+	if err := existingUser.ApplyProfile(profile); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(existingUser); err != nil {
+		return nil, err
+	}
+
+	return existingUser, nil
+}
+
 func (s *UserService) UpdatePassword(id uint, oldPassword, newPassword string) error {
 	existingUser, err := s.repo.GetByID(id)
 	if err != nil {
@@ -110,13 +183,22 @@ func (s *UserService) UpdatePassword(id uint, oldPassword, newPassword string) e
 	return s.repo.Update(existingUser)
 }
 
-func (s *UserService) UpdateProfileImage(id uint, imageURL string) error {
+// UpdateProfileImage makes an uploaded image asset the user's profile image
+func (s *UserService) UpdateProfileImage(ctx context.Context, id uint, assetID string) error {
+	image, err := s.assets.Get(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if !image.IsImage() {
+		return ErrNotAnImage
+	}
+
 	existingUser, err := s.repo.GetByID(id)
 	if err != nil {
 		return err
 	}
 
-	existingUser.SetProfileImageURL(imageURL)
+	existingUser.SetProfileImage(image.ID, image.URL)
 
 	return s.repo.Update(existingUser)
 }
@@ -159,3 +241,12 @@ func (s *UserService) UpdateLastLogin(id uint) error {
 func (s *UserService) DeleteUser(id uint) error {
 	return s.repo.Delete(id)
 }
+
+func containsVersion(versions []int64, version int64) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+// File: internal/application/validation/expression.go
+
+package validation
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+	"quickflow/pkg/expr"
+)
+
+// RuleExpression is reported for a failed column or table check
+const RuleExpression = "expression"
+
+// CheckExpressions evaluates the column and table checks against the record as it will be stored.
+// For updates key is set and the payload is applied over the current record.
+func (s *Service) CheckExpressions(ctx context.Context, table *tableentity.Table, payload map[string]interface{}, key record.Key) error {
+	if !hasChecks(table) {
+		return nil
+	}
+
+	current := record.Record{}
+	if key != nil {
+		var err error
+		current, err = s.currentRecord(ctx, table, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	vars := make(map[string]interface{}, len(table.Columns))
+	for _, col := range table.Columns {
+		if col.IsVirtual() {
+			continue
+		}
+		value, ok := payload[col.Name]
+		if !ok {
+			value = current[col.Name]
+		}
+		vars[col.Name] = exprValue(col, value)
+	}
+
+	var violations []errors.Violation
+	for _, col := range table.Columns {
+		if col.Rules == nil || len(col.Rules.Checks) == 0 {
+			continue
+		}
+		colVars := make(map[string]interface{}, len(vars)+1)
+		for name, value := range vars {
+			colVars[name] = value
+		}
+		colVars[tableentity.SelfVariable] = vars[col.Name]
+
+		for _, check := range col.Rules.Checks {
+			if v, failed := s.evalCheck(check, col.Name, colVars); failed {
+				violations = append(violations, v)
+			}
+		}
+	}
+	for _, check := range table.Checks {
+		if v, failed := s.evalCheck(check, check.Name, vars); failed {
+			violations = append(violations, v)
+		}
+	}
+
+	if len(violations) > 0 {
+		return errors.NewValidationError("Record validation failed", violations)
+	}
+	return nil
+}
+
+func (s *Service) evalCheck(check tableentity.Check, field string, vars map[string]interface{}) (errors.Violation, bool) {
+	violation := errors.Violation{Field: field, Rule: RuleExpression, Message: check.Message}
+	if violation.Message == "" {
+		violation.Message = fmt.Sprintf("must satisfy %s", check.Expression)
+	}
+
+	program, err := s.program(check.Expression)
+	if err != nil {
+		violation.Message = fmt.Sprintf("rule %s cannot be compiled: %v", check.Expression, err)
+		return violation, true
+	}
+	ok, err := program.Check(vars)
+	if err != nil {
+		violation.Message = fmt.Sprintf("rule %s cannot be evaluated: %v", check.Expression, err)
+		return violation, true
+	}
+	return violation, !ok
+}
+
+// program compiles a check once; checks are compiled when the table is defined, so errors are rare
+func (s *Service) program(source string) (*expr.Program, error) {
+	if p, ok := s.programs.Load(source); ok {
+		return p.(*expr.Program), nil
+	}
+	p, err := expr.Compile(source)
+	if err != nil {
+		return nil, err
+	}
+	s.programs.Store(source, p)
+	return p, nil
+}
+
+func (s *Service) currentRecord(ctx context.Context, table *tableentity.Table, key record.Key) (record.Record, error) {
+	var filters []record.Filter
+	var sortKeys []record.SortKey
+	for _, col := range table.PrimaryKey() {
+		filters = append(filters, record.Filter{Column: col.Name, Operator: record.FilterEqual, Value: key[col.Name]})
+		sortKeys = append(sortKeys, record.SortKey{Column: col.Name})
+	}
+
+	found, err := s.records.List(ctx, table, record.Query{Filters: filters, Sort: sortKeys, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	// 存在しないレコードは更新時に Not Found となるのでここでは空として扱う
+	if len(found) == 0 {
+		return record.Record{}, nil
+	}
+	return found[0], nil
+}
+
+func hasChecks(table *tableentity.Table) bool {
+	if len(table.Checks) > 0 {
+		return true
+	}
+	for _, col := range table.Columns {
+		if col.Rules != nil && len(col.Rules.Checks) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// exprValue converts a payload value or a driver value into an expression value.
+// Dates become timestamps, numeric strings numbers, and JSON and arrays read from the database are decoded.
+func exprValue(col tableentity.Column, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	switch col.Type {
+	case tableentity.TypeDATE, tableentity.TypeTIMESTAMP, tableentity.TypeTIMESTAMPTZ:
+		if s, ok := value.(string); ok {
+			for _, layout := range dateTimeLayouts {
+				if t, err := time.Parse(layout, s); err == nil {
+					return t
+				}
+			}
+		}
+
+	case tableentity.TypeNUMERIC:
+		if n, ok := numericValue(value); ok {
+			f, _ := n.Float64()
+			return f
+		}
+		if b, ok := value.([]byte); ok {
+			if n, ok := new(big.Rat).SetString(string(b)); ok {
+				f, _ := n.Float64()
+				return f
+			}
+		}
+
+	case tableentity.TypeJSON, tableentity.TypeARRAY:
+		if b, ok := value.([]byte); ok {
+			var decoded interface{}
+			if err := json.Unmarshal(b, &decoded); err == nil {
+				return decoded
+			}
+		}
+
+	case tableentity.TypeBYTEA:
+		if b, ok := value.([]byte); ok {
+			return base64.StdEncoding.EncodeToString(b)
+		}
+	}
+	return value
+}
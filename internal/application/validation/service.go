@@ -1 +1,529 @@
+// File: internal/application/validation/service.go
+
 package validation
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"quickflow/internal/domain/asset"
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/pkg/errors"
+	"quickflow/pkg/locale"
+
+	"github.com/google/uuid"
+)
+
+// Rule names reported in violations
+const (
+	RuleUnknown   = "unknown"
+	RuleReadOnly  = "read_only"
+	RuleRequired  = "required"
+	RuleNotNull   = "not_null"
+	RuleType      = "type"
+	RuleLength    = "length"
+	RuleEnum      = "enum"
+	RuleMin       = "min"
+	RuleMax       = "max"
+	RuleMinLength = "min_length"
+	RuleMaxLength = "max_length"
+	RulePattern   = "pattern"
+	RuleFormat    = "format"
+	RuleUnique    = "unique"
+	RuleLocale    = "locale"
+	RuleAsset     = "asset"
+)
+
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+var timeLayouts = []string{
+	"15:04:05.999999",
+	"15:04:05",
+	"15:04",
+}
+
+// RecordFinder reads existing records for the unique pre-checks
+type RecordFinder interface {
+	List(ctx context.Context, table *tableentity.Table, query record.Query) ([]record.Record, error)
+}
+
+// Service checks record payloads against their table definition before they reach the database
+type Service struct {
+	records RecordFinder
+	// locales are the locales translations of localized columns may be written in
+	locales  locale.Settings
+	patterns sync.Map
+	programs sync.Map
+}
+
+func NewService(records RecordFinder, locales locale.Settings) *Service {
+	return &Service{records: records, locales: locales}
+}
+
+// Check validates a decoded JSON payload, with numbers as json.Number, and reports every
+// violation in a single validation error. When partial is true missing columns are not required.
+func (s *Service) Check(table *tableentity.Table, payload map[string]interface{}, partial bool) error {
+	var violations []errors.Violation
+	add := func(field, rule, message string) {
+		violations = append(violations, errors.Violation{Field: field, Rule: rule, Message: message})
+	}
+
+	known := make(map[string]bool, len(table.Columns))
+	for _, col := range table.Columns {
+		known[col.Name] = true
+	}
+	for name := range payload {
+		if !known[name] {
+			add(name, RuleUnknown, "is not a column of "+table.Name)
+		}
+	}
+
+	for _, col := range table.Columns {
+		raw, ok := payload[col.Name]
+		if col.IsVirtual() {
+			if ok {
+				add(col.Name, RuleReadOnly, "is managed through the join table "+tableentity.JoinTableName(table, col))
+			}
+			continue
+		}
+		if !ok {
+			if !partial && col.NotNull && col.Default == nil && !col.AutoIncrement {
+				add(col.Name, RuleRequired, "is required")
+			}
+			continue
+		}
+		if raw == nil {
+			if col.NotNull {
+				add(col.Name, RuleNotNull, "must not be null")
+			}
+			continue
+		}
+
+		if col.Type == tableentity.TypeARRAY {
+			items, ok := raw.([]interface{})
+			if !ok {
+				add(col.Name, RuleType, "must be an array")
+				continue
+			}
+			element := tableentity.Column{Name: col.Name, Type: col.ElementType, Length: col.Length, Precision: col.Precision, Scale: col.Scale}
+			for i, item := range items {
+				field := fmt.Sprintf("%s[%d]", col.Name, i)
+				if item == nil {
+					continue
+				}
+				if rule, message := checkValue(element, item); rule != "" {
+					add(field, rule, message)
+				}
+			}
+			continue
+		}
+
+		if col.Localized {
+			for _, v := range s.checkTranslations(col, raw) {
+				add(v.Field, v.Rule, v.Message)
+			}
+			continue
+		}
+
+		if rule, message := checkValue(col, raw); rule != "" {
+			add(col.Name, rule, message)
+			continue
+		}
+		for _, v := range s.checkRules(col, raw) {
+			add(col.Name, v.Rule, v.Message)
+		}
+	}
+
+	if len(violations) > 0 {
+		return errors.NewValidationError("Record validation failed", violations)
+	}
+	return nil
+}
+
+// checkTranslations checks each translation of a localized column against the column type and
+// rules. Violations are reported on fields such as title.en, in locale order.
+func (s *Service) checkTranslations(col tableentity.Column, raw interface{}) []errors.Violation {
+	translations, ok := raw.(map[string]interface{})
+	if !ok {
+		return []errors.Violation{{Field: col.Name, Rule: RuleType, Message: "must be an object of translations keyed by locale"}}
+	}
+
+	tags := make([]string, 0, len(translations))
+	for tag := range translations {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	text := col
+	text.Localized = false
+	var violations []errors.Violation
+	for _, tag := range tags {
+		field := col.Name + "." + tag
+		if !s.locales.IsSupported(locale.Normalize(tag)) {
+			violations = append(violations, errors.Violation{Field: field, Rule: RuleLocale, Message: "is not a supported locale"})
+			continue
+		}
+		value := translations[tag]
+		if value == nil {
+			continue
+		}
+		if rule, message := checkValue(text, value); rule != "" {
+			violations = append(violations, errors.Violation{Field: field, Rule: rule, Message: message})
+			continue
+		}
+		for _, v := range s.checkRules(text, value) {
+			v.Field = field
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+// CheckUnique looks for other records that already hold the unique values of a bound record.
+// key identifies the record being updated, which may keep its own values; it is nil for inserts.
+// The database still enforces the constraints; this only reports conflicts in the same shape as other violations.
+func (s *Service) CheckUnique(ctx context.Context, table *tableentity.Table, values record.Record, key record.Key) error {
+	var pk []string
+	var sortKeys []record.SortKey
+	for _, col := range table.PrimaryKey() {
+		pk = append(pk, col.Name)
+		sortKeys = append(sortKeys, record.SortKey{Column: col.Name})
+	}
+
+	var candidates [][]string
+	if key == nil {
+		candidates = append(candidates, pk)
+	}
+	for _, col := range table.Columns {
+		if col.Unique && !col.PrimaryKey {
+			candidates = append(candidates, []string{col.Name})
+		}
+	}
+	for _, idx := range table.Indexes {
+		if idx.Unique && len(idx.Where) == 0 {
+			candidates = append(candidates, idx.Columns)
+		}
+	}
+
+	var violations []errors.Violation
+	for _, columns := range candidates {
+		filters, ok := uniqueFilters(table, columns, values)
+		if !ok {
+			continue
+		}
+		found, err := s.records.List(ctx, table, record.Query{Filters: filters, Sort: sortKeys, Limit: 2, Fields: pk})
+		if err != nil {
+			return err
+		}
+		for _, rec := range found {
+			if key != nil && sameKey(rec, key) {
+				continue
+			}
+			violations = append(violations, errors.Violation{
+				Field:   strings.Join(columns, ","),
+				Rule:    RuleUnique,
+				Message: "already exists",
+			})
+			break
+		}
+	}
+
+	if len(violations) > 0 {
+		return errors.NewValidationError("Record validation failed", violations)
+	}
+	return nil
+}
+
+// assetTable is the part of the assets table asset columns are checked against
+var assetTable = &tableentity.Table{
+	Name:    asset.Table,
+	Columns: []tableentity.Column{{Name: "id", Type: tableentity.TypeUUID, PrimaryKey: true}},
+}
+
+// CheckAssets reports asset columns of a bound record that refer to assets which do not exist.
+// The foreign key still enforces it; this only reports it in the same shape as other violations.
+func (s *Service) CheckAssets(ctx context.Context, table *tableentity.Table, values record.Record) error {
+	var violations []errors.Violation
+	for _, col := range table.AssetColumns() {
+		id := values[col.Name]
+		if id == nil {
+			continue
+		}
+		found, err := s.records.List(ctx, assetTable, record.Query{
+			Filters: []record.Filter{{Column: "id", Operator: record.FilterEqual, Value: id}},
+			Sort:    []record.SortKey{{Column: "id"}},
+			Limit:   1,
+			Fields:  []string{"id"},
+		})
+		if err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			violations = append(violations, errors.Violation{Field: col.Name, Rule: RuleAsset, Message: "refers to an unknown asset"})
+		}
+	}
+
+	if len(violations) > 0 {
+		return errors.NewValidationError("Record validation failed", violations)
+	}
+	return nil
+}
+
+// uniqueFilters matches records holding the same values in every column.
+// Constraints are skipped when a value is missing or NULL, since NULLs never conflict.
+func uniqueFilters(table *tableentity.Table, columns []string, values record.Record) ([]record.Filter, bool) {
+	if len(columns) == 0 {
+		return nil, false
+	}
+	filters := make([]record.Filter, 0, len(columns))
+	for _, name := range columns {
+		col, ok := table.Column(name)
+		if !ok || col.Type == tableentity.TypeJSON || col.Type == tableentity.TypeARRAY || col.Localized {
+			return nil, false
+		}
+		value := values[name]
+		if value == nil {
+			return nil, false
+		}
+		filters = append(filters, record.Filter{Column: name, Operator: record.FilterEqual, Value: value})
+	}
+	return filters, true
+}
+
+func sameKey(rec record.Record, key record.Key) bool {
+	for name, value := range key {
+		if fmt.Sprint(rec[name]) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkValue checks the JSON value against the column type, varchar length and enum values.
+// It returns the failed rule and a message, or empty strings when the value fits.
+func checkValue(col tableentity.Column, raw interface{}) (string, string) {
+	switch col.Type {
+	case tableentity.TypeVARCHAR, tableentity.TypeTEXT:
+		s, ok := raw.(string)
+		if !ok {
+			return RuleType, "must be a string"
+		}
+		if col.Type == tableentity.TypeVARCHAR && col.Length != nil && len([]rune(s)) > *col.Length {
+			return RuleLength, fmt.Sprintf("must be at most %d characters", *col.Length)
+		}
+
+	case tableentity.TypeINT, tableentity.TypeBIGINT:
+		n, ok := raw.(json.Number)
+		if !ok {
+			return RuleType, "must be an integer"
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return RuleType, "must be an integer"
+		}
+		if col.Type == tableentity.TypeINT && (i < math.MinInt32 || i > math.MaxInt32) {
+			return RuleType, "is out of range for integer"
+		}
+
+	case tableentity.TypeFLOAT, tableentity.TypeDOUBLE:
+		n, ok := raw.(json.Number)
+		if !ok {
+			return RuleType, "must be a number"
+		}
+		if _, err := n.Float64(); err != nil {
+			return RuleType, "must be a number"
+		}
+
+	case tableentity.TypeNUMERIC:
+		if _, ok := numericValue(raw); !ok {
+			return RuleType, "must be a number or numeric string"
+		}
+
+	case tableentity.TypeBOOLEAN:
+		if _, ok := raw.(bool); !ok {
+			return RuleType, "must be a boolean"
+		}
+
+	case tableentity.TypeDATE, tableentity.TypeTIMESTAMP, tableentity.TypeTIMESTAMPTZ:
+		s, ok := raw.(string)
+		if !ok || !parses(dateTimeLayouts, s) {
+			return RuleType, "must be an ISO 8601 date or timestamp"
+		}
+
+	case tableentity.TypeTIME:
+		s, ok := raw.(string)
+		if !ok || !parses(timeLayouts, s) {
+			return RuleType, "must be a time of day in HH:MM[:SS] format"
+		}
+
+	case tableentity.TypeUUID, tableentity.TypeASSET:
+		s, ok := raw.(string)
+		if _, err := uuid.Parse(s); !ok || err != nil {
+			return RuleType, "must be a valid UUID"
+		}
+
+	case tableentity.TypeBYTEA:
+		s, ok := raw.(string)
+		if _, err := base64.StdEncoding.DecodeString(s); !ok || err != nil {
+			return RuleType, "must be valid base64"
+		}
+
+	case tableentity.TypeINET:
+		s, ok := raw.(string)
+		if !ok {
+			return RuleType, "must be a valid IP address"
+		}
+		if net.ParseIP(s) == nil {
+			if _, _, err := net.ParseCIDR(s); err != nil {
+				return RuleType, "must be a valid IP address"
+			}
+		}
+
+	case tableentity.TypeCIDR:
+		s, ok := raw.(string)
+		if !ok {
+			return RuleType, "must be a network address in CIDR notation"
+		}
+		ip, network, err := net.ParseCIDR(s)
+		if err != nil || !ip.Equal(network.IP) {
+			return RuleType, "must be a network address in CIDR notation"
+		}
+
+	case tableentity.TypeENUM:
+		s, ok := raw.(string)
+		if !ok {
+			return RuleType, "must be a string"
+		}
+		for _, value := range col.EnumValues {
+			if value == s {
+				return "", ""
+			}
+		}
+		return RuleEnum, fmt.Sprintf("must be one of %s", strings.Join(col.EnumValues, ", "))
+
+	case tableentity.TypeJSON:
+		if _, err := json.Marshal(raw); err != nil {
+			return RuleType, "must be valid JSON"
+		}
+	}
+	return "", ""
+}
+
+// checkRules applies the declarative rules of a column to a value that already has the right type
+func (s *Service) checkRules(col tableentity.Column, raw interface{}) []errors.Violation {
+	rules := col.Rules
+	if rules == nil {
+		return nil
+	}
+
+	var violations []errors.Violation
+	add := func(rule, message string) {
+		violations = append(violations, errors.Violation{Field: col.Name, Rule: rule, Message: message})
+	}
+
+	if col.Type.IsNumeric() {
+		n, ok := numericValue(raw)
+		if !ok {
+			return nil
+		}
+		if rules.Min != nil && n.Cmp(new(big.Rat).SetFloat64(*rules.Min)) < 0 {
+			add(RuleMin, fmt.Sprintf("must be at least %v", *rules.Min))
+		}
+		if rules.Max != nil && n.Cmp(new(big.Rat).SetFloat64(*rules.Max)) > 0 {
+			add(RuleMax, fmt.Sprintf("must be at most %v", *rules.Max))
+		}
+		return violations
+	}
+
+	str, ok := raw.(string)
+	if !ok {
+		return nil
+	}
+	length := len([]rune(str))
+	if rules.MinLength != nil && length < *rules.MinLength {
+		add(RuleMinLength, fmt.Sprintf("must be at least %d characters", *rules.MinLength))
+	}
+	if rules.MaxLength != nil && length > *rules.MaxLength {
+		add(RuleMaxLength, fmt.Sprintf("must be at most %d characters", *rules.MaxLength))
+	}
+	if rules.Pattern != "" {
+		if re := s.pattern(rules.Pattern); re != nil && !re.MatchString(str) {
+			add(RulePattern, fmt.Sprintf("must match %s", rules.Pattern))
+		}
+	}
+	switch rules.Format {
+	case tableentity.FormatEmail:
+		if !isEmail(str) {
+			add(RuleFormat, "must be an email address")
+		}
+	case tableentity.FormatURL:
+		if !isURL(str) {
+			add(RuleFormat, "must be an absolute http or https URL")
+		}
+	}
+	return violations
+}
+
+// pattern compiles a rule pattern once; patterns are checked when the table is defined
+func (s *Service) pattern(expr string) *regexp.Regexp {
+	if re, ok := s.patterns.Load(expr); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil
+	}
+	s.patterns.Store(expr, re)
+	return re
+}
+
+func numericValue(raw interface{}) (*big.Rat, bool) {
+	var s string
+	switch v := raw.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
+
+func parses(layouts []string, s string) bool {
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmail accepts a bare address without a display name
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
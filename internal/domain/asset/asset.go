@@ -0,0 +1,46 @@
+// File: internal/domain/asset/asset.go
+
+package asset
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Asset is the metadata of an uploaded file. The content lives in the storage backend under StorageKey
+// and never changes; a new upload always creates a new asset.
+type Asset struct {
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Filename string    `gorm:"not null" json:"filename"`
+	MimeType string    `gorm:"not null" json:"mime_type"`
+	Size     int64     `gorm:"not null" json:"size"`
+	// Checksum is the hex encoded SHA-256 of the content
+	Checksum string `gorm:"not null" json:"checksum"`
+	// Width and Height are set for images whose format can be decoded
+	Width      *int      `json:"width,omitempty"`
+	Height     *int      `json:"height,omitempty"`
+	StorageKey string    `gorm:"not null;unique" json:"-"`
+	UploadedBy *uint     `json:"uploaded_by,omitempty"`
+	CreatedAt  time.Time `gorm:"not null" json:"created_at"`
+	// URL serves the content through the API
+	URL string `gorm:"-" json:"url"`
+}
+
+// Table is the table assets are kept in; asset columns of user-defined tables reference it
+const Table = "assets"
+
+func (Asset) TableName() string {
+	return Table
+}
+
+// IsImage reports whether the asset holds an image
+func (a *Asset) IsImage() bool {
+	return strings.HasPrefix(a.MimeType, "image/")
+}
+
+// ContentURL is the API path serving the content of an asset
+func ContentURL(id uuid.UUID) string {
+	return "/assets/" + id.String() + "/content"
+}
@@ -0,0 +1,27 @@
+// File: internal/domain/asset/derivative.go
+
+package asset
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Derivative is a cached transformation of an image asset. Variant names the normalized
+// transform parameters, so equal requests share one derivative.
+type Derivative struct {
+	AssetID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"asset_id"`
+	Variant    string    `gorm:"primaryKey" json:"variant"`
+	MimeType   string    `gorm:"not null" json:"mime_type"`
+	Size       int64     `gorm:"not null" json:"size"`
+	Checksum   string    `gorm:"not null" json:"checksum"`
+	Width      int       `gorm:"not null" json:"width"`
+	Height     int       `gorm:"not null" json:"height"`
+	StorageKey string    `gorm:"not null;unique" json:"-"`
+	CreatedAt  time.Time `gorm:"not null" json:"created_at"`
+}
+
+func (Derivative) TableName() string {
+	return "asset_derivatives"
+}
@@ -1 +1,16 @@
+// File: internal/domain/record/entity.go
+
 package record
+
+// Record is a single row of a user-defined table, keyed by column name
+type Record map[string]interface{}
+
+// Key holds the primary key values that identify a single record
+type Key map[string]interface{}
+
+// Related is a record reached through a many-to-many join table,
+// together with the key of the record that links to it
+type Related struct {
+	Source interface{}
+	Record Record
+}
@@ -0,0 +1,51 @@
+// File: internal/domain/record/filter.go
+
+package record
+
+type FilterOperator string
+
+const (
+	FilterEqual        FilterOperator = "eq"
+	FilterNotEqual     FilterOperator = "neq"
+	FilterGreater      FilterOperator = "gt"
+	FilterGreaterEqual FilterOperator = "gte"
+	FilterLess         FilterOperator = "lt"
+	FilterLessEqual    FilterOperator = "lte"
+	FilterIn           FilterOperator = "in"
+	FilterContains     FilterOperator = "contains"
+	FilterIsNull       FilterOperator = "is_null"
+	// FilterMissing matches localized values without a translation for the locale in Value
+	FilterMissing FilterOperator = "missing"
+	// FilterAny matches when one of the filters in Value matches
+	FilterAny FilterOperator = "any"
+)
+
+// Filter is a predicate on a single column whose value is already bound to the column type.
+// Path selects a nested key of a jsonb column; the nested value is compared as text.
+type Filter struct {
+	Column   string
+	Path     []string
+	Operator FilterOperator
+	// Value is a single value, a []interface{} for in and array contains, a bool for is_null
+	// or a []Filter for any
+	Value interface{}
+}
+
+// SortKey orders records by a column. NULLs of nullable columns come last in either direction.
+type SortKey struct {
+	Column     string
+	Descending bool
+	Nullable   bool
+}
+
+// Query selects a page of records using keyset pagination
+type Query struct {
+	Filters []Filter
+	// Sort always ends with the primary key so that the order is total
+	Sort []SortKey
+	// After holds the Sort values of the last record of the previous page
+	After []interface{}
+	Limit int
+	// Fields restricts the selected columns; nil selects every column
+	Fields []string
+}
@@ -0,0 +1,28 @@
+// File: internal/domain/record/review.go
+
+package record
+
+import "time"
+
+// Transition is one entry of the log of workflow changes of a record
+type Transition struct {
+	ID         uint64    `json:"id"`
+	Action     string    `json:"action"`
+	FromStage  string    `json:"from_stage,omitempty"`
+	ToStage    string    `json:"to_stage,omitempty"`
+	FromStatus Status    `json:"from_status"`
+	ToStatus   Status    `json:"to_status"`
+	UserID     *uint     `json:"user_id,omitempty"`
+	Comment    string    `json:"comment,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Comment is a review comment on a record. ChangesRequested comments sent the record
+// back to the first stage of the workflow.
+type Comment struct {
+	ID               uint64    `json:"id"`
+	UserID           *uint     `json:"user_id,omitempty"`
+	Body             string    `json:"body"`
+	ChangesRequested bool      `json:"changes_requested"`
+	CreatedAt        time.Time `json:"created_at"`
+}
@@ -0,0 +1,80 @@
+// File: internal/domain/record/revision.go
+
+package record
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Operation is the write that produced a revision
+type Operation string
+
+const (
+	OperationCreate  Operation = "create"
+	OperationUpdate  Operation = "update"
+	OperationUpsert  Operation = "upsert"
+	OperationDelete  Operation = "delete"
+	OperationRestore Operation = "restore"
+)
+
+// Revision is an immutable snapshot of a record taken after each write.
+// Delete revisions hold the record as it was before it was deleted.
+type Revision struct {
+	Revision  int       `json:"revision"`
+	Operation Operation `json:"operation"`
+	UserID    *uint     `json:"user_id,omitempty"`
+	// RestoredFrom is the revision a restore brought back
+	RestoredFrom *int      `json:"restored_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	Data         Record    `json:"data,omitempty"`
+}
+
+// ChangeType tells how a field differs between two revisions
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// Change is one differing field between two revisions
+type Change struct {
+	Field string      `json:"field"`
+	Type  ChangeType  `json:"type"`
+	From  interface{} `json:"from,omitempty"`
+	To    interface{} `json:"to,omitempty"`
+}
+
+// Diff compares two record snapshots field by field, in field name order
+func Diff(from, to Record) []Change {
+	names := make(map[string]bool, len(from)+len(to))
+	for name := range from {
+		names[name] = true
+	}
+	for name := range to {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	changes := []Change{}
+	for _, name := range sorted {
+		before, inFrom := from[name]
+		after, inTo := to[name]
+		switch {
+		case !inFrom:
+			changes = append(changes, Change{Field: name, Type: ChangeAdded, To: after})
+		case !inTo:
+			changes = append(changes, Change{Field: name, Type: ChangeRemoved, From: before})
+		case !reflect.DeepEqual(before, after):
+			changes = append(changes, Change{Field: name, Type: ChangeChanged, From: before, To: after})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,48 @@
+// File: internal/domain/record/workflow.go
+
+package record
+
+import "time"
+
+// Status is the lifecycle state of a record in a workflow-enabled table
+type Status string
+
+const (
+	// StatusDraft records are only visible to editors
+	StatusDraft Status = "draft"
+	// StatusPublished records are served to anonymous readers from their published snapshot
+	StatusPublished Status = "published"
+	// StatusArchived records are withdrawn; their last snapshot is kept but not served
+	StatusArchived Status = "archived"
+)
+
+// State is the workflow state of a record. Records without a stored state are drafts.
+type State struct {
+	Status Status `json:"status"`
+	// Stage is the editorial stage of the draft in tables with review stages
+	Stage       string     `json:"stage,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	UpdatedBy   *uint      `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	Schedule
+	// ScheduleAttempts and ScheduleError describe the failed runs of a due schedule
+	ScheduleAttempts int    `json:"schedule_attempts,omitempty"`
+	ScheduleError    string `json:"schedule_error,omitempty"`
+}
+
+// Schedule queues a record to be published or withdrawn at a given time.
+// A nil time leaves the corresponding transition unscheduled.
+type Schedule struct {
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt *time.Time `json:"unpublish_at,omitempty"`
+}
+
+// DueSchedule is a stored schedule of which at least one time has passed
+type DueSchedule struct {
+	TableName   string
+	RecordKey   string
+	ScheduledBy *uint
+	Schedule
+	// Attempts counts the earlier runs of the schedule that failed
+	Attempts int
+}
@@ -1 +1,49 @@
+// File: internal/domain/schema/entity.go
+
 package schema
+
+import (
+	"time"
+
+	"quickflow/internal/domain/tableentity"
+)
+
+// Schema is the catalog entry that keeps the definition of a user-defined table
+type Schema struct {
+	ID          uint              `json:"id"`
+	TableName   string            `json:"table_name"`
+	DisplayName string            `json:"display_name"`
+	Description string            `json:"description"`
+	Definition  tableentity.Table `json:"definition"`
+	OwnerID     *uint             `json:"owner_id,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+func NewSchema(table *tableentity.Table, ownerID *uint) *Schema {
+	now := time.Now()
+	return &Schema{
+		TableName:   table.Name,
+		DisplayName: table.DisplayName,
+		Description: table.Description,
+		Definition:  *table,
+		OwnerID:     ownerID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Table returns a copy of the stored table definition
+func (s *Schema) Table() *tableentity.Table {
+	table := s.Definition
+	table.Columns = append([]tableentity.Column(nil), s.Definition.Columns...)
+	return &table
+}
+
+// UpdateDefinition replaces the stored definition after the physical table has changed
+func (s *Schema) UpdateDefinition(table *tableentity.Table) {
+	s.Definition = *table
+	s.DisplayName = table.DisplayName
+	s.Description = table.Description
+	s.UpdatedAt = time.Now()
+}
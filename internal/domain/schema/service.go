@@ -1 +1,35 @@
+// File: internal/domain/schema/service.go
+
 package schema
+
+import "errors"
+
+// SetDisplayName updates the human readable name of the table
+func (s *Schema) SetDisplayName(displayName string) {
+	s.DisplayName = displayName
+	s.Definition.DisplayName = displayName
+}
+
+// SetDescription updates the table description
+func (s *Schema) SetDescription(description string) {
+	s.Description = description
+	s.Definition.Description = description
+}
+
+// SetColumnMetadata updates the display name and description of a column
+func (s *Schema) SetColumnMetadata(columnName string, displayName, description *string) error {
+	for i := range s.Definition.Columns {
+		col := &s.Definition.Columns[i]
+		if col.Name != columnName {
+			continue
+		}
+		if displayName != nil {
+			col.DisplayName = *displayName
+		}
+		if description != nil {
+			col.Description = *description
+		}
+		return nil
+	}
+	return errors.New("unknown column: " + columnName)
+}
@@ -0,0 +1,22 @@
+// File: internal/domain/tableentity/asset.go
+
+package tableentity
+
+import "quickflow/internal/domain/asset"
+
+// AssetRelation is the foreign key an asset column is stored with. Assets cannot be deleted
+// while a record still references them.
+func AssetRelation() *Relation {
+	return &Relation{Type: RelationOneToMany, Table: asset.Table, Column: "id"}
+}
+
+// AssetColumns returns the asset columns of the table in definition order
+func (t *Table) AssetColumns() []Column {
+	var columns []Column
+	for _, col := range t.Columns {
+		if col.Type == TypeASSET {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,221 @@
+// File: internal/domain/tableentity/default.go
+
+package tableentity
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DefaultFunction string
+
+const (
+	DefaultNow              DefaultFunction = "now"
+	DefaultCurrentTimestamp DefaultFunction = "current_timestamp"
+	DefaultCurrentDate      DefaultFunction = "current_date"
+	DefaultGenRandomUUID    DefaultFunction = "gen_random_uuid"
+)
+
+// defaultFunctionTypes lists the column types each default function can be used with
+var defaultFunctionTypes = map[DefaultFunction][]ColumnType{
+	DefaultNow:              {TypeTIMESTAMP, TypeTIMESTAMPTZ, TypeDATE},
+	DefaultCurrentTimestamp: {TypeTIMESTAMP, TypeTIMESTAMPTZ, TypeDATE},
+	DefaultCurrentDate:      {TypeDATE, TypeTIMESTAMP, TypeTIMESTAMPTZ},
+	DefaultGenRandomUUID:    {TypeUUID},
+}
+
+// DefaultValue is a column default. Exactly one of Value and Function is set.
+// Value holds a JSON literal checked against the column type; Function names
+// one of the whitelisted SQL functions.
+type DefaultValue struct {
+	Value    interface{}     `json:"value,omitempty"`
+	Function DefaultFunction `json:"function,omitempty"`
+}
+
+func (f DefaultFunction) IsValid() bool {
+	_, ok := defaultFunctionTypes[f]
+	return ok
+}
+
+// AppliesTo reports whether the function returns a value assignable to the column type
+func (f DefaultFunction) AppliesTo(t ColumnType) bool {
+	for _, allowed := range defaultFunctionTypes[f] {
+		if allowed == t {
+			return true
+		}
+	}
+	return false
+}
+
+var defaultDateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+var defaultTimeLayouts = []string{
+	"15:04:05.999999",
+	"15:04:05",
+	"15:04",
+}
+
+// ValidateDefault checks the default of a column against its type
+func ValidateDefault(col Column) error {
+	def := col.Default
+	if def == nil {
+		return nil
+	}
+
+	if def.Function != "" {
+		if def.Value != nil {
+			return fmt.Errorf("default of %s must set either a value or a function, not both", col.Name)
+		}
+		if !def.Function.IsValid() {
+			return fmt.Errorf("default of %s uses unsupported function %s", col.Name, def.Function)
+		}
+		if !def.Function.AppliesTo(col.Type) {
+			return fmt.Errorf("default function %s cannot be used for %s column %s", def.Function, col.Type, col.Name)
+		}
+		return nil
+	}
+
+	if def.Value == nil {
+		return fmt.Errorf("default of %s must set a value or a function", col.Name)
+	}
+
+	if col.Type == TypeARRAY {
+		items, ok := def.Value.([]interface{})
+		if !ok {
+			return fmt.Errorf("default of %s must be an array", col.Name)
+		}
+		element := Column{Name: col.Name, Type: col.ElementType, Length: col.Length, Precision: col.Precision, Scale: col.Scale}
+		for _, item := range items {
+			if item == nil {
+				continue
+			}
+			if err := checkLiteral(element, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return checkLiteral(col, def.Value)
+}
+
+// SameDefault reports whether two defaults are equal
+func SameDefault(a, b *DefaultValue) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return reflect.DeepEqual(*a, *b)
+}
+
+func checkLiteral(col Column, value interface{}) error {
+	invalid := func(expected string) error {
+		return fmt.Errorf("default of %s must be %s", col.Name, expected)
+	}
+
+	switch col.Type {
+	case TypeJSON:
+		return nil
+
+	case TypeBOOLEAN:
+		if _, ok := value.(bool); !ok {
+			return invalid("a boolean")
+		}
+		return nil
+
+	case TypeINT, TypeBIGINT:
+		f, ok := value.(float64)
+		if !ok || f != math.Trunc(f) {
+			return invalid("an integer")
+		}
+		if col.Type == TypeINT && (f < math.MinInt32 || f > math.MaxInt32) {
+			return invalid("within the integer range")
+		}
+		return nil
+
+	case TypeFLOAT, TypeDOUBLE:
+		if _, ok := value.(float64); !ok {
+			return invalid("a number")
+		}
+		return nil
+
+	case TypeNUMERIC:
+		switch v := value.(type) {
+		case float64:
+			return nil
+		case string:
+			if _, ok := new(big.Rat).SetString(v); !ok {
+				return invalid("a decimal number")
+			}
+			return nil
+		}
+		return invalid("a number or numeric string")
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return invalid("a string")
+	}
+
+	switch col.Type {
+	case TypeVARCHAR:
+		if col.Length != nil && len([]rune(s)) > *col.Length {
+			return invalid(fmt.Sprintf("at most %d characters", *col.Length))
+		}
+	case TypeDATE, TypeTIMESTAMP, TypeTIMESTAMPTZ:
+		if !matchesLayout(s, defaultDateTimeLayouts) {
+			return invalid("an ISO 8601 date or timestamp")
+		}
+	case TypeTIME:
+		if !matchesLayout(s, defaultTimeLayouts) {
+			return invalid("a time of day in HH:MM[:SS] format")
+		}
+	case TypeUUID:
+		if _, err := uuid.Parse(s); err != nil {
+			return invalid("a valid UUID")
+		}
+	case TypeBYTEA:
+		if _, err := base64.StdEncoding.DecodeString(s); err != nil {
+			return invalid("a base64 string")
+		}
+	case TypeINET:
+		if net.ParseIP(s) == nil {
+			if _, _, err := net.ParseCIDR(s); err != nil {
+				return invalid("a valid IP address")
+			}
+		}
+	case TypeCIDR:
+		ip, network, err := net.ParseCIDR(s)
+		if err != nil || !ip.Equal(network.IP) {
+			return invalid("a network address in CIDR notation")
+		}
+	case TypeENUM:
+		for _, v := range col.EnumValues {
+			if v == s {
+				return nil
+			}
+		}
+		return invalid("one of the enum values")
+	}
+	return nil
+}
+
+func matchesLayout(s string, layouts []string) bool {
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
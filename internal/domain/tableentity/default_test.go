@@ -0,0 +1,63 @@
+// File: internal/domain/tableentity/default_test.go
+
+package tableentity
+
+import "testing"
+
+func TestValidateDefault(t *testing.T) {
+	length := 3
+	status := Column{Name: "status", Type: TypeENUM, EnumValues: []string{"draft", "published"}}
+	tests := []struct {
+		name    string
+		col     Column
+		def     *DefaultValue
+		wantErr bool
+	}{
+		{name: "no default", col: Column{Name: "c", Type: TypeTEXT}},
+		{name: "function", col: Column{Name: "c", Type: TypeTIMESTAMPTZ}, def: &DefaultValue{Function: DefaultNow}},
+		{name: "function for another type", col: Column{Name: "c", Type: TypeTEXT}, def: &DefaultValue{Function: DefaultNow}, wantErr: true},
+		// 関数名は許可されたものだけで、SQL としては埋め込まれない
+		{name: "unknown function", col: Column{Name: "c", Type: TypeTEXT}, def: &DefaultValue{Function: "pg_sleep(10)"}, wantErr: true},
+		{name: "value and function", col: Column{Name: "c", Type: TypeDATE}, def: &DefaultValue{Value: "2026-10-18", Function: DefaultCurrentDate}, wantErr: true},
+		{name: "neither value nor function", col: Column{Name: "c", Type: TypeTEXT}, def: &DefaultValue{}, wantErr: true},
+		{name: "text", col: Column{Name: "c", Type: TypeTEXT}, def: &DefaultValue{Value: "'); DROP TABLE users; --"}},
+		{name: "text from a number", col: Column{Name: "c", Type: TypeTEXT}, def: &DefaultValue{Value: 1.0}, wantErr: true},
+		{name: "varchar within length", col: Column{Name: "c", Type: TypeVARCHAR, Length: &length}, def: &DefaultValue{Value: "日本語"}},
+		{name: "varchar too long", col: Column{Name: "c", Type: TypeVARCHAR, Length: &length}, def: &DefaultValue{Value: "abcd"}, wantErr: true},
+		{name: "integer", col: Column{Name: "c", Type: TypeINT}, def: &DefaultValue{Value: 42.0}},
+		{name: "integer with a fraction", col: Column{Name: "c", Type: TypeINT}, def: &DefaultValue{Value: 1.5}, wantErr: true},
+		{name: "integer out of range", col: Column{Name: "c", Type: TypeINT}, def: &DefaultValue{Value: 3e9}, wantErr: true},
+		{name: "bigint beyond integer range", col: Column{Name: "c", Type: TypeBIGINT}, def: &DefaultValue{Value: 3e9}},
+		{name: "boolean", col: Column{Name: "c", Type: TypeBOOLEAN}, def: &DefaultValue{Value: false}},
+		{name: "boolean from a string", col: Column{Name: "c", Type: TypeBOOLEAN}, def: &DefaultValue{Value: "true"}, wantErr: true},
+		{name: "numeric string", col: Column{Name: "c", Type: TypeNUMERIC}, def: &DefaultValue{Value: "12.50"}},
+		{name: "numeric expression", col: Column{Name: "c", Type: TypeNUMERIC}, def: &DefaultValue{Value: "1; SELECT 1"}, wantErr: true},
+		{name: "date", col: Column{Name: "c", Type: TypeDATE}, def: &DefaultValue{Value: "2026-10-18"}},
+		{name: "timestamp", col: Column{Name: "c", Type: TypeTIMESTAMPTZ}, def: &DefaultValue{Value: "2026-10-18T09:00:00Z"}},
+		{name: "invalid date", col: Column{Name: "c", Type: TypeDATE}, def: &DefaultValue{Value: "yesterday"}, wantErr: true},
+		{name: "time", col: Column{Name: "c", Type: TypeTIME}, def: &DefaultValue{Value: "09:30"}},
+		{name: "invalid time", col: Column{Name: "c", Type: TypeTIME}, def: &DefaultValue{Value: "25:00"}, wantErr: true},
+		{name: "uuid", col: Column{Name: "c", Type: TypeUUID}, def: &DefaultValue{Value: "6f1c3bb6-4b8e-4d5b-9f59-5d4d0f0b0c1a"}},
+		{name: "invalid uuid", col: Column{Name: "c", Type: TypeUUID}, def: &DefaultValue{Value: "not-a-uuid"}, wantErr: true},
+		{name: "bytea", col: Column{Name: "c", Type: TypeBYTEA}, def: &DefaultValue{Value: "AQL/"}},
+		{name: "invalid base64", col: Column{Name: "c", Type: TypeBYTEA}, def: &DefaultValue{Value: "%%%"}, wantErr: true},
+		{name: "inet", col: Column{Name: "c", Type: TypeINET}, def: &DefaultValue{Value: "192.168.0.1"}},
+		{name: "cidr", col: Column{Name: "c", Type: TypeCIDR}, def: &DefaultValue{Value: "10.0.0.0/8"}},
+		{name: "cidr with host bits", col: Column{Name: "c", Type: TypeCIDR}, def: &DefaultValue{Value: "10.0.0.1/8"}, wantErr: true},
+		{name: "json", col: Column{Name: "c", Type: TypeJSON}, def: &DefaultValue{Value: map[string]interface{}{"a": 1.0}}},
+		{name: "enum value", col: status, def: &DefaultValue{Value: "draft"}},
+		{name: "unknown enum value", col: status, def: &DefaultValue{Value: "archived"}, wantErr: true},
+		{name: "array", col: Column{Name: "c", Type: TypeARRAY, ElementType: TypeINT}, def: &DefaultValue{Value: []interface{}{1.0, nil, 3.0}}},
+		{name: "array with a wrong element", col: Column{Name: "c", Type: TypeARRAY, ElementType: TypeINT}, def: &DefaultValue{Value: []interface{}{"1"}}, wantErr: true},
+		{name: "array from a scalar", col: Column{Name: "c", Type: TypeARRAY, ElementType: TypeTEXT}, def: &DefaultValue{Value: "a"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			col := tt.col
+			col.Default = tt.def
+			if err := ValidateDefault(col); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDefault error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+// File: internal/domain/tableentity/diff.go
+
+package tableentity
+
+import (
+	"errors"
+	"fmt"
+)
+
+type ChangeKind string
+
+const (
+	ChangeRenameColumn ChangeKind = "rename_column"
+	ChangeDropColumn   ChangeKind = "drop_column"
+	ChangeAddColumn    ChangeKind = "add_column"
+	ChangeAlterType    ChangeKind = "alter_type"
+	ChangeSetNotNull   ChangeKind = "set_not_null"
+	ChangeDropNotNull  ChangeKind = "drop_not_null"
+	ChangeSetDefault   ChangeKind = "set_default"
+	ChangeDropDefault  ChangeKind = "drop_default"
+	ChangeAddUnique    ChangeKind = "add_unique"
+	ChangeDropUnique   ChangeKind = "drop_unique"
+	ChangeAddEnumValue ChangeKind = "add_enum_values"
+)
+
+// changeOrder is the order in which column changes are applied
+var changeOrder = []ChangeKind{
+	ChangeRenameColumn,
+	ChangeDropUnique,
+	ChangeDropColumn,
+	ChangeAddEnumValue,
+	ChangeAddColumn,
+	ChangeAlterType,
+	ChangeDropDefault,
+	ChangeSetDefault,
+	ChangeDropNotNull,
+	ChangeSetNotNull,
+	ChangeAddUnique,
+}
+
+// ColumnChange is a single column level step needed to reach a desired table definition
+type ColumnChange struct {
+	Kind   ChangeKind `json:"kind"`
+	Column string     `json:"column"`
+	From   *Column    `json:"from,omitempty"`
+	To     *Column    `json:"to,omitempty"`
+}
+
+func (c ColumnChange) String() string {
+	switch c.Kind {
+	case ChangeRenameColumn:
+		return fmt.Sprintf("rename column %s to %s", c.From.Name, c.To.Name)
+	case ChangeDropColumn:
+		return fmt.Sprintf("drop column %s", c.Column)
+	case ChangeAddColumn:
+		return fmt.Sprintf("add column %s %s", c.Column, c.To.Type)
+	case ChangeAlterType:
+		return fmt.Sprintf("change type of %s from %s to %s", c.Column, c.From.Type, c.To.Type)
+	case ChangeSetNotNull:
+		return fmt.Sprintf("set %s NOT NULL", c.Column)
+	case ChangeDropNotNull:
+		return fmt.Sprintf("allow NULL in %s", c.Column)
+	case ChangeSetDefault:
+		return fmt.Sprintf("set default of %s", c.Column)
+	case ChangeDropDefault:
+		return fmt.Sprintf("drop default of %s", c.Column)
+	case ChangeAddUnique:
+		return fmt.Sprintf("add unique constraint on %s", c.Column)
+	case ChangeDropUnique:
+		return fmt.Sprintf("drop unique constraint on %s", c.Column)
+	case ChangeAddEnumValue:
+		return fmt.Sprintf("add values %v to enum of %s", c.To.EnumValues[len(c.From.EnumValues):], c.Column)
+	}
+	return string(c.Kind) + " " + c.Column
+}
+
+// DiffTables computes the column changes that turn current into desired.
+// A desired column with RenamedFrom set is matched with the current column of that name,
+// or with its own name once the rename has been applied.
+func DiffTables(current, desired *Table) ([]ColumnChange, error) {
+	currentColumns := make(map[string]Column, len(current.Columns))
+	for _, col := range current.Columns {
+		if !col.IsVirtual() {
+			currentColumns[col.Name] = col
+		}
+	}
+
+	matched := make(map[string]bool)
+	byKind := make(map[ChangeKind][]ColumnChange)
+	add := func(change ColumnChange) {
+		byKind[change.Kind] = append(byKind[change.Kind], change)
+	}
+
+	for _, to := range desired.Columns {
+		// 多対多カラムは結合テーブルで表現されるため物理的な変更はない
+		if to.IsVirtual() {
+			continue
+		}
+
+		sourceName := to.Name
+		if to.RenamedFrom != "" && to.RenamedFrom != to.Name {
+			_, oldExists := currentColumns[to.RenamedFrom]
+			_, newExists := currentColumns[to.Name]
+			if oldExists && newExists {
+				return nil, fmt.Errorf("cannot rename %s to %s: column already exists", to.RenamedFrom, to.Name)
+			}
+			// 適用済みのリネームが定義に残っている場合は新しい名前と比較する
+			if !newExists {
+				sourceName = to.RenamedFrom
+			}
+		}
+
+		from, exists := currentColumns[sourceName]
+		if !exists {
+			if to.RenamedFrom != "" {
+				return nil, fmt.Errorf("cannot rename unknown column %s", to.RenamedFrom)
+			}
+			if to.PrimaryKey {
+				return nil, errors.New("primary key columns cannot be added to an existing table")
+			}
+			add(ColumnChange{Kind: ChangeAddColumn, Column: to.Name, To: &to})
+			continue
+		}
+		if matched[sourceName] {
+			return nil, fmt.Errorf("column %s is referenced more than once", sourceName)
+		}
+		matched[sourceName] = true
+
+		if from.PrimaryKey != to.PrimaryKey || from.AutoIncrement != to.AutoIncrement {
+			return nil, fmt.Errorf("primary key and auto increment settings of %s cannot be changed", to.Name)
+		}
+
+		if !sameRelation(from.Relation, to.Relation) {
+			return nil, fmt.Errorf("relation of %s cannot be changed", to.Name)
+		}
+
+		if (from.Type == TypeASSET) != (to.Type == TypeASSET) {
+			return nil, fmt.Errorf("%s cannot be converted to or from an asset column", to.Name)
+		}
+
+		if sourceName != to.Name {
+			add(ColumnChange{Kind: ChangeRenameColumn, Column: to.Name, From: &from, To: &to})
+		}
+		if !sameType(from, to) {
+			add(ColumnChange{Kind: ChangeAlterType, Column: to.Name, From: &from, To: &to})
+		} else if to.Type == TypeENUM {
+			added, err := appendedEnumValues(from, to)
+			if err != nil {
+				return nil, err
+			}
+			if len(added) > 0 {
+				add(ColumnChange{Kind: ChangeAddEnumValue, Column: to.Name, From: &from, To: &to})
+			}
+		}
+		if from.NotNull != to.NotNull {
+			kind := ChangeDropNotNull
+			if to.NotNull {
+				kind = ChangeSetNotNull
+			}
+			add(ColumnChange{Kind: kind, Column: to.Name, From: &from, To: &to})
+		}
+		if !SameDefault(from.Default, to.Default) {
+			kind := ChangeSetDefault
+			if to.Default == nil {
+				kind = ChangeDropDefault
+			}
+			add(ColumnChange{Kind: kind, Column: to.Name, From: &from, To: &to})
+		}
+		if from.Unique != to.Unique {
+			kind := ChangeDropUnique
+			if to.Unique {
+				kind = ChangeAddUnique
+			}
+			add(ColumnChange{Kind: kind, Column: to.Name, From: &from, To: &to})
+		}
+	}
+
+	for _, from := range current.Columns {
+		if from.IsVirtual() || matched[from.Name] {
+			continue
+		}
+		if from.PrimaryKey {
+			return nil, fmt.Errorf("primary key column %s cannot be dropped", from.Name)
+		}
+		add(ColumnChange{Kind: ChangeDropColumn, Column: from.Name, From: &from})
+	}
+
+	var changes []ColumnChange
+	for _, kind := range changeOrder {
+		changes = append(changes, byKind[kind]...)
+	}
+	return changes, nil
+}
+
+// IsNarrowing reports whether converting from one column type to another may lose data
+func IsNarrowing(from, to Column) bool {
+	// 多言語カラムを戻すと既定ロケール以外の翻訳が失われる
+	if from.Localized && !to.Localized {
+		return true
+	}
+	from.Localized, to.Localized = false, false
+
+	// text の表現へはバイナリ以外ロスなく変換できる
+	if to.Type == TypeTEXT && from.Type != TypeBYTEA {
+		return false
+	}
+
+	if from.Type == to.Type {
+		switch from.Type {
+		case TypeVARCHAR:
+			if to.Length == nil {
+				return false
+			}
+			return from.Length == nil || *to.Length < *from.Length
+		case TypeNUMERIC:
+			if to.Precision == nil {
+				return false
+			}
+			if from.Precision == nil {
+				return true
+			}
+			return integerDigits(to) < integerDigits(from) || intValue(to.Scale) < intValue(from.Scale)
+		case TypeARRAY:
+			fromElement := Column{Type: from.ElementType, Length: from.Length, Precision: from.Precision, Scale: from.Scale}
+			toElement := Column{Type: to.ElementType, Length: to.Length, Precision: to.Precision, Scale: to.Scale}
+			return IsNarrowing(fromElement, toElement)
+		case TypeENUM:
+			return from.EnumName != to.EnumName
+		}
+		return false
+	}
+
+	switch from.Type {
+	case TypeINT:
+		return !(to.Type == TypeBIGINT || to.Type == TypeDOUBLE || to.Type == TypeFLOAT || holdsIntegerDigits(to, 10))
+	case TypeBIGINT:
+		return !holdsIntegerDigits(to, 19)
+	case TypeFLOAT, TypeDOUBLE:
+		// PostgreSQL の float は double precision と同じ
+		return to.Type != TypeDOUBLE && to.Type != TypeFLOAT
+	case TypeDATE:
+		return to.Type != TypeTIMESTAMP && to.Type != TypeTIMESTAMPTZ
+	case TypeTIMESTAMP:
+		return to.Type != TypeTIMESTAMPTZ
+	case TypeCIDR:
+		return to.Type != TypeINET
+	}
+	return true
+}
+
+// holdsIntegerDigits reports whether a numeric column can store integers with the given number of digits
+func holdsIntegerDigits(col Column, digits int) bool {
+	return col.Type == TypeNUMERIC && (col.Precision == nil || integerDigits(col) >= digits)
+}
+
+func integerDigits(col Column) int {
+	return intValue(col.Precision) - intValue(col.Scale)
+}
+
+func intValue(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func sameType(a, b Column) bool {
+	return a.Type == b.Type &&
+		a.Localized == b.Localized &&
+		a.ElementType == b.ElementType &&
+		a.EnumName == b.EnumName &&
+		sameInt(a.Length, b.Length) &&
+		sameInt(a.Precision, b.Precision) &&
+		sameInt(a.Scale, b.Scale)
+}
+
+func sameInt(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// appendedEnumValues returns the values added to the end of an enum, or an error
+// when existing values were removed or reordered, which PostgreSQL cannot do in place
+func appendedEnumValues(from, to Column) ([]string, error) {
+	if len(to.EnumValues) < len(from.EnumValues) {
+		return nil, fmt.Errorf("values of enum %s cannot be removed", to.Name)
+	}
+	for i, value := range from.EnumValues {
+		if to.EnumValues[i] != value {
+			return nil, fmt.Errorf("values of enum %s can only be appended", to.Name)
+		}
+	}
+	return to.EnumValues[len(from.EnumValues):], nil
+}
+
+func sameRelation(a, b *Relation) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
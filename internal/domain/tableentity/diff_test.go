@@ -0,0 +1,219 @@
+// File: internal/domain/tableentity/diff_test.go
+
+package tableentity
+
+import (
+	"reflect"
+	"testing"
+)
+
+// changeSummary lists the changes as "kind column" in order
+func changeSummary(changes []ColumnChange) []string {
+	summary := make([]string, len(changes))
+	for i, change := range changes {
+		summary[i] = string(change.Kind) + " " + change.Column
+	}
+	return summary
+}
+
+func TestDiffTables(t *testing.T) {
+	id := Column{Name: "id", Type: TypeBIGINT, PrimaryKey: true, AutoIncrement: true}
+	title := Column{Name: "title", Type: TypeTEXT}
+	length := 50
+	status := Column{Name: "status", Type: TypeENUM, EnumName: "article_status", EnumValues: []string{"draft", "published"}}
+	tags := Column{Name: "tags", Type: TypeINT, Relation: &Relation{Type: RelationManyToMany, Table: "tags", Column: "id"}}
+	table := func(columns ...Column) *Table {
+		return &Table{Name: "articles", Columns: columns}
+	}
+	with := func(col Column, edit func(*Column)) Column {
+		edit(&col)
+		return col
+	}
+
+	tests := []struct {
+		name    string
+		current *Table
+		desired *Table
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "no changes",
+			current: table(id, title),
+			desired: table(id, title),
+			want:    []string{},
+		},
+		{
+			name:    "add and drop",
+			current: table(id, title),
+			desired: table(id, Column{Name: "body", Type: TypeTEXT}),
+			want:    []string{"drop_column title", "add_column body"},
+		},
+		{
+			name:    "rename",
+			current: table(id, title),
+			desired: table(id, with(title, func(c *Column) { c.Name, c.RenamedFrom = "headline", "title" })),
+			want:    []string{"rename_column headline"},
+		},
+		{
+			name:    "rename already applied is compared by the new name",
+			current: table(id, Column{Name: "headline", Type: TypeTEXT}),
+			desired: table(id, Column{Name: "headline", Type: TypeTEXT, RenamedFrom: "title"}),
+			want:    []string{},
+		},
+		{
+			name:    "rename with other changes",
+			current: table(id, title),
+			desired: table(id, Column{Name: "headline", Type: TypeVARCHAR, Length: &length, NotNull: true, RenamedFrom: "title"}),
+			want:    []string{"rename_column headline", "alter_type headline", "set_not_null headline"},
+		},
+		{
+			name:    "changes follow the apply order",
+			current: table(id, with(title, func(c *Column) { c.Unique = true }), Column{Name: "views", Type: TypeINT, NotNull: true, Default: &DefaultValue{Value: 0.0}}),
+			desired: table(id, title, Column{Name: "views", Type: TypeBIGINT, Default: &DefaultValue{Value: 1.0}, Unique: true}),
+			want:    []string{"drop_unique title", "alter_type views", "set_default views", "drop_not_null views", "add_unique views"},
+		},
+		{
+			name:    "drop default",
+			current: table(id, with(title, func(c *Column) { c.Default = &DefaultValue{Value: "untitled"} })),
+			desired: table(id, title),
+			want:    []string{"drop_default title"},
+		},
+		{
+			name:    "append enum values",
+			current: table(id, status),
+			desired: table(id, with(status, func(c *Column) { c.EnumValues = []string{"draft", "published", "archived"} })),
+			want:    []string{"add_enum_values status"},
+		},
+		{
+			name:    "switch enum type",
+			current: table(id, status),
+			desired: table(id, with(status, func(c *Column) { c.EnumName = "page_status" })),
+			want:    []string{"alter_type status"},
+		},
+		{
+			name:    "localize a column",
+			current: table(id, title),
+			desired: table(id, with(title, func(c *Column) { c.Localized = true })),
+			want:    []string{"alter_type title"},
+		},
+		{
+			name:    "many to many columns have no physical changes",
+			current: table(id, title, tags),
+			desired: table(id, title),
+			want:    []string{},
+		},
+		{
+			name:    "reordered enum values",
+			current: table(id, status),
+			desired: table(id, with(status, func(c *Column) { c.EnumValues = []string{"published", "draft"} })),
+			wantErr: true,
+		},
+		{
+			name:    "removed enum value",
+			current: table(id, status),
+			desired: table(id, with(status, func(c *Column) { c.EnumValues = []string{"draft"} })),
+			wantErr: true,
+		},
+		{
+			name:    "rename onto an existing column",
+			current: table(id, title, Column{Name: "headline", Type: TypeTEXT}),
+			desired: table(id, Column{Name: "headline", Type: TypeTEXT, RenamedFrom: "title"}),
+			wantErr: true,
+		},
+		{
+			name:    "rename of an unknown column",
+			current: table(id, title),
+			desired: table(id, title, Column{Name: "headline", Type: TypeTEXT, RenamedFrom: "subtitle"}),
+			wantErr: true,
+		},
+		{
+			name:    "two columns renamed from the same one",
+			current: table(id, title),
+			desired: table(id, Column{Name: "a", Type: TypeTEXT, RenamedFrom: "title"}, Column{Name: "b", Type: TypeTEXT, RenamedFrom: "title"}),
+			wantErr: true,
+		},
+		{
+			name:    "add a primary key column",
+			current: table(id),
+			desired: table(id, Column{Name: "code", Type: TypeTEXT, PrimaryKey: true}),
+			wantErr: true,
+		},
+		{
+			name:    "drop the primary key column",
+			current: table(id, title),
+			desired: table(title),
+			wantErr: true,
+		},
+		{
+			name:    "change auto increment",
+			current: table(id),
+			desired: table(with(id, func(c *Column) { c.AutoIncrement = false })),
+			wantErr: true,
+		},
+		{
+			name:    "change a relation",
+			current: table(id, Column{Name: "author_id", Type: TypeBIGINT, Relation: &Relation{Type: RelationOneToMany, Table: "users", Column: "id"}}),
+			desired: table(id, Column{Name: "author_id", Type: TypeBIGINT, Relation: &Relation{Type: RelationOneToMany, Table: "members", Column: "id"}}),
+			wantErr: true,
+		},
+		{
+			name:    "convert to an asset",
+			current: table(id, Column{Name: "cover", Type: TypeUUID}),
+			desired: table(id, Column{Name: "cover", Type: TypeASSET}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := DiffTables(tt.current, tt.desired)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DiffTables error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := changeSummary(changes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNarrowing(t *testing.T) {
+	n := func(v int) *int { return &v }
+	tests := []struct {
+		name     string
+		from, to Column
+		want     bool
+	}{
+		{"int to bigint", Column{Type: TypeINT}, Column{Type: TypeBIGINT}, false},
+		{"bigint to int", Column{Type: TypeBIGINT}, Column{Type: TypeINT}, true},
+		{"int to wide numeric", Column{Type: TypeINT}, Column{Type: TypeNUMERIC, Precision: n(12), Scale: n(2)}, false},
+		{"int to narrow numeric", Column{Type: TypeINT}, Column{Type: TypeNUMERIC, Precision: n(8), Scale: n(2)}, true},
+		{"anything to text", Column{Type: TypeTIMESTAMPTZ}, Column{Type: TypeTEXT}, false},
+		{"bytea to text", Column{Type: TypeBYTEA}, Column{Type: TypeTEXT}, true},
+		{"text to int", Column{Type: TypeTEXT}, Column{Type: TypeINT}, true},
+		{"longer varchar", Column{Type: TypeVARCHAR, Length: n(10)}, Column{Type: TypeVARCHAR, Length: n(20)}, false},
+		{"shorter varchar", Column{Type: TypeVARCHAR, Length: n(20)}, Column{Type: TypeVARCHAR, Length: n(10)}, true},
+		{"unbounded varchar to bounded", Column{Type: TypeVARCHAR}, Column{Type: TypeVARCHAR, Length: n(10)}, true},
+		{"numeric scale reduced", Column{Type: TypeNUMERIC, Precision: n(10), Scale: n(4)}, Column{Type: TypeNUMERIC, Precision: n(10), Scale: n(2)}, true},
+		{"unbounded numeric to bounded", Column{Type: TypeNUMERIC}, Column{Type: TypeNUMERIC, Precision: n(10)}, true},
+		{"date to timestamp", Column{Type: TypeDATE}, Column{Type: TypeTIMESTAMP}, false},
+		{"timestamp to date", Column{Type: TypeTIMESTAMP}, Column{Type: TypeDATE}, true},
+		{"cidr to inet", Column{Type: TypeCIDR}, Column{Type: TypeINET}, false},
+		{"float is double precision", Column{Type: TypeFLOAT}, Column{Type: TypeDOUBLE}, false},
+		{"array element widened", Column{Type: TypeARRAY, ElementType: TypeINT}, Column{Type: TypeARRAY, ElementType: TypeBIGINT}, false},
+		{"array element narrowed", Column{Type: TypeARRAY, ElementType: TypeBIGINT}, Column{Type: TypeARRAY, ElementType: TypeINT}, true},
+		{"other enum type", Column{Type: TypeENUM, EnumName: "a"}, Column{Type: TypeENUM, EnumName: "b"}, true},
+		{"localize", Column{Type: TypeTEXT}, Column{Type: TypeTEXT, Localized: true}, false},
+		{"drop translations", Column{Type: TypeTEXT, Localized: true}, Column{Type: TypeTEXT}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNarrowing(tt.from, tt.to); got != tt.want {
+				t.Errorf("IsNarrowing = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// File: internal/domain/tableentity/index.go
+
+package tableentity
+
+import (
+	"reflect"
+	"strings"
+)
+
+type IndexMethod string
+
+const (
+	IndexBTree IndexMethod = "btree"
+	IndexHash  IndexMethod = "hash"
+	IndexGIN   IndexMethod = "gin"
+	IndexGiST  IndexMethod = "gist"
+	IndexBRIN  IndexMethod = "brin"
+)
+
+type ConditionOperator string
+
+const (
+	ConditionIsNull    ConditionOperator = "is_null"
+	ConditionIsNotNull ConditionOperator = "is_not_null"
+	ConditionEqual     ConditionOperator = "eq"
+	ConditionNotEqual  ConditionOperator = "neq"
+)
+
+// Index is a secondary index on a user-defined table.
+// Where conditions are combined with AND to build a partial index.
+type Index struct {
+	Name    string           `json:"name"`
+	Columns []string         `json:"columns"`
+	Unique  bool             `json:"unique,omitempty"`
+	Method  IndexMethod      `json:"method,omitempty"`
+	Where   []IndexCondition `json:"where,omitempty"`
+}
+
+type IndexCondition struct {
+	Column   string            `json:"column"`
+	Operator ConditionOperator `json:"operator"`
+	Value    interface{}       `json:"value,omitempty"`
+}
+
+func (m IndexMethod) IsValid() bool {
+	switch m {
+	case "", IndexBTree, IndexHash, IndexGIN, IndexGiST, IndexBRIN:
+		return true
+	}
+	return false
+}
+
+// DefaultIndexName derives an index name from the table and column names
+func DefaultIndexName(tableName string, columns []string) string {
+	return tableName + "_" + strings.Join(columns, "_") + "_idx"
+}
+
+// DiffIndexes returns the indexes to drop and to create to turn current into desired.
+// An index whose definition changed is dropped and created again.
+func DiffIndexes(current, desired []Index) (dropped, added []Index) {
+	currentByName := make(map[string]Index, len(current))
+	for _, idx := range current {
+		currentByName[idx.Name] = idx
+	}
+	desiredByName := make(map[string]bool, len(desired))
+
+	for _, idx := range desired {
+		desiredByName[idx.Name] = true
+		existing, ok := currentByName[idx.Name]
+		if !ok {
+			added = append(added, idx)
+			continue
+		}
+		if !reflect.DeepEqual(existing, idx) {
+			dropped = append(dropped, existing)
+			added = append(added, idx)
+		}
+	}
+
+	for _, idx := range current {
+		if !desiredByName[idx.Name] {
+			dropped = append(dropped, idx)
+		}
+	}
+	return dropped, added
+}
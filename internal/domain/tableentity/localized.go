@@ -0,0 +1,52 @@
+// File: internal/domain/tableentity/localized.go
+
+package tableentity
+
+import "fmt"
+
+// Localized columns are stored as a jsonb object keyed by locale, such as {"ja": "...", "en": "..."}.
+// The declared varchar or text type, length and rules apply to every translation.
+
+// HasLocalized reports whether the table has at least one localized column
+func (t *Table) HasLocalized() bool {
+	return len(t.LocalizedColumns()) > 0
+}
+
+// LocalizedColumns returns the localized columns of the table in definition order
+func (t *Table) LocalizedColumns() []Column {
+	var columns []Column
+	for _, col := range t.Columns {
+		if col.Localized {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// ValidateLocalized checks that localized columns are plain text columns. Keys, relations,
+// defaults and unique constraints compare whole values and cannot apply per locale.
+func ValidateLocalized(table *Table) error {
+	for _, col := range table.Columns {
+		if !col.Localized {
+			continue
+		}
+		if !col.Type.IsText() {
+			return fmt.Errorf("localized column %s must be varchar or text", col.Name)
+		}
+		if col.PrimaryKey || col.Unique || col.Relation != nil || col.Default != nil {
+			return fmt.Errorf("localized column %s cannot be a key, a relation, unique or have a default", col.Name)
+		}
+	}
+
+	for _, index := range table.Indexes {
+		if !index.Unique {
+			continue
+		}
+		for _, name := range index.Columns {
+			if col, ok := table.Column(name); ok && col.Localized {
+				return fmt.Errorf("unique index %s cannot include localized column %s", index.Name, name)
+			}
+		}
+	}
+	return nil
+}
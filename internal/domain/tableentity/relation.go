@@ -0,0 +1,122 @@
+// File: internal/domain/tableentity/relation.go
+
+package tableentity
+
+type RelationType string
+
+const (
+	// RelationOneToMany stores a foreign key in this column; many rows here reference one row of the target
+	RelationOneToMany RelationType = "one_to_many"
+	// RelationManyToMany is backed by a generated join table and has no physical column
+	RelationManyToMany RelationType = "many_to_many"
+)
+
+type ReferentialAction string
+
+const (
+	ActionNoAction   ReferentialAction = "NO ACTION"
+	ActionRestrict   ReferentialAction = "RESTRICT"
+	ActionCascade    ReferentialAction = "CASCADE"
+	ActionSetNull    ReferentialAction = "SET NULL"
+	ActionSetDefault ReferentialAction = "SET DEFAULT"
+)
+
+// Relation describes a reference from a column to a key of another table
+type Relation struct {
+	Type     RelationType      `json:"type"`
+	Table    string            `json:"table"`
+	Column   string            `json:"column"`
+	OnDelete ReferentialAction `json:"on_delete,omitempty"`
+	OnUpdate ReferentialAction `json:"on_update,omitempty"`
+}
+
+func (a ReferentialAction) IsValid() bool {
+	switch a {
+	case "", ActionNoAction, ActionRestrict, ActionCascade, ActionSetNull, ActionSetDefault:
+		return true
+	}
+	return false
+}
+
+// IsVirtual reports whether the column has no physical counterpart in the table
+func (c Column) IsVirtual() bool {
+	return c.Relation != nil && c.Relation.Type == RelationManyToMany
+}
+
+// PrimaryKey returns the primary key columns of the table
+func (t *Table) PrimaryKey() []Column {
+	var pk []Column
+	for _, col := range t.Columns {
+		if col.PrimaryKey {
+			pk = append(pk, col)
+		}
+	}
+	return pk
+}
+
+// Column returns the column with the given name
+func (t *Table) Column(name string) (Column, bool) {
+	for _, col := range t.Columns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return Column{}, false
+}
+
+// JoinTableName returns the name of the join table backing a many-to-many column
+func JoinTableName(table *Table, col Column) string {
+	return table.Name + "_" + col.Name
+}
+
+// JoinTable builds the join table definition for a many-to-many column.
+// The owning table must have a single column primary key.
+func JoinTable(table *Table, col Column, target Column) *Table {
+	source := table.PrimaryKey()[0]
+
+	sourceName := table.Name + "_" + source.Name
+	targetName := col.Relation.Table + "_" + target.Name
+	if sourceName == targetName {
+		targetName = "related_" + targetName
+	}
+
+	onDelete := col.Relation.OnDelete
+	if onDelete == "" {
+		onDelete = ActionCascade
+	}
+
+	return &Table{
+		Name:        JoinTableName(table, col),
+		Description: "Join table for " + table.Name + "." + col.Name,
+		JoinFor:     table.Name + "." + col.Name,
+		Columns: []Column{
+			joinColumn(sourceName, source, &Relation{
+				Type:     RelationOneToMany,
+				Table:    table.Name,
+				Column:   source.Name,
+				OnDelete: onDelete,
+				OnUpdate: col.Relation.OnUpdate,
+			}),
+			joinColumn(targetName, target, &Relation{
+				Type:     RelationOneToMany,
+				Table:    col.Relation.Table,
+				Column:   target.Name,
+				OnDelete: onDelete,
+				OnUpdate: col.Relation.OnUpdate,
+			}),
+		},
+	}
+}
+
+func joinColumn(name string, key Column, relation *Relation) Column {
+	return Column{
+		Name:       name,
+		Type:       key.Type,
+		Length:     key.Length,
+		Precision:  key.Precision,
+		Scale:      key.Scale,
+		NotNull:    true,
+		PrimaryKey: true,
+		Relation:   relation,
+	}
+}
@@ -0,0 +1,143 @@
+// File: internal/domain/tableentity/rules.go
+
+package tableentity
+
+import (
+	"fmt"
+	"regexp"
+
+	"quickflow/pkg/expr"
+)
+
+type Format string
+
+const (
+	FormatEmail Format = "email"
+	FormatURL   Format = "url"
+)
+
+// Rules are declarative constraints checked by the record validator before a write.
+// They are not part of the physical table.
+type Rules struct {
+	// Min and Max bound numeric values
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// MinLength and MaxLength bound the number of characters of text values
+	MinLength *int   `json:"min_length,omitempty"`
+	MaxLength *int   `json:"max_length,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+	Format    Format `json:"format,omitempty"`
+	// Checks are expressions over the record; the column value is also available as self
+	Checks []Check `json:"checks,omitempty"`
+}
+
+// SelfVariable names the column value inside a column check
+const SelfVariable = "self"
+
+// Check is an expression rule such as `end_date > start_date`, evaluated against the whole record
+// on create and update. It fails only when the expression is false.
+type Check struct {
+	Name       string `json:"name,omitempty"`
+	Expression string `json:"expression"`
+	// Message replaces the default violation message
+	Message string `json:"message,omitempty"`
+}
+
+func (f Format) IsValid() bool {
+	return f == FormatEmail || f == FormatURL
+}
+
+// IsNumeric reports whether the type holds numbers that Min and Max apply to
+func (t ColumnType) IsNumeric() bool {
+	switch t {
+	case TypeINT, TypeBIGINT, TypeFLOAT, TypeDOUBLE, TypeNUMERIC:
+		return true
+	}
+	return false
+}
+
+// IsText reports whether the type holds text that length, pattern and format rules apply to
+func (t ColumnType) IsText() bool {
+	return t == TypeVARCHAR || t == TypeTEXT
+}
+
+// ValidateRules checks that the rules of a column fit its type
+func ValidateRules(col Column) error {
+	rules := col.Rules
+	if rules == nil {
+		return nil
+	}
+
+	if rules.Min != nil || rules.Max != nil {
+		if !col.Type.IsNumeric() {
+			return fmt.Errorf("min and max rules of %s need a numeric column", col.Name)
+		}
+		if rules.Min != nil && rules.Max != nil && *rules.Min > *rules.Max {
+			return fmt.Errorf("min rule of %s is greater than max", col.Name)
+		}
+	}
+
+	if rules.MinLength != nil || rules.MaxLength != nil || rules.Pattern != "" || rules.Format != "" {
+		if !col.Type.IsText() {
+			return fmt.Errorf("length, pattern and format rules of %s need a varchar or text column", col.Name)
+		}
+	}
+	if (rules.MinLength != nil && *rules.MinLength < 0) || (rules.MaxLength != nil && *rules.MaxLength < 0) {
+		return fmt.Errorf("length rules of %s must not be negative", col.Name)
+	}
+	if rules.MinLength != nil && rules.MaxLength != nil && *rules.MinLength > *rules.MaxLength {
+		return fmt.Errorf("min_length rule of %s is greater than max_length", col.Name)
+	}
+	if rules.Pattern != "" {
+		if _, err := regexp.Compile(rules.Pattern); err != nil {
+			return fmt.Errorf("pattern rule of %s is not a valid regular expression: %v", col.Name, err)
+		}
+	}
+	if rules.Format != "" && !rules.Format.IsValid() {
+		return fmt.Errorf("format rule of %s must be email or url", col.Name)
+	}
+	return nil
+}
+
+// ValidateChecks compiles the column and table checks and makes sure they only refer to physical columns
+func ValidateChecks(table *Table) error {
+	columns := make(map[string]bool, len(table.Columns))
+	for _, col := range table.Columns {
+		if !col.IsVirtual() {
+			columns[col.Name] = true
+		}
+	}
+
+	for _, col := range table.Columns {
+		if col.Rules == nil {
+			continue
+		}
+		for _, check := range col.Rules.Checks {
+			if err := validateCheck(check, columns, true); err != nil {
+				return fmt.Errorf("check of column %s: %v", col.Name, err)
+			}
+		}
+	}
+	for _, check := range table.Checks {
+		if err := validateCheck(check, columns, false); err != nil {
+			return fmt.Errorf("check of table %s: %v", table.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateCheck(check Check, columns map[string]bool, self bool) error {
+	if check.Expression == "" {
+		return fmt.Errorf("expression is required")
+	}
+	program, err := expr.Compile(check.Expression)
+	if err != nil {
+		return fmt.Errorf("invalid expression %q: %v", check.Expression, err)
+	}
+	for _, name := range program.Identifiers() {
+		if !columns[name] && !(self && name == SelfVariable) {
+			return fmt.Errorf("expression %q refers to unknown column %s", check.Expression, name)
+		}
+	}
+	return nil
+}
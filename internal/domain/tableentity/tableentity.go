@@ -4,31 +4,100 @@ package tableentity
 type ColumnType string
 
 const (
-	TypeVARCHAR   ColumnType = "varchar"
-	TypeTEXT      ColumnType = "text"
-	TypeINT       ColumnType = "integer"
-	TypeBIGINT    ColumnType = "bigint"
-	TypeFLOAT     ColumnType = "float"
-	TypeDOUBLE    ColumnType = "double precision"
-	TypeBOOLEAN   ColumnType = "boolean"
-	TypeDATE      ColumnType = "date"
-	TypeTIMESTAMP ColumnType = "timestamp"
-	TypeJSON      ColumnType = "jsonb"
+	TypeVARCHAR     ColumnType = "varchar"
+	TypeTEXT        ColumnType = "text"
+	TypeINT         ColumnType = "integer"
+	TypeBIGINT      ColumnType = "bigint"
+	TypeFLOAT       ColumnType = "float"
+	TypeDOUBLE      ColumnType = "double precision"
+	TypeBOOLEAN     ColumnType = "boolean"
+	TypeDATE        ColumnType = "date"
+	TypeTIMESTAMP   ColumnType = "timestamp"
+	TypeJSON        ColumnType = "jsonb"
+	TypeUUID        ColumnType = "uuid"
+	TypeNUMERIC     ColumnType = "numeric"
+	TypeTIMESTAMPTZ ColumnType = "timestamptz"
+	TypeTIME        ColumnType = "time"
+	TypeBYTEA       ColumnType = "bytea"
+	TypeINET        ColumnType = "inet"
+	TypeCIDR        ColumnType = "cidr"
+	TypeARRAY       ColumnType = "array"
+	TypeENUM        ColumnType = "enum"
+	// TypeASSET references an uploaded asset; it is stored as the asset uuid
+	TypeASSET ColumnType = "asset"
 )
 
+// VersionColumn is the system column every user-defined table carries for optimistic
+// concurrency. Each write bumps it; it is not part of the table definition.
+const VersionColumn = "_version"
+
+// scalarTypes can be used as array elements
+var scalarTypes = map[ColumnType]bool{
+	TypeVARCHAR:     true,
+	TypeTEXT:        true,
+	TypeINT:         true,
+	TypeBIGINT:      true,
+	TypeFLOAT:       true,
+	TypeDOUBLE:      true,
+	TypeBOOLEAN:     true,
+	TypeDATE:        true,
+	TypeTIMESTAMP:   true,
+	TypeUUID:        true,
+	TypeNUMERIC:     true,
+	TypeTIMESTAMPTZ: true,
+	TypeTIME:        true,
+	TypeINET:        true,
+	TypeCIDR:        true,
+}
+
+// IsValid reports whether the type is supported for columns
+func (t ColumnType) IsValid() bool {
+	switch t {
+	case TypeJSON, TypeBYTEA, TypeARRAY, TypeENUM, TypeASSET:
+		return true
+	}
+	return scalarTypes[t]
+}
+
+// IsScalar reports whether the type can be used as an array element
+func (t ColumnType) IsScalar() bool {
+	return scalarTypes[t]
+}
+
 type Column struct {
-	Name          string     `json:"name"`
-	Type          ColumnType `json:"type"`
-	Length        *int       `json:"length,omitempty"`
-	NotNull       bool       `json:"not_null"`
-	PrimaryKey    bool       `json:"primary_key"`
-	AutoIncrement bool       `json:"auto_increment"`
-	Unique        bool       `json:"unique"`
-	Default       *string    `json:"default,omitempty"`
+	Name          string        `json:"name"`
+	Type          ColumnType    `json:"type"`
+	Length        *int          `json:"length,omitempty"`
+	Precision     *int          `json:"precision,omitempty"`
+	Scale         *int          `json:"scale,omitempty"`
+	ElementType   ColumnType    `json:"element_type,omitempty"`
+	EnumName      string        `json:"enum_name,omitempty"`
+	EnumValues    []string      `json:"enum_values,omitempty"`
+	NotNull       bool          `json:"not_null"`
+	PrimaryKey    bool          `json:"primary_key"`
+	AutoIncrement bool          `json:"auto_increment"`
+	Unique        bool          `json:"unique"`
+	Default       *DefaultValue `json:"default,omitempty"`
+	DisplayName   string        `json:"display_name,omitempty"`
+	Description   string        `json:"description,omitempty"`
+	Relation      *Relation     `json:"relation,omitempty"`
+	Rules         *Rules        `json:"rules,omitempty"`
+	// Localized columns hold one value per content locale
+	Localized bool `json:"localized,omitempty"`
+	// RenamedFrom names the existing column this one replaces when altering a table
+	RenamedFrom string `json:"renamed_from,omitempty"`
 }
 
 type Table struct {
 	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name,omitempty"`
 	Columns     []Column `json:"columns"`
+	Indexes     []Index  `json:"indexes,omitempty"`
 	Description string   `json:"description,omitempty"`
+	// Checks are record level expression rules evaluated by the validator
+	Checks []Check `json:"checks,omitempty"`
+	// Workflow opts the table into the draft, published and archived lifecycle
+	Workflow *Workflow `json:"workflow,omitempty"`
+	// JoinFor names the many-to-many column ("table.column") a generated join table backs
+	JoinFor string `json:"join_for,omitempty"`
 }
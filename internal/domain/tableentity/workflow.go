@@ -0,0 +1,153 @@
+// File: internal/domain/tableentity/workflow.go
+
+package tableentity
+
+import (
+	"fmt"
+
+	"quickflow/internal/domain/user"
+)
+
+// maxStageNameLength matches the stage column of record_publications
+const maxStageNameLength = 63
+
+// Workflow configures the content lifecycle of a table. The table rows are the editable drafts;
+// published snapshots are kept apart and are the only rows anonymous readers see.
+type Workflow struct {
+	Enabled bool `json:"enabled"`
+	// Stages are the editorial review stages of drafts in order. New records start in the
+	// first stage. Without stages records may be published at any time.
+	Stages []Stage `json:"stages,omitempty"`
+	// Transitions list the allowed moves between stages and who may make them
+	Transitions []StageTransition `json:"transitions,omitempty"`
+	// PublishRoles lists who besides admins may publish, unpublish and archive records; empty allows admins only
+	PublishRoles []string `json:"publish_roles,omitempty"`
+}
+
+// Stage is one editorial stage of a draft, e.g. draft, legal_review or approved
+type Stage struct {
+	Name string `json:"name"`
+	// Publishable marks the stages from which a record may be published
+	Publishable bool `json:"publishable,omitempty"`
+}
+
+// StageTransition allows records to move from one stage to another.
+// Roles lists the user roles besides admins allowed to make the move; empty allows admins only.
+type StageTransition struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// HasWorkflow reports whether the table uses the content workflow
+func (t *Table) HasWorkflow() bool {
+	return t.Workflow != nil && t.Workflow.Enabled
+}
+
+// HasStages reports whether drafts go through editorial review stages
+func (w *Workflow) HasStages() bool {
+	return len(w.Stages) > 0
+}
+
+// InitialStage returns the stage new records start in
+func (w *Workflow) InitialStage() string {
+	if !w.HasStages() {
+		return ""
+	}
+	return w.Stages[0].Name
+}
+
+// FindStage returns the stage with the given name
+func (w *Workflow) FindStage(name string) (*Stage, bool) {
+	for i := range w.Stages {
+		if w.Stages[i].Name == name {
+			return &w.Stages[i], true
+		}
+	}
+	return nil, false
+}
+
+// FindTransition returns the transition between two stages
+func (w *Workflow) FindTransition(from, to string) (*StageTransition, bool) {
+	for i := range w.Transitions {
+		if w.Transitions[i].From == from && w.Transitions[i].To == to {
+			return &w.Transitions[i], true
+		}
+	}
+	return nil, false
+}
+
+// RoleAllowed reports whether role is listed in roles. Admins are always allowed;
+// an empty list allows nobody else.
+func RoleAllowed(roles []string, role string) bool {
+	if role == user.RoleAdmin {
+		return true
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateWorkflow checks that stages are unique, transitions connect known stages
+// and only known user roles are referenced
+func ValidateWorkflow(table *Table) error {
+	w := table.Workflow
+	if w == nil {
+		return nil
+	}
+	if err := validateRoles(w.PublishRoles); err != nil {
+		return fmt.Errorf("workflow publish_roles: %v", err)
+	}
+	if !w.HasStages() {
+		if len(w.Transitions) > 0 {
+			return fmt.Errorf("workflow transitions require stages")
+		}
+		return nil
+	}
+
+	stages := make(map[string]bool, len(w.Stages))
+	publishable := false
+	for _, stage := range w.Stages {
+		if stage.Name == "" || len(stage.Name) > maxStageNameLength {
+			return fmt.Errorf("invalid workflow stage name: %q", stage.Name)
+		}
+		if stages[stage.Name] {
+			return fmt.Errorf("duplicate workflow stage: %s", stage.Name)
+		}
+		stages[stage.Name] = true
+		publishable = publishable || stage.Publishable
+	}
+	if !publishable {
+		return fmt.Errorf("workflow needs at least one publishable stage")
+	}
+
+	seen := make(map[[2]string]bool, len(w.Transitions))
+	for _, t := range w.Transitions {
+		if !stages[t.From] || !stages[t.To] {
+			return fmt.Errorf("workflow transition %s -> %s refers to an unknown stage", t.From, t.To)
+		}
+		if t.From == t.To {
+			return fmt.Errorf("workflow transition %s -> %s does not change the stage", t.From, t.To)
+		}
+		if seen[[2]string{t.From, t.To}] {
+			return fmt.Errorf("duplicate workflow transition %s -> %s", t.From, t.To)
+		}
+		seen[[2]string{t.From, t.To}] = true
+		if err := validateRoles(t.Roles); err != nil {
+			return fmt.Errorf("workflow transition %s -> %s: %v", t.From, t.To, err)
+		}
+	}
+	return nil
+}
+
+func validateRoles(roles []string) error {
+	for _, role := range roles {
+		if !user.IsValidRole(role) {
+			return fmt.Errorf("unknown role %q", role)
+		}
+	}
+	return nil
+}
@@ -7,24 +7,39 @@ import (
 	"regexp"
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Roles a user may hold
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// ErrVersionConflict is returned when a user was changed after it was read
+var ErrVersionConflict = errors.New("user was modified by another request")
+
 type User struct {
-	ID              uint      `gorm:"primaryKey" json:"id"`
-	Username        string    `gorm:"not null;unique" json:"username"`
-	FirstName       string    `gorm:"not null" json:"first_name"`
-	LastName        string    `gorm:"not null" json:"last_name"`
-	Email           string    `gorm:"not null;unique" json:"email"`
-	Password        string    `gorm:"not null" json:"-"`
-	IsActive        bool      `gorm:"not null;default:false" json:"is_active"`
-	Role            string    `json:"role"`
-	ProfileImageURL string    `json:"profile_image_url"`
-	PhoneNumber     string    `json:"phone_number"`
-	EmailVerified   bool      `gorm:"not null;default:false" json:"email_verified"`
-	LastLogin       time.Time `json:"last_login"`
-	CreatedAt       time.Time `gorm:"not null" json:"created_at"`
-	UpdatedAt       time.Time `gorm:"not null" json:"updated_at"`
+	ID              uint   `gorm:"primaryKey" json:"id"`
+	Username        string `gorm:"not null;unique" json:"username"`
+	FirstName       string `gorm:"not null" json:"first_name"`
+	LastName        string `gorm:"not null" json:"last_name"`
+	Email           string `gorm:"not null;unique" json:"email"`
+	Password        string `gorm:"not null" json:"-"`
+	IsActive        bool   `gorm:"not null;default:false" json:"is_active"`
+	Role            string `json:"role"`
+	ProfileImageURL string `json:"profile_image_url"`
+	// ProfileImageAssetID is set when the profile image is an uploaded asset
+	ProfileImageAssetID *uuid.UUID `gorm:"type:uuid" json:"profile_image_asset_id,omitempty"`
+	PhoneNumber         string     `json:"phone_number"`
+	EmailVerified       bool       `gorm:"not null;default:false" json:"email_verified"`
+	LastLogin           time.Time  `json:"last_login"`
+	CreatedAt           time.Time  `gorm:"not null" json:"created_at"`
+	UpdatedAt           time.Time  `gorm:"not null" json:"updated_at"`
+	// Version is bumped on every update and exposed as the ETag
+	Version int64 `gorm:"not null;default:1" json:"-"`
 }
 
 func NewUser(username, firstName, lastName, email, password, phoneNumber string) (*User, error) {
@@ -59,7 +74,7 @@ func NewUser(username, firstName, lastName, email, password, phoneNumber string)
 		Email:         email,
 		Password:      hashedPassword,
 		IsActive:      true,
-		Role:          "user",
+		Role:          RoleUser,
 		PhoneNumber:   phoneNumber,
 		EmailVerified: false,
 		CreatedAt:     time.Now(),
@@ -100,6 +115,62 @@ func (u *User) UpdateProfile(firstName, lastName, phoneNumber string) error {
 	return nil
 }
 
+// Profile is the part of a user that can be edited by patch documents
+type Profile struct {
+	Username        string `json:"username"`
+	FirstName       string `json:"first_name"`
+	LastName        string `json:"last_name"`
+	Email           string `json:"email"`
+	PhoneNumber     string `json:"phone_number"`
+	ProfileImageURL string `json:"profile_image_url"`
+}
+
+func (u *User) Profile() Profile {
+	return Profile{
+		Username:        u.Username,
+		FirstName:       u.FirstName,
+		LastName:        u.LastName,
+		Email:           u.Email,
+		PhoneNumber:     u.PhoneNumber,
+		ProfileImageURL: u.ProfileImageURL,
+	}
+}
+
+// ApplyProfile replaces the whole profile. The phone number and profile image
+// are optional and are cleared by an empty string.
+func (u *User) ApplyProfile(p Profile) error {
+	if err := ValidateUsername(p.Username); err != nil {
+		return err
+	}
+	if err := ValidateFirstName(p.FirstName); err != nil {
+		return err
+	}
+	if err := ValidateLastName(p.LastName); err != nil {
+		return err
+	}
+	if err := ValidateEmail(p.Email); err != nil {
+		return err
+	}
+	if p.PhoneNumber != "" {
+		if err := ValidatePhoneNumber(p.PhoneNumber); err != nil {
+			return err
+		}
+	}
+
+	u.Username = p.Username
+	u.FirstName = p.FirstName
+	u.LastName = p.LastName
+	u.Email = p.Email
+	u.PhoneNumber = p.PhoneNumber
+	// URL を書き換えた場合はアセットとの紐付けを外す
+	if p.ProfileImageURL != u.ProfileImageURL {
+		u.ProfileImageAssetID = nil
+	}
+	u.ProfileImageURL = p.ProfileImageURL
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
 func (u *User) UpdateEmailVerification(verified bool) {
 	u.EmailVerified = verified
 	u.UpdatedAt = time.Now()
@@ -110,21 +181,34 @@ func (u *User) UpdateLastLogin() {
 	u.UpdatedAt = time.Now()
 }
 
-func (u *User) SetProfileImageURL(url string) {
+// SetProfileImage makes an uploaded asset the profile image; url is where its content is served
+func (u *User) SetProfileImage(assetID uuid.UUID, url string) {
+	u.ProfileImageAssetID = &assetID
 	u.ProfileImageURL = url
 	u.UpdatedAt = time.Now()
 }
 
 func (u *User) SetRole(role string) error {
-	validRoles := []string{"user", "admin", "moderator"}
-	for _, validRole := range validRoles {
-		if role == validRole {
-			u.Role = role
-			u.UpdatedAt = time.Now()
-			return nil
-		}
+	if !IsValidRole(role) {
+		return errors.New("invalid role")
+	}
+	u.Role = role
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsEditor reports whether role may read drafts and write records of workflow-enabled tables
+func IsEditor(role string) bool {
+	return role == RoleModerator || role == RoleAdmin
+}
+
+// IsValidRole reports whether role is one of the known user roles
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
 	}
-	return errors.New("invalid role")
+	return false
 }
 
 func ValidateUsername(username string) error {
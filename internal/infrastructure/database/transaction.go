@@ -0,0 +1,63 @@
+// File: internal/infrastructure/database/transaction.go
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// TxManager runs functions inside a database transaction carried by the context
+type TxManager struct {
+	db         *gorm.DB
+	savepoints uint64
+}
+
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTransaction runs fn in a transaction. Nested calls join the outer transaction.
+func (m *TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// WithinSavepoint runs fn under a savepoint of the transaction bound to ctx so that
+// a failure of fn undoes only its own work. Without a transaction fn runs as is.
+func (m *TxManager) WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	if !ok {
+		return fn(ctx)
+	}
+
+	name := fmt.Sprintf("sp_%d", atomic.AddUint64(&m.savepoints, 1))
+	if err := tx.SavePoint(name).Error; err != nil {
+		return err
+	}
+	if err := fn(ctx); err != nil {
+		if rollbackErr := tx.RollbackTo(name).Error; rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+	return tx.Exec("RELEASE SAVEPOINT " + name).Error
+}
+
+// Conn returns the transaction bound to ctx, or db when there is none
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,170 @@
+// File: internal/infrastructure/repository/asset_repository.go
+
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"quickflow/internal/domain/asset"
+	"quickflow/internal/infrastructure/database"
+	"quickflow/pkg/errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// foreignKeyViolation is the SQLSTATE of a write that breaks a foreign key
+const foreignKeyViolation = "23503"
+
+// AssetRepository stores the metadata of uploaded assets
+type AssetRepository struct {
+	db *gorm.DB
+}
+
+func NewAssetRepository(db *gorm.DB) *AssetRepository {
+	return &AssetRepository{db: db}
+}
+
+func (r *AssetRepository) Create(ctx context.Context, a *asset.Asset) error {
+	if err := database.Conn(ctx, r.db).Create(a).Error; err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to store asset", err)
+	}
+	return nil
+}
+
+func (r *AssetRepository) Get(ctx context.Context, id uuid.UUID) (*asset.Asset, error) {
+	var a asset.Asset
+	err := database.Conn(ctx, r.db).Where("id = ?", id).First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NewAppError(errors.ErrorTypeNotFound, "Asset not found", nil)
+	}
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to get asset", err)
+	}
+	return &a, nil
+}
+
+// List returns assets newest first, continuing after before when it is set
+func (r *AssetRepository) List(ctx context.Context, before *asset.Asset, limit int) ([]*asset.Asset, error) {
+	assets := []*asset.Asset{}
+	query := database.Conn(ctx, r.db).Order("created_at DESC, id DESC").Limit(limit)
+	if before != nil {
+		query = query.Where("(created_at, id) < (?, ?)", before.CreatedAt, before.ID)
+	}
+	if err := query.Find(&assets).Error; err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to list assets", err)
+	}
+	return assets, nil
+}
+
+// Delete removes the metadata of an asset. Assets still referenced by a user or a record are kept.
+func (r *AssetRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := database.Conn(ctx, r.db).Where("id = ?", id).Delete(&asset.Asset{})
+	if result.Error != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(result.Error, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return errors.NewAppError(errors.ErrorTypeValidation, "Asset is still in use", nil)
+		}
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to delete asset", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewAppError(errors.ErrorTypeNotFound, "Asset not found", nil)
+	}
+	return nil
+}
+
+func (r *AssetRepository) GetDerivative(ctx context.Context, assetID uuid.UUID, variant string) (*asset.Derivative, error) {
+	var d asset.Derivative
+	err := database.Conn(ctx, r.db).Where("asset_id = ? AND variant = ?", assetID, variant).First(&d).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NewAppError(errors.ErrorTypeNotFound, "Derivative not found", nil)
+	}
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to get asset derivative", err)
+	}
+	return &d, nil
+}
+
+// CreateDerivative records a derivative. A derivative stored concurrently by another request
+// is kept; both wrote the same content under the same key. When maxVariants is positive, an
+// asset that already has that many other derivatives gets none; the asset row is locked so
+// that concurrent requests for different variants cannot pass the cap together.
+func (r *AssetRepository) CreateDerivative(ctx context.Context, d *asset.Derivative, maxVariants int) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var locked []uuid.UUID
+		if err := tx.Raw("SELECT id FROM assets WHERE id = ? FOR UPDATE", d.AssetID).Scan(&locked).Error; err != nil {
+			return errors.NewAppError(errors.ErrorTypeInternal, "Failed to store asset derivative", err)
+		}
+		if len(locked) == 0 {
+			return errors.NewAppError(errors.ErrorTypeNotFound, "Asset not found", nil)
+		}
+
+		if maxVariants > 0 {
+			var others int64
+			err := tx.Model(&asset.Derivative{}).
+				Where("asset_id = ? AND variant <> ?", d.AssetID, d.Variant).
+				Count(&others).Error
+			if err != nil {
+				return errors.NewAppError(errors.ErrorTypeInternal, "Failed to count asset derivatives", err)
+			}
+			if others >= int64(maxVariants) {
+				return errors.NewAppError(
+					errors.ErrorTypeValidation,
+					fmt.Sprintf("Asset already has the maximum of %d image variants", maxVariants),
+					nil,
+				)
+			}
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(d).Error; err != nil {
+			return errors.NewAppError(errors.ErrorTypeInternal, "Failed to store asset derivative", err)
+		}
+		return nil
+	})
+}
+
+// CountDerivatives returns the number of derivatives of an asset
+func (r *AssetRepository) CountDerivatives(ctx context.Context, assetID uuid.UUID) (int64, error) {
+	var count int64
+	if err := database.Conn(ctx, r.db).Model(&asset.Derivative{}).Where("asset_id = ?", assetID).Count(&count).Error; err != nil {
+		return 0, errors.NewAppError(errors.ErrorTypeInternal, "Failed to count asset derivatives", err)
+	}
+	return count, nil
+}
+
+// ListDerivatives returns the derivatives of an asset
+func (r *AssetRepository) ListDerivatives(ctx context.Context, assetID uuid.UUID) ([]*asset.Derivative, error) {
+	derivatives := []*asset.Derivative{}
+	if err := database.Conn(ctx, r.db).Where("asset_id = ?", assetID).Find(&derivatives).Error; err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to list asset derivatives", err)
+	}
+	return derivatives, nil
+}
+
+// recordReferencePath matches a record whose top-level fields hold the asset id. Snapshots and
+// revisions keep the columns of their time, so any field is checked rather than the current
+// asset columns.
+const recordReferencePath = `$.* ? (@ == $id)`
+
+// IsReferencedByRecords reports whether a published snapshot or a revision of a record still
+// holds the asset. Foreign keys only protect the live rows of user tables.
+func (r *AssetRepository) IsReferencedByRecords(ctx context.Context, assetID uuid.UUID) (bool, error) {
+	var referenced bool
+	err := database.Conn(ctx, r.db).Raw(
+		`SELECT EXISTS (
+			SELECT 1 FROM record_publications
+			WHERE data IS NOT NULL AND jsonb_path_exists(data, ?::jsonpath, jsonb_build_object('id', ?::text))
+		) OR EXISTS (
+			SELECT 1 FROM record_revisions
+			WHERE jsonb_path_exists(data, ?::jsonpath, jsonb_build_object('id', ?::text))
+		)`,
+		recordReferencePath, assetID.String(), recordReferencePath, assetID.String(),
+	).Scan(&referenced).Error
+	if err != nil {
+		return false, errors.NewAppError(errors.ErrorTypeInternal, "Failed to check asset references", err)
+	}
+	return referenced, nil
+}
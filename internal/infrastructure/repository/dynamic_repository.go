@@ -1 +1,468 @@
+// File: internal/infrastructure/repository/dynamic_repository.go
+
 package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/internal/infrastructure/database"
+	"quickflow/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+type DynamicRepository struct {
+	db *gorm.DB
+	// published reads workflow-enabled tables from their published snapshots
+	published bool
+}
+
+func NewDynamicRepository(db *gorm.DB) *DynamicRepository {
+	return &DynamicRepository{db: db}
+}
+
+// NewPublishedRepository returns a repository whose reads of workflow-enabled tables only see
+// published snapshots. Tables without a workflow are read as they are.
+func NewPublishedRepository(db *gorm.DB) *DynamicRepository {
+	return &DynamicRepository{db: db, published: true}
+}
+
+func (r *DynamicRepository) List(ctx context.Context, table *tableentity.Table, query record.Query) ([]record.Record, error) {
+	stmt := fmt.Sprintf("SELECT %s FROM %s", selectList(table, query.Fields), r.source(table, ""))
+
+	where, args := buildFilterSQL(table, query.Filters)
+	if query.After != nil {
+		keyset, keysetArgs := buildKeysetSQL(query.Sort, query.After)
+		where = joinConditions(where, keyset)
+		args = append(args, keysetArgs...)
+	}
+	if where != "" {
+		stmt += " WHERE " + where
+	}
+
+	stmt += " ORDER BY " + buildOrderBySQL(query.Sort) + " LIMIT ?"
+	args = append(args, query.Limit)
+
+	rows, err := database.Conn(ctx, r.db).Raw(stmt, args...).Rows()
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to list records", err)
+	}
+	defer rows.Close()
+
+	return scanRecords(rows)
+}
+
+// Count returns the number of records matching the filters
+func (r *DynamicRepository) Count(ctx context.Context, table *tableentity.Table, filters []record.Filter) (int64, error) {
+	stmt := "SELECT COUNT(*) FROM " + r.source(table, "")
+	where, args := buildFilterSQL(table, filters)
+	if where != "" {
+		stmt += " WHERE " + where
+	}
+
+	var count int64
+	if err := database.Conn(ctx, r.db).Raw(stmt, args...).Scan(&count).Error; err != nil {
+		return 0, errors.NewAppError(errors.ErrorTypeInternal, "Failed to count records", err)
+	}
+	return count, nil
+}
+
+// Get reads a single record with its version; fields restricts the selected columns and nil
+// selects every column
+func (r *DynamicRepository) Get(ctx context.Context, table *tableentity.Table, key record.Key, fields []string) (record.Record, error) {
+	where, args := keyCondition(table, key)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s", versionedSelectList(table, fields), r.source(table, ""), where)
+
+	rows, err := database.Conn(ctx, r.db).Raw(query, args...).Rows()
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to get record", err)
+	}
+	defer rows.Close()
+
+	return scanSingleRecord(rows)
+}
+
+// ListByValues reads the records whose column matches any of the values
+func (r *DynamicRepository) ListByValues(ctx context.Context, table *tableentity.Table, column string, values []interface{}) ([]record.Record, error) {
+	placeholders := make([]string, len(values))
+	for i := range values {
+		placeholders[i] = "?"
+	}
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s IN (%s)",
+		selectList(table, nil),
+		r.source(table, ""),
+		quoteIdentifier(column),
+		strings.Join(placeholders, ", "),
+	)
+
+	rows, err := database.Conn(ctx, r.db).Raw(query, values...).Rows()
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to list related records", err)
+	}
+	defer rows.Close()
+
+	return scanRecords(rows)
+}
+
+// ListRelated reads the target records linked to any of the source keys through a join table.
+// The first join table column refers to the source and the second to the target.
+func (r *DynamicRepository) ListRelated(ctx context.Context, target *tableentity.Table, join *tableentity.Table, sources []interface{}) ([]record.Related, error) {
+	source := join.Columns[0]
+	link := join.Columns[1]
+
+	var columns []string
+	for _, col := range target.Columns {
+		if col.IsVirtual() {
+			continue
+		}
+		columns = append(columns, selectColumn(col, "t."))
+	}
+
+	placeholders := make([]string, len(sources))
+	for i := range sources {
+		placeholders[i] = "?"
+	}
+	query := fmt.Sprintf(
+		"SELECT j.%s AS %s, %s FROM %s j JOIN %s ON t.%s = j.%s WHERE j.%s IN (%s)",
+		quoteIdentifier(source.Name),
+		quoteIdentifier(relatedSourceColumn),
+		strings.Join(columns, ", "),
+		quoteIdentifier(join.Name),
+		r.source(target, "t"),
+		quoteIdentifier(link.Relation.Column),
+		quoteIdentifier(link.Name),
+		quoteIdentifier(source.Name),
+		strings.Join(placeholders, ", "),
+	)
+
+	rows, err := database.Conn(ctx, r.db).Raw(query, sources...).Rows()
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to list related records", err)
+	}
+	defer rows.Close()
+
+	records, err := scanRecords(rows)
+	if err != nil {
+		return nil, err
+	}
+	related := make([]record.Related, len(records))
+	for i, rec := range records {
+		related[i] = record.Related{Source: rec[relatedSourceColumn], Record: rec}
+		delete(rec, relatedSourceColumn)
+	}
+	return related, nil
+}
+
+func (r *DynamicRepository) Create(ctx context.Context, table *tableentity.Table, values record.Record) (record.Record, error) {
+	var columns, placeholders []string
+	var args []interface{}
+	for _, col := range table.Columns {
+		value, ok := values[col.Name]
+		if !ok {
+			continue
+		}
+		columns = append(columns, quoteIdentifier(col.Name))
+		placeholders = append(placeholders, "?")
+		args = append(args, value)
+	}
+
+	var query string
+	if len(columns) == 0 {
+		query = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES RETURNING %s", quoteIdentifier(table.Name), versionedSelectList(table, nil))
+	} else {
+		query = fmt.Sprintf(
+			"INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
+			quoteIdentifier(table.Name),
+			strings.Join(columns, ", "),
+			strings.Join(placeholders, ", "),
+			versionedSelectList(table, nil),
+		)
+	}
+
+	rows, err := database.Conn(ctx, r.db).Raw(query, args...).Rows()
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to create record", err)
+	}
+	defer rows.Close()
+
+	return scanSingleRecord(rows)
+}
+
+// Upsert inserts a record or, when it conflicts on the given unique columns, updates the
+// existing row with the supplied values
+func (r *DynamicRepository) Upsert(ctx context.Context, table *tableentity.Table, values record.Record, conflict []string) (record.Record, error) {
+	inConflict := make(map[string]bool, len(conflict))
+	for _, name := range conflict {
+		inConflict[name] = true
+	}
+
+	var columns, placeholders, assignments []string
+	var args []interface{}
+	for _, col := range table.Columns {
+		value, ok := values[col.Name]
+		if !ok {
+			continue
+		}
+		name := quoteIdentifier(col.Name)
+		columns = append(columns, name)
+		placeholders = append(placeholders, "?")
+		args = append(args, value)
+		if !inConflict[col.Name] {
+			assignments = append(assignments, name+" = EXCLUDED."+name)
+		}
+	}
+	version := quoteIdentifier(tableentity.VersionColumn)
+	assignments = append(assignments, fmt.Sprintf("%s = %s.%s + 1", version, quoteIdentifier(table.Name), version))
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s RETURNING %s",
+		quoteIdentifier(table.Name),
+		strings.Join(columns, ", "),
+		strings.Join(placeholders, ", "),
+		strings.Join(quoteIdentifiers(conflict), ", "),
+		strings.Join(assignments, ", "),
+		versionedSelectList(table, nil),
+	)
+
+	rows, err := database.Conn(ctx, r.db).Raw(query, args...).Rows()
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to upsert record", err)
+	}
+	defer rows.Close()
+
+	return scanSingleRecord(rows)
+}
+
+// Update writes the given columns and bumps the record version. When expected is not empty
+// the record is only updated if its current version is one of them.
+func (r *DynamicRepository) Update(ctx context.Context, table *tableentity.Table, key record.Key, values record.Record, expected []int64) (record.Record, error) {
+	var assignments []string
+	var args []interface{}
+	for _, col := range table.Columns {
+		value, ok := values[col.Name]
+		if !ok {
+			continue
+		}
+		assignments = append(assignments, quoteIdentifier(col.Name)+" = ?")
+		args = append(args, value)
+	}
+	version := quoteIdentifier(tableentity.VersionColumn)
+	assignments = append(assignments, version+" = "+version+" + 1")
+
+	where, keyArgs := keyCondition(table, key)
+	args = append(args, keyArgs...)
+	if len(expected) > 0 {
+		where += " AND " + version + " IN ?"
+		args = append(args, expected)
+	}
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s RETURNING %s",
+		quoteIdentifier(table.Name),
+		strings.Join(assignments, ", "),
+		where,
+		versionedSelectList(table, nil),
+	)
+
+	rows, err := database.Conn(ctx, r.db).Raw(query, args...).Rows()
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to update record", err)
+	}
+	defer rows.Close()
+
+	rec, err := scanSingleRecord(rows)
+	var appErr *errors.AppError
+	if len(expected) > 0 && errors.As(err, &appErr) && appErr.Type == errors.ErrorTypeNotFound {
+		// 行が存在するならバージョン不一致
+		if _, getErr := r.Get(ctx, table, key, primaryKeyNames(table)); getErr == nil {
+			return nil, errors.NewAppError(errors.ErrorTypePreconditionFailed, "Record was modified by another request", nil)
+		}
+	}
+	return rec, err
+}
+
+func (r *DynamicRepository) Delete(ctx context.Context, table *tableentity.Table, key record.Key) error {
+	where, args := keyCondition(table, key)
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", quoteIdentifier(table.Name), where)
+
+	result := database.Conn(ctx, r.db).Exec(query, args...)
+	if result.Error != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to delete record", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewAppError(errors.ErrorTypeNotFound, "Record not found", nil)
+	}
+	return nil
+}
+
+// source renders the FROM item of a read. Published reads of workflow-enabled tables rebuild
+// typed rows of the table from the published snapshots, so filters, sorting and keyset
+// pagination work on them unchanged. AlterTable rewrites the snapshots along with the columns.
+func (r *DynamicRepository) source(table *tableentity.Table, alias string) string {
+	if !r.published || !table.HasWorkflow() {
+		if alias == "" {
+			return quoteIdentifier(table.Name)
+		}
+		return quoteIdentifier(table.Name) + " " + alias
+	}
+
+	if alias == "" {
+		alias = quoteIdentifier(table.Name)
+	}
+	return fmt.Sprintf(
+		"(SELECT s.* FROM record_publications p, jsonb_populate_record(NULL::%s, p.data) s WHERE p.table_name = %s AND p.status = %s) AS %s",
+		quoteIdentifier(table.Name),
+		quoteLiteral(table.Name),
+		quoteLiteral(string(record.StatusPublished)),
+		alias,
+	)
+}
+
+func keyCondition(table *tableentity.Table, key record.Key) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, name := range primaryKeyNames(table) {
+		conditions = append(conditions, quoteIdentifier(name)+" = ?")
+		args = append(args, key[name])
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// relatedSourceColumn carries the source key of a many-to-many row alongside the target columns
+const relatedSourceColumn = "_source"
+
+// selectList lists the physical columns of the table, restricted to fields when it is not nil
+func selectList(table *tableentity.Table, fields []string) string {
+	var wanted map[string]bool
+	if fields != nil {
+		wanted = make(map[string]bool, len(fields))
+		for _, name := range fields {
+			wanted[name] = true
+		}
+	}
+
+	var columns []string
+	for _, col := range table.Columns {
+		if col.IsVirtual() || (wanted != nil && !wanted[col.Name]) {
+			continue
+		}
+		columns = append(columns, selectColumn(col, ""))
+	}
+	return strings.Join(columns, ", ")
+}
+
+// versionedSelectList is selectList followed by the version column
+func versionedSelectList(table *tableentity.Table, fields []string) string {
+	version := quoteIdentifier(tableentity.VersionColumn)
+	if list := selectList(table, fields); list != "" {
+		return list + ", " + version
+	}
+	return version
+}
+
+// selectColumn renders one select list entry.
+// Arrays are read back as JSON so they keep their element types.
+func selectColumn(col tableentity.Column, qualifier string) string {
+	name := quoteIdentifier(col.Name)
+	if col.Type == tableentity.TypeARRAY {
+		return fmt.Sprintf("to_json(%s%s) AS %s", qualifier, name, name)
+	}
+	if qualifier != "" {
+		return qualifier + name
+	}
+	return name
+}
+
+func primaryKeyNames(table *tableentity.Table) []string {
+	var names []string
+	for _, col := range table.Columns {
+		if col.PrimaryKey {
+			names = append(names, col.Name)
+		}
+	}
+	return names
+}
+
+func scanRecords(rows *sql.Rows) ([]record.Record, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to get column names", err)
+	}
+
+	records := []record.Record{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		scanArgs := make([]interface{}, len(columns))
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to scan row", err)
+		}
+
+		rec := make(record.Record, len(columns))
+		for i, name := range columns {
+			rec[name] = values[i]
+		}
+		records = append(records, rec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Error during row iteration", err)
+	}
+	return records, nil
+}
+
+func scanSingleRecord(rows *sql.Rows) (record.Record, error) {
+	records, err := scanRecords(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.NewAppError(errors.ErrorTypeNotFound, "Record not found", nil)
+	}
+	return records[0], nil
+}
+
+// quoteIdentifier quotes a table or column name for use in generated SQL
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteLiteral renders a scalar value as an escaped SQL literal
+func quoteLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	default:
+		return "'" + strings.ReplaceAll(fmt.Sprint(v), "'", "''") + "'"
+	}
+}
+
+func quoteIdentifiers(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdentifier(name)
+	}
+	return quoted
+}
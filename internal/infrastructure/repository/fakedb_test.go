@@ -0,0 +1,160 @@
+// File: internal/infrastructure/repository/fakedb_test.go
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeStatement is a statement the fake database received
+type fakeStatement struct {
+	SQL  string
+	InTx bool
+}
+
+// fakeResult answers queries whose SQL contains Match
+type fakeResult struct {
+	Match   string
+	Columns []string
+	Rows    [][]driver.Value
+}
+
+// fakeDB records the statements it receives and answers queries with canned rows.
+// Queries without a canned answer return no rows.
+type fakeDB struct {
+	mu      sync.Mutex
+	results []fakeResult
+	log     []fakeStatement
+}
+
+// newFakeGorm opens gorm on a fake database
+func newFakeGorm(t *testing.T, results ...fakeResult) (*gorm.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{results: results}
+	sqlDB := sql.OpenDB(fakeConnector{fake})
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return db, fake
+}
+
+// statements returns the statements received so far, with BEGIN, COMMIT and ROLLBACK in place
+func (f *fakeDB) statements() []fakeStatement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]fakeStatement(nil), f.log...)
+}
+
+func (f *fakeDB) record(query string, inTx bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, fakeStatement{SQL: query, InTx: inTx})
+}
+
+func (f *fakeDB) result(query string) fakeResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.results {
+		if strings.Contains(query, r.Match) {
+			return r
+		}
+	}
+	return fakeResult{Columns: []string{"result"}}
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{db: c.db}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fake driver: use the connector")
+}
+
+type fakeConn struct {
+	db   *fakeDB
+	inTx bool
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fake driver: prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.db.record("BEGIN", true)
+	c.inTx = true
+	return fakeTx{c}, nil
+}
+
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query, c.inTx)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query, c.inTx)
+	r := c.db.result(query)
+	return &fakeRows{columns: r.Columns, rows: r.Rows}, nil
+}
+
+type fakeTx struct{ conn *fakeConn }
+
+func (t fakeTx) Commit() error {
+	t.conn.db.record("COMMIT", true)
+	t.conn.inTx = false
+	return nil
+}
+
+func (t fakeTx) Rollback() error {
+	t.conn.db.record("ROLLBACK", true)
+	t.conn.inTx = false
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
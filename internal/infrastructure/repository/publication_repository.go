@@ -0,0 +1,226 @@
+// File: internal/infrastructure/repository/publication_repository.go
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/internal/infrastructure/database"
+	"quickflow/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// PublicationRepository stores the workflow state and published snapshot of records
+type PublicationRepository struct {
+	db *gorm.DB
+}
+
+func NewPublicationRepository(db *gorm.DB) *PublicationRepository {
+	return &PublicationRepository{db: db}
+}
+
+// Publish copies the current draft row into the published snapshot
+func (r *PublicationRepository) Publish(ctx context.Context, table *tableentity.Table, key record.Key, recordKey string, userID uint) error {
+	where, args := keyCondition(table, key)
+	// to_jsonb と jsonb_populate_record は対になっており、スナップショットは型を失わずに行へ戻せる
+	query := fmt.Sprintf(`INSERT INTO record_publications (table_name, record_key, status, data, published_at, updated_by, updated_at)
+SELECT ?, ?, ?, to_jsonb(t), now(), ?, now() FROM %s t WHERE %s
+ON CONFLICT (table_name, record_key) DO UPDATE SET
+status = EXCLUDED.status, data = EXCLUDED.data, published_at = EXCLUDED.published_at,
+updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`,
+		quoteIdentifier(table.Name),
+		where,
+	)
+
+	params := append([]interface{}{table.Name, recordKey, string(record.StatusPublished), userRef(userID)}, args...)
+	result := database.Conn(ctx, r.db).Exec(query, params...)
+	if result.Error != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to publish record", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewAppError(errors.ErrorTypeNotFound, "Record not found", nil)
+	}
+	return nil
+}
+
+// SetStatus moves a record to draft or archived. Drafts drop their snapshot;
+// archived records keep it without serving it.
+func (r *PublicationRepository) SetStatus(ctx context.Context, tableName, recordKey string, status record.Status, userID uint) error {
+	kept := "data = record_publications.data, published_at = record_publications.published_at"
+	if status == record.StatusDraft {
+		kept = "data = NULL, published_at = NULL"
+	}
+	query := fmt.Sprintf(`INSERT INTO record_publications (table_name, record_key, status, updated_by, updated_at)
+VALUES (?, ?, ?, ?, now())
+ON CONFLICT (table_name, record_key) DO UPDATE SET
+status = EXCLUDED.status, %s,
+updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`, kept)
+
+	if err := database.Conn(ctx, r.db).Exec(query, tableName, recordKey, string(status), userRef(userID)).Error; err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to change record status", err)
+	}
+	return nil
+}
+
+// GetState returns the workflow state of a record; records without a stored state are drafts
+func (r *PublicationRepository) GetState(ctx context.Context, tableName, recordKey string) (*record.State, error) {
+	var row struct {
+		Status           string
+		Stage            *string
+		PublishedAt      *time.Time
+		UpdatedBy        *uint
+		UpdatedAt        *time.Time
+		PublishAt        *time.Time
+		UnpublishAt      *time.Time
+		ScheduleAttempts int
+		ScheduleError    *string
+	}
+	result := database.Conn(ctx, r.db).Raw(
+		`SELECT status, stage, published_at, updated_by, updated_at, publish_at, unpublish_at, schedule_attempts, schedule_error
+FROM record_publications WHERE table_name = ? AND record_key = ?`,
+		tableName, recordKey,
+	).Scan(&row)
+	if result.Error != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to get record status", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return &record.State{Status: record.StatusDraft}, nil
+	}
+
+	state := &record.State{
+		Status:      record.Status(row.Status),
+		PublishedAt: row.PublishedAt,
+		UpdatedBy:   row.UpdatedBy,
+		UpdatedAt:   row.UpdatedAt,
+		Schedule: record.Schedule{
+			PublishAt:   row.PublishAt,
+			UnpublishAt: row.UnpublishAt,
+		},
+		ScheduleAttempts: row.ScheduleAttempts,
+	}
+	if row.Stage != nil {
+		state.Stage = *row.Stage
+	}
+	if row.ScheduleError != nil {
+		state.ScheduleError = *row.ScheduleError
+	}
+	return state, nil
+}
+
+// SetStage moves the draft of a record to an editorial stage. A schedule held back by a failed
+// run becomes due again, so that a publish waiting for approval fires on the next run.
+func (r *PublicationRepository) SetStage(ctx context.Context, tableName, recordKey, stage string, userID uint) error {
+	err := database.Conn(ctx, r.db).Exec(`INSERT INTO record_publications (table_name, record_key, status, stage, updated_by, updated_at)
+VALUES (?, ?, ?, ?, ?, now())
+ON CONFLICT (table_name, record_key) DO UPDATE SET
+stage = EXCLUDED.stage, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at, schedule_retry_at = NULL`,
+		tableName, recordKey, string(record.StatusDraft), stage, userRef(userID),
+	).Error
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to change record stage", err)
+	}
+	return nil
+}
+
+// SetSchedule replaces the publish and unpublish times of a record and forgets earlier failed runs
+func (r *PublicationRepository) SetSchedule(ctx context.Context, tableName, recordKey string, schedule record.Schedule, userID uint) error {
+	err := database.Conn(ctx, r.db).Exec(`INSERT INTO record_publications (table_name, record_key, status, publish_at, unpublish_at, scheduled_by, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, now())
+ON CONFLICT (table_name, record_key) DO UPDATE SET
+publish_at = EXCLUDED.publish_at, unpublish_at = EXCLUDED.unpublish_at, scheduled_by = EXCLUDED.scheduled_by,
+schedule_attempts = 0, schedule_error = NULL, schedule_retry_at = NULL`,
+		tableName, recordKey, string(record.StatusDraft), schedule.PublishAt, schedule.UnpublishAt, userRef(userID),
+	).Error
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to schedule record", err)
+	}
+	return nil
+}
+
+// ClaimDue locks up to limit schedules that are due at now. It must run inside a transaction;
+// rows locked by another instance are skipped so that each schedule fires once. Schedules that
+// failed wait for their retry time and come after those that never failed.
+func (r *PublicationRepository) ClaimDue(ctx context.Context, now time.Time, limit int) ([]record.DueSchedule, error) {
+	var rows []struct {
+		TableName        string
+		RecordKey        string
+		ScheduledBy      *uint
+		PublishAt        *time.Time
+		UnpublishAt      *time.Time
+		ScheduleAttempts int
+	}
+	err := database.Conn(ctx, r.db).Raw(`SELECT table_name, record_key, scheduled_by, publish_at, unpublish_at, schedule_attempts FROM record_publications
+WHERE (publish_at <= ? OR unpublish_at <= ?) AND (schedule_retry_at IS NULL OR schedule_retry_at <= ?)
+ORDER BY schedule_attempts, LEAST(COALESCE(publish_at, unpublish_at), COALESCE(unpublish_at, publish_at))
+LIMIT ? FOR UPDATE SKIP LOCKED`,
+		now, now, now, limit,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to claim due schedules", err)
+	}
+
+	due := make([]record.DueSchedule, len(rows))
+	for i, row := range rows {
+		due[i] = record.DueSchedule{
+			TableName:   row.TableName,
+			RecordKey:   row.RecordKey,
+			ScheduledBy: row.ScheduledBy,
+			Schedule:    record.Schedule{PublishAt: row.PublishAt, UnpublishAt: row.UnpublishAt},
+			Attempts:    row.ScheduleAttempts,
+		}
+	}
+	return due, nil
+}
+
+// ClearDue removes the schedule times of a record that have passed at now, along with its failed runs
+func (r *PublicationRepository) ClearDue(ctx context.Context, tableName, recordKey string, now time.Time) error {
+	err := database.Conn(ctx, r.db).Exec(`UPDATE record_publications SET
+publish_at = CASE WHEN publish_at <= ? THEN NULL ELSE publish_at END,
+unpublish_at = CASE WHEN unpublish_at <= ? THEN NULL ELSE unpublish_at END,
+schedule_attempts = 0, schedule_error = NULL, schedule_retry_at = NULL
+WHERE table_name = ? AND record_key = ?`,
+		now, now, tableName, recordKey,
+	).Error
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to clear due schedule", err)
+	}
+	return nil
+}
+
+// FailDue records a failed run of a due schedule and holds it back until retryAt
+func (r *PublicationRepository) FailDue(ctx context.Context, tableName, recordKey, message string, retryAt time.Time) error {
+	err := database.Conn(ctx, r.db).Exec(`UPDATE record_publications SET
+schedule_attempts = schedule_attempts + 1, schedule_error = ?, schedule_retry_at = ?
+WHERE table_name = ? AND record_key = ?`,
+		message, retryAt, tableName, recordKey,
+	).Error
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to record schedule failure", err)
+	}
+	return nil
+}
+
+// userRef stores transitions made without a user, such as scheduled ones, as NULL
+func userRef(userID uint) interface{} {
+	if userID == 0 {
+		return nil
+	}
+	return userID
+}
+
+// Delete removes the state and snapshot of a deleted record
+func (r *PublicationRepository) Delete(ctx context.Context, tableName, recordKey string) error {
+	err := database.Conn(ctx, r.db).Exec(
+		"DELETE FROM record_publications WHERE table_name = ? AND record_key = ?",
+		tableName, recordKey,
+	).Error
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to delete record publication", err)
+	}
+	return nil
+}
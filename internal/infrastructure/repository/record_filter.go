@@ -0,0 +1,220 @@
+// File: internal/infrastructure/repository/record_filter.go
+
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+)
+
+// likeEscaper escapes LIKE wildcards so contains matches the value literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// buildFilterSQL compiles bound filters into a parameterized condition joined with AND.
+// Only identifiers from the table definition are written into the SQL; every value is a parameter.
+func buildFilterSQL(table *tableentity.Table, filters []record.Filter) (string, []interface{}) {
+	conditions, args := buildConditions(table, filters)
+	return strings.Join(conditions, " AND "), args
+}
+
+func buildConditions(table *tableentity.Table, filters []record.Filter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	for _, filter := range filters {
+		col, _ := table.Column(filter.Column)
+		target := quoteIdentifier(filter.Column)
+		var targetArgs []interface{}
+		var pathPlaceholders string
+		if filter.Path != nil {
+			placeholders := make([]string, len(filter.Path))
+			for i, key := range filter.Path {
+				placeholders[i] = "?"
+				targetArgs = append(targetArgs, key)
+			}
+			pathPlaceholders = strings.Join(placeholders, ", ")
+			target = fmt.Sprintf("jsonb_extract_path_text(%s, %s)", target, pathPlaceholders)
+		}
+
+		var condition string
+		var valueArgs []interface{}
+		switch filter.Operator {
+		case record.FilterIsNull:
+			if isNull, _ := filter.Value.(bool); isNull {
+				condition = target + " IS NULL"
+			} else {
+				condition = target + " IS NOT NULL"
+			}
+
+		case record.FilterEqual:
+			condition = target + " = ?"
+			valueArgs = append(valueArgs, filter.Value)
+
+		case record.FilterNotEqual:
+			// NULL も「等しくない」側に含める
+			condition = target + " IS DISTINCT FROM ?"
+			valueArgs = append(valueArgs, filter.Value)
+
+		case record.FilterGreater, record.FilterGreaterEqual, record.FilterLess, record.FilterLessEqual:
+			if _, numeric := filter.Value.(float64); numeric && filter.Path != nil {
+				// 数値以外の値を持つ行でキャストが失敗しないよう、数値のときだけ変換する
+				target = fmt.Sprintf("CASE WHEN jsonb_typeof(jsonb_extract_path(%s, %s)) = 'number' THEN (%s)::numeric END",
+					quoteIdentifier(filter.Column), pathPlaceholders, target)
+				targetArgs = append(targetArgs, targetArgs...)
+			}
+			condition = fmt.Sprintf("%s %s ?", target, comparisonOperators[filter.Operator])
+			valueArgs = append(valueArgs, filter.Value)
+
+		case record.FilterIn:
+			values, _ := filter.Value.([]interface{})
+			placeholders := make([]string, len(values))
+			for i := range values {
+				placeholders[i] = "?"
+			}
+			condition = fmt.Sprintf("%s IN (%s)", target, strings.Join(placeholders, ", "))
+			valueArgs = append(valueArgs, values...)
+
+		case record.FilterContains:
+			switch {
+			// @> は GIN インデックスで絞り込める
+			case filter.Path == nil && col.Type == tableentity.TypeARRAY:
+				values, _ := filter.Value.([]interface{})
+				placeholders := make([]string, len(values))
+				for i := range values {
+					placeholders[i] = "?"
+				}
+				condition = fmt.Sprintf("%s @> ARRAY[%s]::%s", target, strings.Join(placeholders, ", "), columnTypeSQL(col))
+				valueArgs = append(valueArgs, values...)
+			case filter.Path == nil && col.Type == tableentity.TypeJSON:
+				condition = target + " @> ?::jsonb"
+				valueArgs = append(valueArgs, filter.Value)
+			default:
+				condition = target + ` ILIKE ? ESCAPE '\'`
+				valueArgs = append(valueArgs, "%"+likeEscaper.Replace(fmt.Sprint(filter.Value))+"%")
+			}
+
+		case record.FilterMissing:
+			// 空文字の翻訳も未翻訳として扱う
+			condition = fmt.Sprintf("(%s IS NOT NULL AND COALESCE(%s->>?, '') = '')", target, target)
+			valueArgs = append(valueArgs, filter.Value)
+
+		case record.FilterAny:
+			alternatives, _ := filter.Value.([]record.Filter)
+			parts, partArgs := buildConditions(table, alternatives)
+			if len(parts) == 0 {
+				continue
+			}
+			condition = "(" + strings.Join(parts, " OR ") + ")"
+			valueArgs = append(valueArgs, partArgs...)
+
+		default:
+			continue
+		}
+
+		conditions = append(conditions, condition)
+		args = append(args, targetArgs...)
+		args = append(args, valueArgs...)
+	}
+
+	return conditions, args
+}
+
+var comparisonOperators = map[record.FilterOperator]string{
+	record.FilterGreater:      ">",
+	record.FilterGreaterEqual: ">=",
+	record.FilterLess:         "<",
+	record.FilterLessEqual:    "<=",
+}
+
+// buildKeysetSQL selects the records that come after the given sort key values.
+// Keys of one direction without NULLs compare as a row, (a, b) > (?, ?), which an index on
+// the keys can serve. Otherwise keys (a, b) produce (a > ?) OR (a = ? AND b > ?), flipping >
+// for descending keys; NULLs sort last, so they follow every value and nothing follows them.
+func buildKeysetSQL(keys []record.SortKey, after []interface{}) (string, []interface{}) {
+	if len(keys) > 0 && rowComparable(keys) {
+		columns := make([]string, len(keys))
+		placeholders := make([]string, len(keys))
+		for i, key := range keys {
+			columns[i] = quoteIdentifier(key.Column)
+			placeholders[i] = "?"
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), keysetOperator(keys[0]), strings.Join(placeholders, ", ")), after
+	}
+
+	var branches []string
+	var args []interface{}
+
+	for i, key := range keys {
+		// NULL の後に続く値はないため、この分岐は常に偽になる
+		if after[i] == nil {
+			continue
+		}
+		var parts []string
+		var partArgs []interface{}
+		for j := 0; j < i; j++ {
+			if after[j] == nil {
+				parts = append(parts, quoteIdentifier(keys[j].Column)+" IS NULL")
+				continue
+			}
+			parts = append(parts, quoteIdentifier(keys[j].Column)+" = ?")
+			partArgs = append(partArgs, after[j])
+		}
+		column := quoteIdentifier(key.Column)
+		comparison := fmt.Sprintf("%s %s ?", column, keysetOperator(key))
+		if key.Nullable {
+			comparison = fmt.Sprintf("(%s OR %s IS NULL)", comparison, column)
+		}
+		parts = append(parts, comparison)
+		partArgs = append(partArgs, after[i])
+		branches = append(branches, "("+strings.Join(parts, " AND ")+")")
+		args = append(args, partArgs...)
+	}
+	if len(branches) == 0 {
+		return "FALSE", nil
+	}
+	return "(" + strings.Join(branches, " OR ") + ")", args
+}
+
+// rowComparable reports whether the keys may be compared as a single row value
+func rowComparable(keys []record.SortKey) bool {
+	for _, key := range keys {
+		if key.Nullable || key.Descending != keys[0].Descending {
+			return false
+		}
+	}
+	return true
+}
+
+func keysetOperator(key record.SortKey) string {
+	if key.Descending {
+		return "<"
+	}
+	return ">"
+}
+
+func buildOrderBySQL(keys []record.SortKey) string {
+	terms := make([]string, len(keys))
+	for i, key := range keys {
+		terms[i] = quoteIdentifier(key.Column)
+		if key.Descending {
+			terms[i] += " DESC"
+		}
+		if key.Nullable {
+			terms[i] += " NULLS LAST"
+		}
+	}
+	return strings.Join(terms, ", ")
+}
+
+func joinConditions(conditions ...string) string {
+	var nonEmpty []string
+	for _, condition := range conditions {
+		if condition != "" {
+			nonEmpty = append(nonEmpty, condition)
+		}
+	}
+	return strings.Join(nonEmpty, " AND ")
+}
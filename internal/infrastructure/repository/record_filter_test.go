@@ -0,0 +1,233 @@
+// File: internal/infrastructure/repository/record_filter_test.go
+
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/domain/tableentity"
+)
+
+var filterTable = &tableentity.Table{
+	Name: "articles",
+	Columns: []tableentity.Column{
+		{Name: "id", Type: tableentity.TypeBIGINT, PrimaryKey: true},
+		{Name: "title", Type: tableentity.TypeTEXT},
+		{Name: "views", Type: tableentity.TypeINT},
+		{Name: "tags", Type: tableentity.TypeARRAY, ElementType: tableentity.TypeTEXT},
+		{Name: "meta", Type: tableentity.TypeJSON},
+		{Name: "name", Type: tableentity.TypeTEXT, Localized: true},
+	},
+}
+
+func TestBuildFilterSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		filters  []record.Filter
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:    "no filters",
+			wantSQL: "",
+		},
+		{
+			name:     "equal",
+			filters:  []record.Filter{{Column: "title", Operator: record.FilterEqual, Value: "hello"}},
+			wantSQL:  `"title" = ?`,
+			wantArgs: []interface{}{"hello"},
+		},
+		{
+			name:     "not equal includes nulls",
+			filters:  []record.Filter{{Column: "title", Operator: record.FilterNotEqual, Value: "hello"}},
+			wantSQL:  `"title" IS DISTINCT FROM ?`,
+			wantArgs: []interface{}{"hello"},
+		},
+		{
+			name: "comparisons joined with AND",
+			filters: []record.Filter{
+				{Column: "views", Operator: record.FilterGreaterEqual, Value: 10.0},
+				{Column: "views", Operator: record.FilterLess, Value: 20.0},
+			},
+			wantSQL:  `"views" >= ? AND "views" < ?`,
+			wantArgs: []interface{}{10.0, 20.0},
+		},
+		{
+			name:    "is null and is not null",
+			filters: []record.Filter{{Column: "title", Operator: record.FilterIsNull, Value: true}, {Column: "views", Operator: record.FilterIsNull, Value: false}},
+			wantSQL: `"title" IS NULL AND "views" IS NOT NULL`,
+		},
+		{
+			name:     "in",
+			filters:  []record.Filter{{Column: "id", Operator: record.FilterIn, Value: []interface{}{1.0, 2.0, 3.0}}},
+			wantSQL:  `"id" IN (?, ?, ?)`,
+			wantArgs: []interface{}{1.0, 2.0, 3.0},
+		},
+		{
+			name:     "contains on text escapes wildcards",
+			filters:  []record.Filter{{Column: "title", Operator: record.FilterContains, Value: `50%_off\`}},
+			wantSQL:  `"title" ILIKE ? ESCAPE '\'`,
+			wantArgs: []interface{}{`%50\%\_off\\%`},
+		},
+		{
+			name:     "contains on an array uses @>",
+			filters:  []record.Filter{{Column: "tags", Operator: record.FilterContains, Value: []interface{}{"go", "sql"}}},
+			wantSQL:  `"tags" @> ARRAY[?, ?]::text[]`,
+			wantArgs: []interface{}{"go", "sql"},
+		},
+		{
+			name:     "contains on json uses @>",
+			filters:  []record.Filter{{Column: "meta", Operator: record.FilterContains, Value: `{"draft":true}`}},
+			wantSQL:  `"meta" @> ?::jsonb`,
+			wantArgs: []interface{}{`{"draft":true}`},
+		},
+		{
+			name:     "json path equal",
+			filters:  []record.Filter{{Column: "meta", Path: []string{"author", "name"}, Operator: record.FilterEqual, Value: "hanako"}},
+			wantSQL:  `jsonb_extract_path_text("meta", ?, ?) = ?`,
+			wantArgs: []interface{}{"author", "name", "hanako"},
+		},
+		{
+			name:     "json path numeric comparison casts numbers only",
+			filters:  []record.Filter{{Column: "meta", Path: []string{"score"}, Operator: record.FilterGreater, Value: 3.0}},
+			wantSQL:  `CASE WHEN jsonb_typeof(jsonb_extract_path("meta", ?)) = 'number' THEN (jsonb_extract_path_text("meta", ?))::numeric END > ?`,
+			wantArgs: []interface{}{"score", "score", 3.0},
+		},
+		{
+			name:     "json path string comparison is not cast",
+			filters:  []record.Filter{{Column: "meta", Path: []string{"published"}, Operator: record.FilterLessEqual, Value: "2026-10-18"}},
+			wantSQL:  `jsonb_extract_path_text("meta", ?) <= ?`,
+			wantArgs: []interface{}{"published", "2026-10-18"},
+		},
+		{
+			name:     "missing translation",
+			filters:  []record.Filter{{Column: "name", Operator: record.FilterMissing, Value: "en"}},
+			wantSQL:  `("name" IS NOT NULL AND COALESCE("name"->>?, '') = '')`,
+			wantArgs: []interface{}{"en"},
+		},
+		{
+			name: "any of several filters",
+			filters: []record.Filter{
+				{Column: "views", Operator: record.FilterGreater, Value: 5.0},
+				{Column: "title", Operator: record.FilterAny, Value: []record.Filter{
+					{Column: "title", Operator: record.FilterEqual, Value: "a"},
+					{Column: "meta", Path: []string{"n"}, Operator: record.FilterLess, Value: 2.0},
+				}},
+			},
+			wantSQL: `"views" > ? AND ("title" = ? OR ` +
+				`CASE WHEN jsonb_typeof(jsonb_extract_path("meta", ?)) = 'number' THEN (jsonb_extract_path_text("meta", ?))::numeric END < ?)`,
+			wantArgs: []interface{}{5.0, "a", "n", "n", 2.0},
+		},
+		{
+			name:     "identifiers are quoted",
+			filters:  []record.Filter{{Column: `we"ird`, Operator: record.FilterEqual, Value: 1.0}},
+			wantSQL:  `"we""ird" = ?`,
+			wantArgs: []interface{}{1.0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := buildFilterSQL(filterTable, tt.filters)
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %s\nwant  %s", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestBuildKeysetSQL(t *testing.T) {
+	id := record.SortKey{Column: "id"}
+	createdAt := record.SortKey{Column: "created_at"}
+	tests := []struct {
+		name     string
+		keys     []record.SortKey
+		after    []interface{}
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "primary key",
+			keys:     []record.SortKey{id},
+			after:    []interface{}{int64(5)},
+			wantSQL:  `("id") > (?)`,
+			wantArgs: []interface{}{int64(5)},
+		},
+		{
+			name:     "ascending keys compare as a row",
+			keys:     []record.SortKey{createdAt, id},
+			after:    []interface{}{"2026-10-18", int64(5)},
+			wantSQL:  `("created_at", "id") > (?, ?)`,
+			wantArgs: []interface{}{"2026-10-18", int64(5)},
+		},
+		{
+			name:     "descending keys compare as a row",
+			keys:     []record.SortKey{{Column: "created_at", Descending: true}, {Column: "id", Descending: true}},
+			after:    []interface{}{"2026-10-18", int64(5)},
+			wantSQL:  `("created_at", "id") < (?, ?)`,
+			wantArgs: []interface{}{"2026-10-18", int64(5)},
+		},
+		{
+			name:     "mixed directions expand into branches",
+			keys:     []record.SortKey{{Column: "views", Descending: true}, id},
+			after:    []interface{}{int64(10), int64(3)},
+			wantSQL:  `(("views" < ?) OR ("views" = ? AND "id" > ?))`,
+			wantArgs: []interface{}{int64(10), int64(10), int64(3)},
+		},
+		{
+			name:     "nullable key lets NULLs follow every value",
+			keys:     []record.SortKey{{Column: "title", Nullable: true}, id},
+			after:    []interface{}{"b", int64(3)},
+			wantSQL:  `((("title" > ? OR "title" IS NULL)) OR ("title" = ? AND "id" > ?))`,
+			wantArgs: []interface{}{"b", "b", int64(3)},
+		},
+		{
+			name:     "after a NULL only NULLs with a later key follow",
+			keys:     []record.SortKey{{Column: "title", Nullable: true}, id},
+			after:    []interface{}{nil, int64(3)},
+			wantSQL:  `(("title" IS NULL AND "id" > ?))`,
+			wantArgs: []interface{}{int64(3)},
+		},
+		{
+			name:    "nothing follows a NULL of the last key",
+			keys:    []record.SortKey{{Column: "title", Nullable: true}},
+			after:   []interface{}{nil},
+			wantSQL: "FALSE",
+		},
+		{
+			name:     "identifiers are quoted",
+			keys:     []record.SortKey{{Column: `we"ird`}},
+			after:    []interface{}{1.0},
+			wantSQL:  `("we""ird") > (?)`,
+			wantArgs: []interface{}{1.0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := buildKeysetSQL(tt.keys, tt.after)
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %s\nwant  %s", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestBuildOrderBySQL(t *testing.T) {
+	keys := []record.SortKey{
+		{Column: "created_at", Descending: true},
+		{Column: "title", Nullable: true},
+		{Column: "rank", Descending: true, Nullable: true},
+		{Column: "id"},
+	}
+	want := `"created_at" DESC, "title" NULLS LAST, "rank" DESC NULLS LAST, "id"`
+	if got := buildOrderBySQL(keys); got != want {
+		t.Errorf("buildOrderBySQL = %s, want %s", got, want)
+	}
+}
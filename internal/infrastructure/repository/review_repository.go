@@ -0,0 +1,95 @@
+// File: internal/infrastructure/repository/review_repository.go
+
+package repository
+
+import (
+	"context"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/infrastructure/database"
+	"quickflow/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// ReviewRepository stores the transition log and review comments of workflow records
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// LogTransition appends an entry to the transition log of a record
+func (r *ReviewRepository) LogTransition(ctx context.Context, tableName, recordKey string, t record.Transition) error {
+	var userID uint
+	if t.UserID != nil {
+		userID = *t.UserID
+	}
+	err := database.Conn(ctx, r.db).Exec(`INSERT INTO record_transitions
+(table_name, record_key, action, from_stage, to_stage, from_status, to_status, user_id, comment)
+VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, NULLIF(?, ''))`,
+		tableName, recordKey, t.Action, t.FromStage, t.ToStage, string(t.FromStatus), string(t.ToStatus), userRef(userID), t.Comment,
+	).Error
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to log record transition", err)
+	}
+	return nil
+}
+
+// ListTransitions returns the transition log of a record, oldest first
+func (r *ReviewRepository) ListTransitions(ctx context.Context, tableName, recordKey string) ([]record.Transition, error) {
+	transitions := []record.Transition{}
+	err := database.Conn(ctx, r.db).Raw(`SELECT id, action, COALESCE(from_stage, '') AS from_stage, COALESCE(to_stage, '') AS to_stage,
+from_status, to_status, user_id, COALESCE(comment, '') AS comment, created_at
+FROM record_transitions WHERE table_name = ? AND record_key = ? ORDER BY id`,
+		tableName, recordKey,
+	).Scan(&transitions).Error
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to list record transitions", err)
+	}
+	return transitions, nil
+}
+
+// AddComment stores a review comment and fills in its ID and creation time
+func (r *ReviewRepository) AddComment(ctx context.Context, tableName, recordKey string, comment *record.Comment) error {
+	var userID uint
+	if comment.UserID != nil {
+		userID = *comment.UserID
+	}
+	err := database.Conn(ctx, r.db).Raw(`INSERT INTO record_comments (table_name, record_key, user_id, body, changes_requested)
+VALUES (?, ?, ?, ?, ?) RETURNING id, created_at`,
+		tableName, recordKey, userRef(userID), comment.Body, comment.ChangesRequested,
+	).Row().Scan(&comment.ID, &comment.CreatedAt)
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to add record comment", err)
+	}
+	return nil
+}
+
+// ListComments returns the review comments of a record, oldest first
+func (r *ReviewRepository) ListComments(ctx context.Context, tableName, recordKey string) ([]record.Comment, error) {
+	comments := []record.Comment{}
+	err := database.Conn(ctx, r.db).Raw(
+		"SELECT id, user_id, body, changes_requested, created_at FROM record_comments WHERE table_name = ? AND record_key = ? ORDER BY id",
+		tableName, recordKey,
+	).Scan(&comments).Error
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to list record comments", err)
+	}
+	return comments, nil
+}
+
+// DeleteComments removes the review comments of a deleted record. The transition
+// log is kept as an audit trail.
+func (r *ReviewRepository) DeleteComments(ctx context.Context, tableName, recordKey string) error {
+	err := database.Conn(ctx, r.db).Exec(
+		"DELETE FROM record_comments WHERE table_name = ? AND record_key = ?",
+		tableName, recordKey,
+	).Error
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to delete record comments", err)
+	}
+	return nil
+}
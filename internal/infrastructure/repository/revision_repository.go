@@ -0,0 +1,102 @@
+// File: internal/infrastructure/repository/revision_repository.go
+
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"quickflow/internal/domain/record"
+	"quickflow/internal/infrastructure/database"
+	"quickflow/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// RevisionRepository stores the immutable revision history of records
+type RevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewRevisionRepository(db *gorm.DB) *RevisionRepository {
+	return &RevisionRepository{db: db}
+}
+
+// Add stores the next revision of a record and fills in its number and creation time.
+// It runs after the write it records, whose row lock keeps revision numbers of a record
+// from being taken twice.
+func (r *RevisionRepository) Add(ctx context.Context, tableName, recordKey string, rev *record.Revision) error {
+	data, err := json.Marshal(rev.Data)
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to encode record revision", err)
+	}
+
+	var userID uint
+	if rev.UserID != nil {
+		userID = *rev.UserID
+	}
+	err = database.Conn(ctx, r.db).Raw(`INSERT INTO record_revisions (table_name, record_key, revision, operation, data, user_id, restored_from)
+SELECT ?, ?, COALESCE(MAX(revision), 0) + 1, ?, ?::jsonb, ?, ?
+FROM record_revisions WHERE table_name = ? AND record_key = ?
+RETURNING revision, created_at`,
+		tableName, recordKey, string(rev.Operation), string(data), userRef(userID), rev.RestoredFrom, tableName, recordKey,
+	).Row().Scan(&rev.Revision, &rev.CreatedAt)
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to store record revision", err)
+	}
+	return nil
+}
+
+// List returns the revisions of a record without their snapshots, newest first
+func (r *RevisionRepository) List(ctx context.Context, tableName, recordKey string) ([]record.Revision, error) {
+	revisions := []record.Revision{}
+	err := database.Conn(ctx, r.db).Raw(
+		"SELECT revision, operation, user_id, restored_from, created_at FROM record_revisions WHERE table_name = ? AND record_key = ? ORDER BY revision DESC",
+		tableName, recordKey,
+	).Scan(&revisions).Error
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to list record revisions", err)
+	}
+	return revisions, nil
+}
+
+// Get returns one revision of a record with its snapshot
+func (r *RevisionRepository) Get(ctx context.Context, tableName, recordKey string, revision int) (*record.Revision, error) {
+	var row struct {
+		Revision     int
+		Operation    string
+		UserID       *uint
+		RestoredFrom *int
+		CreatedAt    time.Time
+		Data         []byte
+	}
+	result := database.Conn(ctx, r.db).Raw(
+		"SELECT revision, operation, user_id, restored_from, created_at, data FROM record_revisions WHERE table_name = ? AND record_key = ? AND revision = ?",
+		tableName, recordKey, revision,
+	).Scan(&row)
+	if result.Error != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to get record revision", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.NewAppError(errors.ErrorTypeNotFound, "Revision not found", nil)
+	}
+
+	// 数値は json.Number のまま保持し、復元時にそのまま型変換へ渡す
+	decoder := json.NewDecoder(bytes.NewReader(row.Data))
+	decoder.UseNumber()
+	var data record.Record
+	if err := decoder.Decode(&data); err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to decode record revision", err)
+	}
+
+	return &record.Revision{
+		Revision:     row.Revision,
+		Operation:    record.Operation(row.Operation),
+		UserID:       row.UserID,
+		RestoredFrom: row.RestoredFrom,
+		CreatedAt:    row.CreatedAt,
+		Data:         data,
+	}, nil
+}
@@ -1 +1,143 @@
+// File: internal/infrastructure/repository/schema_repository.go
+
 package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"quickflow/internal/domain/schema"
+	"quickflow/internal/domain/tableentity"
+	"quickflow/internal/infrastructure/database"
+	"quickflow/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// schemaModel is the persisted form of a catalog entry
+type schemaModel struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"column:table_name;not null;unique"`
+	DisplayName string
+	Description string
+	Definition  []byte `gorm:"type:jsonb;not null"`
+	OwnerID     *uint
+	CreatedAt   time.Time `gorm:"not null"`
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+func (schemaModel) TableName() string {
+	return "table_schemas"
+}
+
+type SchemaRepository struct {
+	db *gorm.DB
+}
+
+func NewSchemaRepository(db *gorm.DB) *SchemaRepository {
+	return &SchemaRepository{db: db}
+}
+
+func (r *SchemaRepository) Create(ctx context.Context, s *schema.Schema) error {
+	model, err := toSchemaModel(s)
+	if err != nil {
+		return err
+	}
+
+	if err := database.Conn(ctx, r.db).Create(model).Error; err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to register table schema", err)
+	}
+	s.ID = model.ID
+	return nil
+}
+
+func (r *SchemaRepository) GetByTableName(ctx context.Context, tableName string) (*schema.Schema, error) {
+	var model schemaModel
+	err := database.Conn(ctx, r.db).Where("table_name = ?", tableName).First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewAppError(
+				errors.ErrorTypeNotFound,
+				fmt.Sprintf("Table '%s' not found", tableName),
+				nil,
+			)
+		}
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to get table schema", err)
+	}
+	return fromSchemaModel(&model)
+}
+
+func (r *SchemaRepository) List(ctx context.Context) ([]*schema.Schema, error) {
+	var models []schemaModel
+	if err := database.Conn(ctx, r.db).Order("table_name").Find(&models).Error; err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to list table schemas", err)
+	}
+
+	schemas := make([]*schema.Schema, 0, len(models))
+	for i := range models {
+		s, err := fromSchemaModel(&models[i])
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, s)
+	}
+	return schemas, nil
+}
+
+func (r *SchemaRepository) Update(ctx context.Context, s *schema.Schema) error {
+	model, err := toSchemaModel(s)
+	if err != nil {
+		return err
+	}
+
+	if err := database.Conn(ctx, r.db).Save(model).Error; err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to update table schema", err)
+	}
+	return nil
+}
+
+// Delete removes the catalog entry of a table
+func (r *SchemaRepository) Delete(ctx context.Context, tableName string) error {
+	if err := database.Conn(ctx, r.db).Where("table_name = ?", tableName).Delete(&schemaModel{}).Error; err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to delete table schema", err)
+	}
+	return nil
+}
+
+func toSchemaModel(s *schema.Schema) (*schemaModel, error) {
+	definition, err := json.Marshal(s.Definition)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to encode table definition", err)
+	}
+
+	return &schemaModel{
+		ID:          s.ID,
+		Name:        s.TableName,
+		DisplayName: s.DisplayName,
+		Description: s.Description,
+		Definition:  definition,
+		OwnerID:     s.OwnerID,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}, nil
+}
+
+func fromSchemaModel(model *schemaModel) (*schema.Schema, error) {
+	var definition tableentity.Table
+	if err := json.Unmarshal(model.Definition, &definition); err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to decode table definition", err)
+	}
+
+	return &schema.Schema{
+		ID:          model.ID,
+		TableName:   model.Name,
+		DisplayName: model.DisplayName,
+		Description: model.Description,
+		Definition:  definition,
+		OwnerID:     model.OwnerID,
+		CreatedAt:   model.CreatedAt,
+		UpdatedAt:   model.UpdatedAt,
+	}, nil
+}
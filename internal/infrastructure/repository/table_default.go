@@ -0,0 +1,186 @@
+// File: internal/infrastructure/repository/table_default.go
+
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"quickflow/internal/domain/tableentity"
+)
+
+// defaultFunctionSQL is the SQL emitted for each whitelisted default function
+var defaultFunctionSQL = map[tableentity.DefaultFunction]string{
+	tableentity.DefaultNow:              "now()",
+	tableentity.DefaultCurrentTimestamp: "CURRENT_TIMESTAMP",
+	tableentity.DefaultCurrentDate:      "CURRENT_DATE",
+	tableentity.DefaultGenRandomUUID:    "gen_random_uuid()",
+}
+
+// buildDefaultSQL renders a validated default as an escaped literal cast to the column type
+func buildDefaultSQL(col tableentity.Column) string {
+	def := col.Default
+	if def.Function != "" {
+		return defaultFunctionSQL[def.Function]
+	}
+
+	typeSQL := columnTypeSQL(col)
+	switch col.Type {
+	case tableentity.TypeJSON:
+		b, _ := json.Marshal(def.Value)
+		return quoteLiteral(string(b)) + "::" + typeSQL
+	case tableentity.TypeBYTEA:
+		b, _ := base64.StdEncoding.DecodeString(def.Value.(string))
+		return quoteLiteral(`\x`+hex.EncodeToString(b)) + "::" + typeSQL
+	case tableentity.TypeARRAY:
+		items, _ := def.Value.([]interface{})
+		if len(items) == 0 {
+			return "'{}'::" + typeSQL
+		}
+		elements := make([]string, len(items))
+		for i, item := range items {
+			elements[i] = quoteLiteral(item)
+		}
+		return fmt.Sprintf("ARRAY[%s]::%s", strings.Join(elements, ", "), typeSQL)
+	}
+	return quoteLiteral(def.Value) + "::" + typeSQL
+}
+
+var (
+	// castLiteralPattern matches a quoted literal with an optional type cast such as 'abc'::character varying
+	castLiteralPattern = regexp.MustCompile(`^'((?:[^']|'')*)'(?:::[a-z_ ."\[\]()0-9]+)?$`)
+	numberPattern      = regexp.MustCompile(`^\(?(-?[0-9]+(?:\.[0-9]+)?)\)?(?:::[a-z ]+)?$`)
+)
+
+// parseColumnDefault converts a default expression read from information_schema back into
+// a typed default. Expressions that were not produced by buildDefaultSQL yield nil.
+func parseColumnDefault(col tableentity.Column, expr string) *tableentity.DefaultValue {
+	switch strings.ToLower(expr) {
+	case "now()":
+		return &tableentity.DefaultValue{Function: tableentity.DefaultNow}
+	case "current_timestamp":
+		return &tableentity.DefaultValue{Function: tableentity.DefaultCurrentTimestamp}
+	case "current_date":
+		return &tableentity.DefaultValue{Function: tableentity.DefaultCurrentDate}
+	case "true", "false":
+		return &tableentity.DefaultValue{Value: strings.ToLower(expr) == "true"}
+	}
+
+	if m := numberPattern.FindStringSubmatch(expr); m != nil {
+		return literalDefault(col.Type, m[1])
+	}
+
+	m := castLiteralPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return nil
+	}
+	text := strings.ReplaceAll(m[1], "''", "'")
+
+	switch col.Type {
+	case tableentity.TypeJSON:
+		var value interface{}
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			return nil
+		}
+		return &tableentity.DefaultValue{Value: value}
+	case tableentity.TypeBYTEA:
+		b, err := hex.DecodeString(strings.TrimPrefix(text, `\x`))
+		if err != nil {
+			return nil
+		}
+		return &tableentity.DefaultValue{Value: base64.StdEncoding.EncodeToString(b)}
+	case tableentity.TypeARRAY:
+		elements, ok := parseArrayLiteral(text)
+		if !ok {
+			return nil
+		}
+		values := make([]interface{}, len(elements))
+		for i, element := range elements {
+			if element == nil {
+				continue
+			}
+			if def := literalDefault(col.ElementType, *element); def != nil {
+				values[i] = def.Value
+			}
+		}
+		return &tableentity.DefaultValue{Value: values}
+	}
+	return literalDefault(col.Type, text)
+}
+
+// literalDefault converts the text form of a scalar literal into the JSON value used in definitions
+func literalDefault(columnType tableentity.ColumnType, text string) *tableentity.DefaultValue {
+	switch columnType {
+	case tableentity.TypeINT, tableentity.TypeBIGINT, tableentity.TypeFLOAT, tableentity.TypeDOUBLE:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil
+		}
+		return &tableentity.DefaultValue{Value: f}
+	case tableentity.TypeBOOLEAN:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil
+		}
+		return &tableentity.DefaultValue{Value: b}
+	}
+	// numeric は精度を保つため文字列のまま扱う
+	return &tableentity.DefaultValue{Value: text}
+}
+
+// parseArrayLiteral splits a one dimensional PostgreSQL array literal such as {1,"a b",NULL}
+func parseArrayLiteral(text string) ([]*string, bool) {
+	if !strings.HasPrefix(text, "{") || !strings.HasSuffix(text, "}") {
+		return nil, false
+	}
+	body := text[1 : len(text)-1]
+	elements := []*string{}
+	if body == "" {
+		return elements, true
+	}
+
+	for len(body) > 0 {
+		var element string
+		if body[0] == '"' {
+			var sb strings.Builder
+			i := 1
+			for ; i < len(body) && body[i] != '"'; i++ {
+				if body[i] == '\\' && i+1 < len(body) {
+					i++
+				}
+				sb.WriteByte(body[i])
+			}
+			if i >= len(body) {
+				return nil, false
+			}
+			element = sb.String()
+			body = body[i+1:]
+			elements = append(elements, &element)
+		} else {
+			end := strings.IndexByte(body, ',')
+			if end < 0 {
+				end = len(body)
+			}
+			element = body[:end]
+			body = body[end:]
+			if element == "NULL" {
+				elements = append(elements, nil)
+			} else {
+				elements = append(elements, &element)
+			}
+		}
+
+		if len(body) > 0 {
+			if body[0] != ',' {
+				return nil, false
+			}
+			body = body[1:]
+		}
+	}
+	return elements, true
+}
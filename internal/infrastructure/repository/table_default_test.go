@@ -0,0 +1,212 @@
+// File: internal/infrastructure/repository/table_default_test.go
+
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"quickflow/internal/domain/tableentity"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"articles", `"articles"`},
+		{"Mixed Case", `"Mixed Case"`},
+		{`we"ird`, `"we""ird"`},
+		{`x"; DROP TABLE users; --`, `"x""; DROP TABLE users; --"`},
+		{"", `""`},
+	}
+	for _, tt := range tests {
+		if got := quoteIdentifier(tt.name); got != tt.want {
+			t.Errorf("quoteIdentifier(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nil", nil, "NULL"},
+		{"true", true, "TRUE"},
+		{"false", false, "FALSE"},
+		{"float", 1.5, "1.5"},
+		{"large float without exponent", 1e21, "1000000000000000000000"},
+		{"negative float", -0.25, "-0.25"},
+		{"int", 42, "42"},
+		{"int64", int64(-7), "-7"},
+		{"string", "hello", "'hello'"},
+		{"quote is doubled", "it's", "'it''s'"},
+		{"injection stays inside the literal", "'); DROP TABLE users; --", "'''); DROP TABLE users; --'"},
+		// standard_conforming_strings が有効なのでバックスラッシュはそのまま
+		{"backslash", `C:\path`, `'C:\path'`},
+		{"other types use their text form", time.Second, "'1s'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteLiteral(tt.value); got != tt.want {
+				t.Errorf("quoteLiteral(%#v) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildDefaultSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		col  tableentity.Column
+		want string
+	}{
+		{
+			name: "function",
+			col:  tableentity.Column{Type: tableentity.TypeTIMESTAMPTZ, Default: &tableentity.DefaultValue{Function: tableentity.DefaultNow}},
+			want: "now()",
+		},
+		{
+			name: "current date",
+			col:  tableentity.Column{Type: tableentity.TypeDATE, Default: &tableentity.DefaultValue{Function: tableentity.DefaultCurrentDate}},
+			want: "CURRENT_DATE",
+		},
+		{
+			name: "text",
+			col:  tableentity.Column{Type: tableentity.TypeTEXT, Default: &tableentity.DefaultValue{Value: "it's"}},
+			want: "'it''s'::text",
+		},
+		{
+			name: "varchar with length",
+			col:  tableentity.Column{Type: tableentity.TypeVARCHAR, Length: intPtr(20), Default: &tableentity.DefaultValue{Value: "x"}},
+			want: "'x'::varchar(20)",
+		},
+		{
+			name: "integer",
+			col:  tableentity.Column{Type: tableentity.TypeINT, Default: &tableentity.DefaultValue{Value: 42.0}},
+			want: "42::integer",
+		},
+		{
+			name: "boolean",
+			col:  tableentity.Column{Type: tableentity.TypeBOOLEAN, Default: &tableentity.DefaultValue{Value: true}},
+			want: "TRUE::boolean",
+		},
+		{
+			name: "numeric keeps the string form",
+			col:  tableentity.Column{Type: tableentity.TypeNUMERIC, Precision: intPtr(10), Scale: intPtr(2), Default: &tableentity.DefaultValue{Value: "12.50"}},
+			want: "'12.50'::numeric(10,2)",
+		},
+		{
+			name: "json",
+			col:  tableentity.Column{Type: tableentity.TypeJSON, Default: &tableentity.DefaultValue{Value: map[string]interface{}{"note": "it's"}}},
+			want: `'{"note":"it''s"}'::jsonb`,
+		},
+		{
+			name: "bytea from base64",
+			col:  tableentity.Column{Type: tableentity.TypeBYTEA, Default: &tableentity.DefaultValue{Value: "AQL/"}},
+			want: `'\x0102ff'::bytea`,
+		},
+		{
+			name: "array",
+			col:  tableentity.Column{Type: tableentity.TypeARRAY, ElementType: tableentity.TypeTEXT, Default: &tableentity.DefaultValue{Value: []interface{}{"a", "b'c", nil}}},
+			want: "ARRAY['a', 'b''c', NULL]::text[]",
+		},
+		{
+			name: "empty array",
+			col:  tableentity.Column{Type: tableentity.TypeARRAY, ElementType: tableentity.TypeINT, Default: &tableentity.DefaultValue{Value: []interface{}{}}},
+			want: "'{}'::integer[]",
+		},
+		{
+			name: "enum",
+			col:  tableentity.Column{Type: tableentity.TypeENUM, EnumName: "article_status", Default: &tableentity.DefaultValue{Value: "draft"}},
+			want: `'draft'::"article_status"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildDefaultSQL(tt.col); got != tt.want {
+				t.Errorf("buildDefaultSQL = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseColumnDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		col  tableentity.Column
+		expr string
+		want *tableentity.DefaultValue
+	}{
+		{"now", tableentity.Column{Type: tableentity.TypeTIMESTAMPTZ}, "now()", &tableentity.DefaultValue{Function: tableentity.DefaultNow}},
+		{"current timestamp", tableentity.Column{Type: tableentity.TypeTIMESTAMP}, "CURRENT_TIMESTAMP", &tableentity.DefaultValue{Function: tableentity.DefaultCurrentTimestamp}},
+		{"current date", tableentity.Column{Type: tableentity.TypeDATE}, "CURRENT_DATE", &tableentity.DefaultValue{Function: tableentity.DefaultCurrentDate}},
+		{"boolean", tableentity.Column{Type: tableentity.TypeBOOLEAN}, "false", &tableentity.DefaultValue{Value: false}},
+		{"integer", tableentity.Column{Type: tableentity.TypeINT}, "42", &tableentity.DefaultValue{Value: 42.0}},
+		{"negative integer", tableentity.Column{Type: tableentity.TypeBIGINT}, "'-3'::bigint", &tableentity.DefaultValue{Value: -3.0}},
+		{"parenthesized number", tableentity.Column{Type: tableentity.TypeINT}, "(-3)", &tableentity.DefaultValue{Value: -3.0}},
+		{"double", tableentity.Column{Type: tableentity.TypeDOUBLE}, "'1.5'::double precision", &tableentity.DefaultValue{Value: 1.5}},
+		{"numeric stays a string", tableentity.Column{Type: tableentity.TypeNUMERIC}, "12.50", &tableentity.DefaultValue{Value: "12.50"}},
+		{"text", tableentity.Column{Type: tableentity.TypeTEXT}, "'it''s'::text", &tableentity.DefaultValue{Value: "it's"}},
+		{"varchar", tableentity.Column{Type: tableentity.TypeVARCHAR}, "'x'::character varying", &tableentity.DefaultValue{Value: "x"}},
+		{"json", tableentity.Column{Type: tableentity.TypeJSON}, `'{"a": [1, "b"]}'::jsonb`, &tableentity.DefaultValue{Value: map[string]interface{}{"a": []interface{}{1.0, "b"}}}},
+		{"bytea", tableentity.Column{Type: tableentity.TypeBYTEA}, `'\x0102ff'::bytea`, &tableentity.DefaultValue{Value: "AQL/"}},
+		{
+			"text array",
+			tableentity.Column{Type: tableentity.TypeARRAY, ElementType: tableentity.TypeTEXT},
+			`'{a,"b c",NULL}'::text[]`,
+			&tableentity.DefaultValue{Value: []interface{}{"a", "b c", nil}},
+		},
+		{
+			"integer array",
+			tableentity.Column{Type: tableentity.TypeARRAY, ElementType: tableentity.TypeINT},
+			`'{1,2}'::integer[]`,
+			&tableentity.DefaultValue{Value: []interface{}{1.0, 2.0}},
+		},
+		{"enum", tableentity.Column{Type: tableentity.TypeENUM, EnumName: "article_status"}, `'draft'::"article_status"`, &tableentity.DefaultValue{Value: "draft"}},
+		// buildDefaultSQL が生成しない式は読み戻さない
+		{"sequence", tableentity.Column{Type: tableentity.TypeBIGINT}, "nextval('articles_id_seq'::regclass)", nil},
+		{"gen_random_uuid is auto increment", tableentity.Column{Type: tableentity.TypeUUID}, "gen_random_uuid()", nil},
+		{"expression", tableentity.Column{Type: tableentity.TypeTEXT}, "lower('A'::text)", nil},
+		{"concatenation", tableentity.Column{Type: tableentity.TypeTEXT}, "'a'::text || 'b'::text", nil},
+		{"invalid json", tableentity.Column{Type: tableentity.TypeJSON}, `'{'::jsonb`, nil},
+		{"invalid bytea", tableentity.Column{Type: tableentity.TypeBYTEA}, `'\xzz'::bytea`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseColumnDefault(tt.col, tt.expr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseColumnDefault(%q) = %#v, want %#v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArrayLiteral(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		text   string
+		want   []*string
+		wantOK bool
+	}{
+		{"{}", []*string{}, true},
+		{"{a,b}", []*string{str("a"), str("b")}, true},
+		{`{"a,b","c\"d","e\\f"}`, []*string{str("a,b"), str(`c"d`), str(`e\f`)}, true},
+		{`{NULL,"NULL"}`, []*string{nil, str("NULL")}, true},
+		{"a,b", nil, false},
+		{`{"a}`, nil, false},
+		{`{"a"b}`, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, ok := parseArrayLiteral(tt.text)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseArrayLiteral(%q) = %v, %v, want %v, %v", tt.text, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"context"
 	"fmt"
 	"quickflow/internal/domain/tableentity"
+	"quickflow/internal/infrastructure/database"
 	"quickflow/pkg/errors"
+	"quickflow/pkg/logger"
+	"slices"
 	"strings"
 
 	"gorm.io/gorm"
@@ -13,15 +16,17 @@ import (
 
 type TableRepository struct {
 	db *gorm.DB
+	// defaultLocale keeps existing values when a column becomes localized or stops being localized
+	defaultLocale string
 }
 
-func NewTableRepository(db *gorm.DB) *TableRepository {
-	return &TableRepository{db: db}
+func NewTableRepository(db *gorm.DB, defaultLocale string) *TableRepository {
+	return &TableRepository{db: db, defaultLocale: defaultLocale}
 }
 
 func (r *TableRepository) TableExists(ctx context.Context, tableName string) (bool, error) {
 	var exists bool
-	err := r.db.WithContext(ctx).Raw(
+	err := database.Conn(ctx, r.db).Raw(
 		"SELECT EXISTS (SELECT FROM information_schema.tables WHERE table_name = ?)",
 		tableName,
 	).Scan(&exists).Error
@@ -36,75 +41,1017 @@ func (r *TableRepository) TableExists(ctx context.Context, tableName string) (bo
 	return exists, nil
 }
 
-func (r *TableRepository) CreateTable(ctx context.Context, table *tableentity.Table) error {
-	sql := buildCreateTableSQL(table)
+type columnInfo struct {
+	TableName              string
+	ColumnName             string
+	DataType               string
+	UDTName                string
+	CharacterMaximumLength *int
+	NumericPrecision       *int
+	NumericScale           *int
+	IsNullable             string
+	ColumnDefault          *string
+	Description            *string
+}
 
-	if err := r.db.WithContext(ctx).Exec(sql).Error; err != nil {
-		return errors.NewAppError(
+type enumValueInfo struct {
+	TypeName  string
+	EnumLabel string
+}
+
+type constraintInfo struct {
+	TableName        string
+	ConstraintType   string
+	ColumnName       string
+	KeyCount         int
+	ReferencedTable  *string
+	ReferencedColumn *string
+	DeleteAction     *string
+	UpdateAction     *string
+}
+
+type tableCommentInfo struct {
+	TableName   string
+	Description string
+}
+
+type indexInfo struct {
+	TableName   string
+	IndexName   string
+	IsUnique    bool
+	Method      string
+	ColumnNames string
+}
+
+// referentialActions maps pg_constraint action codes to their SQL keywords
+var referentialActions = map[string]tableentity.ReferentialAction{
+	"a": tableentity.ActionNoAction,
+	"r": tableentity.ActionRestrict,
+	"c": tableentity.ActionCascade,
+	"n": tableentity.ActionSetNull,
+	"d": tableentity.ActionSetDefault,
+}
+
+// ListTables returns the names of all base tables in the current schema
+func (r *TableRepository) ListTables(ctx context.Context) ([]string, error) {
+	var names []string
+	err := database.Conn(ctx, r.db).Raw(
+		`SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+		ORDER BY table_name`,
+	).Scan(&names).Error
+	if err != nil {
+		return nil, errors.NewAppError(
 			errors.ErrorTypeInternal,
-			"Failed to create table",
+			"Failed to list tables",
 			err,
 		)
 	}
+	return names, nil
+}
 
-	// テーブルにコメントが指定されている場合
-	if table.Description != "" {
-		commentSQL := fmt.Sprintf(
-			"COMMENT ON TABLE %s IS '%s'",
-			table.Name,
-			strings.Replace(table.Description, "'", "''", -1),
-		)
-		if err := r.db.WithContext(ctx).Exec(commentSQL).Error; err != nil {
-			return errors.NewAppError(
-				errors.ErrorTypeInternal,
-				"Failed to add table comment",
-				err,
+// DescribeTable rebuilds the definition of a single table
+func (r *TableRepository) DescribeTable(ctx context.Context, tableName string) (*tableentity.Table, error) {
+	tables, err := r.DescribeTables(ctx, []string{tableName})
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeNotFound,
+			fmt.Sprintf("Table '%s' not found", tableName),
+			nil,
+		)
+	}
+	return tables[0], nil
+}
+
+// DescribeTables rebuilds table definitions from information_schema, pg_constraint, pg_description
+// and pg_index, reading each catalog once for all tables. Tables are returned in the order of
+// names; names without a table are left out. Expression indexes and the conditions of partial
+// indexes are not read back.
+func (r *TableRepository) DescribeTables(ctx context.Context, names []string) ([]*tableentity.Table, error) {
+	tables := []*tableentity.Table{}
+	if len(names) == 0 {
+		return tables, nil
+	}
+
+	var columns []columnInfo
+	err := database.Conn(ctx, r.db).Raw(
+		`SELECT c.table_name, c.column_name, c.data_type, c.udt_name, c.character_maximum_length,
+			c.numeric_precision, c.numeric_scale, c.is_nullable, c.column_default,
+			d.description
+		FROM information_schema.columns c
+		LEFT JOIN pg_description d
+			ON d.classoid = 'pg_class'::regclass
+			AND d.objoid = (quote_ident(c.table_schema) || '.' || quote_ident(c.table_name))::regclass
+			AND d.objsubid = c.ordinal_position
+		WHERE c.table_schema = current_schema() AND c.table_name IN ?
+		ORDER BY c.table_name, c.ordinal_position`,
+		names,
+	).Scan(&columns).Error
+	if err != nil {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeInternal,
+			"Failed to describe table",
+			err,
+		)
+	}
+
+	var constraints []constraintInfo
+	err = database.Conn(ctx, r.db).Raw(
+		`SELECT cls.relname AS table_name, con.contype::text AS constraint_type, att.attname AS column_name,
+			array_length(con.conkey, 1) AS key_count,
+			ref.relname AS referenced_table, refatt.attname AS referenced_column,
+			con.confdeltype::text AS delete_action, con.confupdtype::text AS update_action
+		FROM pg_constraint con
+		JOIN pg_class cls ON cls.oid = con.conrelid
+		JOIN pg_namespace ns ON ns.oid = cls.relnamespace
+		JOIN pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = ANY(con.conkey)
+		LEFT JOIN pg_class ref ON ref.oid = con.confrelid
+		LEFT JOIN pg_attribute refatt ON refatt.attrelid = con.confrelid AND refatt.attnum = con.confkey[1]
+		WHERE ns.nspname = current_schema() AND cls.relname IN ?
+			AND con.contype IN ('p', 'u', 'f')`,
+		names,
+	).Scan(&constraints).Error
+	if err != nil {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeInternal,
+			"Failed to read table constraints",
+			err,
+		)
+	}
+
+	var comments []tableCommentInfo
+	err = database.Conn(ctx, r.db).Raw(
+		`SELECT cls.relname AS table_name, d.description
+		FROM pg_description d
+		JOIN pg_class cls ON cls.oid = d.objoid
+		JOIN pg_namespace ns ON ns.oid = cls.relnamespace
+		WHERE d.classoid = 'pg_class'::regclass
+			AND d.objsubid = 0
+			AND ns.nspname = current_schema() AND cls.relname IN ?`,
+		names,
+	).Scan(&comments).Error
+	if err != nil {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeInternal,
+			"Failed to read table comment",
+			err,
+		)
+	}
+
+	// 制約が作ったインデックスはカラムの PrimaryKey / Unique として表現されるため除く
+	var indexes []indexInfo
+	err = database.Conn(ctx, r.db).Raw(
+		`SELECT tbl.relname AS table_name, idx.relname AS index_name, i.indisunique AS is_unique,
+			am.amname AS method,
+			array_to_string(ARRAY(
+				SELECT att.attname
+				FROM unnest(i.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute att ON att.attrelid = i.indrelid AND att.attnum = k.attnum
+				ORDER BY k.ord
+			), ',') AS column_names
+		FROM pg_index i
+		JOIN pg_class tbl ON tbl.oid = i.indrelid
+		JOIN pg_class idx ON idx.oid = i.indexrelid
+		JOIN pg_am am ON am.oid = idx.relam
+		JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+		WHERE ns.nspname = current_schema() AND tbl.relname IN ?
+			AND i.indexprs IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM pg_constraint con
+				WHERE con.conindid = i.indexrelid AND con.contype IN ('p', 'u', 'x')
 			)
+		ORDER BY tbl.relname, idx.relname`,
+		names,
+	).Scan(&indexes).Error
+	if err != nil {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeInternal,
+			"Failed to read table indexes",
+			err,
+		)
+	}
+
+	enumValues, err := r.enumValues(ctx, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	columnsByTable := make(map[string][]columnInfo)
+	for _, info := range columns {
+		columnsByTable[info.TableName] = append(columnsByTable[info.TableName], info)
+	}
+	constraintsByTable := make(map[string][]constraintInfo)
+	for _, con := range constraints {
+		constraintsByTable[con.TableName] = append(constraintsByTable[con.TableName], con)
+	}
+	commentByTable := make(map[string]string)
+	for _, comment := range comments {
+		commentByTable[comment.TableName] = comment.Description
+	}
+	indexesByTable := make(map[string][]tableentity.Index)
+	for _, info := range indexes {
+		indexesByTable[info.TableName] = append(indexesByTable[info.TableName], tableentity.Index{
+			Name:    info.IndexName,
+			Columns: strings.Split(info.ColumnNames, ","),
+			Unique:  info.IsUnique,
+			Method:  tableentity.IndexMethod(info.Method),
+		})
+	}
+
+	for _, name := range names {
+		tableColumns, ok := columnsByTable[name]
+		if !ok {
+			continue
 		}
+		table := buildDescribedTable(name, tableColumns, constraintsByTable[name], enumValues)
+		table.Description = commentByTable[name]
+		table.Indexes = indexesByTable[name]
+		tables = append(tables, table)
 	}
+	return tables, nil
+}
 
+// buildDescribedTable assembles the columns of an introspected table
+func buildDescribedTable(tableName string, columns []columnInfo, constraints []constraintInfo, enumValues map[string][]string) *tableentity.Table {
+	isPrimaryKey := make(map[string]bool)
+	isUnique := make(map[string]bool)
+	relations := make(map[string]*tableentity.Relation)
+	for _, con := range constraints {
+		switch {
+		case con.ConstraintType == "p":
+			isPrimaryKey[con.ColumnName] = true
+		case con.ConstraintType == "u" && con.KeyCount == 1:
+			// 複合ユニーク制約はカラム単位の Unique では表現できない
+			isUnique[con.ColumnName] = true
+		case con.ConstraintType == "f" && con.KeyCount == 1 && con.ReferencedTable != nil:
+			relation := &tableentity.Relation{
+				Type:  tableentity.RelationOneToMany,
+				Table: *con.ReferencedTable,
+			}
+			if con.ReferencedColumn != nil {
+				relation.Column = *con.ReferencedColumn
+			}
+			if con.DeleteAction != nil && *con.DeleteAction != "a" {
+				relation.OnDelete = referentialActions[*con.DeleteAction]
+			}
+			if con.UpdateAction != nil && *con.UpdateAction != "a" {
+				relation.OnUpdate = referentialActions[*con.UpdateAction]
+			}
+			relations[con.ColumnName] = relation
+		}
+	}
+
+	table := &tableentity.Table{Name: tableName}
+	for _, info := range columns {
+		// バージョン列はシステム列なので定義には含めない
+		if info.ColumnName == tableentity.VersionColumn {
+			continue
+		}
+		col := tableentity.Column{
+			Name:       info.ColumnName,
+			Length:     info.CharacterMaximumLength,
+			NotNull:    info.IsNullable == "NO",
+			PrimaryKey: isPrimaryKey[info.ColumnName],
+			Unique:     isUnique[info.ColumnName],
+			Relation:   relations[info.ColumnName],
+		}
+		switch info.DataType {
+		case "ARRAY":
+			col.Type = tableentity.TypeARRAY
+			col.ElementType = columnTypeFromUDT(strings.TrimPrefix(info.UDTName, "_"))
+		case "USER-DEFINED":
+			col.Type = tableentity.TypeENUM
+			col.EnumName = info.UDTName
+			col.EnumValues = enumValues[info.UDTName]
+		default:
+			col.Type = columnTypeFromUDT(info.UDTName)
+		}
+		if col.Type == tableentity.TypeNUMERIC {
+			col.Precision = info.NumericPrecision
+			col.Scale = info.NumericScale
+		}
+		if info.Description != nil {
+			col.Description = *info.Description
+		}
+
+		// シーケンスや UUID 生成関数を使うデフォルト値は自動採番として扱う。
+		// gen_random_uuid の既定値は検証時に自動採番へ正規化されている
+		if info.ColumnDefault != nil {
+			if strings.HasPrefix(*info.ColumnDefault, "nextval(") ||
+				(col.Type == tableentity.TypeUUID && *info.ColumnDefault == "gen_random_uuid()") {
+				col.AutoIncrement = true
+			} else {
+				col.Default = parseColumnDefault(col, *info.ColumnDefault)
+			}
+		}
+
+		table.Columns = append(table.Columns, col)
+	}
+	return table
+}
+
+// enumValues reads the labels of the enum types used by the given columns, in sort order
+func (r *TableRepository) enumValues(ctx context.Context, columns []columnInfo) (map[string][]string, error) {
+	var typeNames []string
+	for _, info := range columns {
+		if info.DataType == "USER-DEFINED" {
+			typeNames = append(typeNames, info.UDTName)
+		}
+	}
+	return r.enumLabels(ctx, typeNames)
+}
+
+// enumLabels reads the labels of the named enum types in sort order. Types that do not exist are left out.
+func (r *TableRepository) enumLabels(ctx context.Context, typeNames []string) (map[string][]string, error) {
+	values := make(map[string][]string)
+	if len(typeNames) == 0 {
+		return values, nil
+	}
+
+	var labels []enumValueInfo
+	err := database.Conn(ctx, r.db).Raw(
+		`SELECT t.typname AS type_name, e.enumlabel AS enum_label
+		FROM pg_enum e
+		JOIN pg_type t ON t.oid = e.enumtypid
+		WHERE t.typname IN ?
+		ORDER BY t.typname, e.enumsortorder`,
+		typeNames,
+	).Scan(&labels).Error
+	if err != nil {
+		return nil, errors.NewAppError(
+			errors.ErrorTypeInternal,
+			"Failed to read enum values",
+			err,
+		)
+	}
+	for _, label := range labels {
+		values[label.TypeName] = append(values[label.TypeName], label.EnumLabel)
+	}
+	return values, nil
+}
+
+// udtTypes maps PostgreSQL internal type names to column types
+var udtTypes = map[string]tableentity.ColumnType{
+	"varchar":     tableentity.TypeVARCHAR,
+	"text":        tableentity.TypeTEXT,
+	"int4":        tableentity.TypeINT,
+	"int8":        tableentity.TypeBIGINT,
+	"float8":      tableentity.TypeDOUBLE,
+	"bool":        tableentity.TypeBOOLEAN,
+	"date":        tableentity.TypeDATE,
+	"timestamp":   tableentity.TypeTIMESTAMP,
+	"timestamptz": tableentity.TypeTIMESTAMPTZ,
+	"time":        tableentity.TypeTIME,
+	"jsonb":       tableentity.TypeJSON,
+	"uuid":        tableentity.TypeUUID,
+	"numeric":     tableentity.TypeNUMERIC,
+	"bytea":       tableentity.TypeBYTEA,
+	"inet":        tableentity.TypeINET,
+	"cidr":        tableentity.TypeCIDR,
+}
+
+func columnTypeFromUDT(udtName string) tableentity.ColumnType {
+	if columnType, ok := udtTypes[udtName]; ok {
+		return columnType
+	}
+	return tableentity.ColumnType(udtName)
+}
+
+// schemaStatements are DDL statements in run order. EnumValues add labels to enum types that
+// were committed before the transaction; Postgres refuses to use a label in the transaction
+// that added it, so they run and commit on their own before Statements.
+type schemaStatements struct {
+	EnumValues []string
+	Statements []string
+}
+
+func (s schemaStatements) all() []string {
+	return append(slices.Clone(s.EnumValues), s.Statements...)
+}
+
+// exec runs the enum labels outside the transaction bound to ctx, then the other statements in it.
+// Labels that were added stay when the rest fails; ADD VALUE IF NOT EXISTS makes a retry safe.
+func (r *TableRepository) exec(ctx context.Context, s schemaStatements, message string) error {
+	for _, stmt := range s.EnumValues {
+		if err := r.db.WithContext(ctx).Exec(stmt).Error; err != nil {
+			return errors.NewAppError(errors.ErrorTypeInternal, message, err)
+		}
+	}
+	for _, stmt := range s.Statements {
+		if err := database.Conn(ctx, r.db).Exec(stmt).Error; err != nil {
+			return errors.NewAppError(errors.ErrorTypeInternal, message, err)
+		}
+	}
 	return nil
 }
 
-func buildCreateTableSQL(table *tableentity.Table) string {
-	var columnDefs []string
+func (r *TableRepository) CreateTable(ctx context.Context, table *tableentity.Table) error {
+	statements, err := r.createTableStatements(ctx, table)
+	if err != nil {
+		return err
+	}
+	return r.exec(ctx, statements, "Failed to create table")
+}
+
+// CreateTableStatements builds the statements CreateTable runs, in order. Enum types that
+// already exist are shared rather than created again.
+func (r *TableRepository) CreateTableStatements(ctx context.Context, table *tableentity.Table) ([]string, error) {
+	statements, err := r.createTableStatements(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	return statements.all(), nil
+}
+
+func (r *TableRepository) createTableStatements(ctx context.Context, table *tableentity.Table) (schemaStatements, error) {
+	var statements schemaStatements
 
+	// enum 型はテーブルより先に作成する
+	declared := make(map[string][]string)
 	for _, col := range table.Columns {
-		def := fmt.Sprintf("%s %s", col.Name, col.Type)
+		if col.Type == tableentity.TypeENUM {
+			if err := r.enumTypeStatements(ctx, col, declared, &statements); err != nil {
+				return schemaStatements{}, err
+			}
+		}
+	}
 
-		if col.Type == tableentity.TypeVARCHAR && col.Length != nil {
-			def = fmt.Sprintf("%s(%d)", def, *col.Length)
+	statements.Statements = append(statements.Statements, buildCreateTableSQL(table))
+
+	// 新規テーブルは空なのでインデックスはロックを気にせず作成できる
+	for _, index := range table.Indexes {
+		statements.Statements = append(statements.Statements, buildCreateIndexSQL(table.Name, index, false))
+	}
+
+	// テーブルにコメントが指定されている場合
+	if table.Description != "" {
+		statements.Statements = append(statements.Statements, fmt.Sprintf(
+			"COMMENT ON TABLE %s IS %s",
+			quoteIdentifier(table.Name),
+			quoteLiteral(table.Description),
+		))
+	}
+
+	return statements, nil
+}
+
+// CreateIndex builds an index. Concurrent builds must not run inside a transaction.
+func (r *TableRepository) CreateIndex(ctx context.Context, tableName string, index tableentity.Index, concurrently bool) error {
+	if err := database.Conn(ctx, r.db).Exec(buildCreateIndexSQL(tableName, index, concurrently)).Error; err != nil {
+		if concurrently {
+			// 失敗した CONCURRENTLY ビルドは INVALID なインデックスを残すため削除する
+			if dropErr := r.db.WithContext(ctx).Exec(buildDropIndexSQL(index.Name, true)).Error; dropErr != nil {
+				logger.Warn("Failed to drop invalid index after a failed build", "index", index.Name, "error", dropErr)
+			}
 		}
+		return errors.NewAppError(
+			errors.ErrorTypeInternal,
+			fmt.Sprintf("Failed to create index '%s'", index.Name),
+			err,
+		)
+	}
+	return nil
+}
+
+// CreateIndexStatement builds the statement CreateIndex runs
+func (r *TableRepository) CreateIndexStatement(tableName string, index tableentity.Index, concurrently bool) string {
+	return buildCreateIndexSQL(tableName, index, concurrently)
+}
+
+// DropTable removes a table if it still exists
+func (r *TableRepository) DropTable(ctx context.Context, tableName string) error {
+	if err := database.Conn(ctx, r.db).Exec(r.DropTableStatement(tableName)).Error; err != nil {
+		return errors.NewAppError(
+			errors.ErrorTypeInternal,
+			fmt.Sprintf("Failed to drop table '%s'", tableName),
+			err,
+		)
+	}
+	return nil
+}
+
+// DropTableStatement builds the statement DropTable runs
+func (r *TableRepository) DropTableStatement(tableName string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s", quoteIdentifier(tableName))
+}
+
+// TableHasData reports whether a table holds at least one row
+func (r *TableRepository) TableHasData(ctx context.Context, tableName string) (bool, error) {
+	var exists bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s)", quoteIdentifier(tableName))
+	if err := database.Conn(ctx, r.db).Raw(query).Scan(&exists).Error; err != nil {
+		return false, errors.NewAppError(
+			errors.ErrorTypeInternal,
+			fmt.Sprintf("Failed to check data of table '%s'", tableName),
+			err,
+		)
+	}
+	return exists, nil
+}
 
-		if col.NotNull {
-			def += " NOT NULL"
+// DropIndex removes an index if it still exists
+func (r *TableRepository) DropIndex(ctx context.Context, indexName string, concurrently bool) error {
+	if err := database.Conn(ctx, r.db).Exec(buildDropIndexSQL(indexName, concurrently)).Error; err != nil {
+		return errors.NewAppError(
+			errors.ErrorTypeInternal,
+			fmt.Sprintf("Failed to drop index '%s'", indexName),
+			err,
+		)
+	}
+	return nil
+}
+
+// DropIndexStatement builds the statement DropIndex runs
+func (r *TableRepository) DropIndexStatement(indexName string, concurrently bool) string {
+	return buildDropIndexSQL(indexName, concurrently)
+}
+
+// EstimateRowCount returns the planner's row estimate for a table
+func (r *TableRepository) EstimateRowCount(ctx context.Context, tableName string) (int64, error) {
+	var counts []int64
+	err := database.Conn(ctx, r.db).Raw(
+		"SELECT GREATEST(reltuples, 0)::bigint FROM pg_class WHERE oid = to_regclass(quote_ident(?))",
+		tableName,
+	).Scan(&counts).Error
+	if err != nil {
+		return 0, errors.NewAppError(
+			errors.ErrorTypeInternal,
+			"Failed to estimate table size",
+			err,
+		)
+	}
+	if len(counts) == 0 {
+		return 0, nil
+	}
+	return counts[0], nil
+}
+
+// ColumnHasData reports whether any row holds a non-null value in the column
+func (r *TableRepository) ColumnHasData(ctx context.Context, tableName, columnName string) (bool, error) {
+	var exists bool
+	err := database.Conn(ctx, r.db).Raw(
+		fmt.Sprintf(
+			"SELECT EXISTS (SELECT 1 FROM %s WHERE %s IS NOT NULL)",
+			quoteIdentifier(tableName),
+			quoteIdentifier(columnName),
+		),
+	).Scan(&exists).Error
+	if err != nil {
+		return false, errors.NewAppError(
+			errors.ErrorTypeInternal,
+			"Failed to check column data",
+			err,
+		)
+	}
+	return exists, nil
+}
+
+// AlterTable applies column changes in order. The caller is expected to run it inside a transaction.
+func (r *TableRepository) AlterTable(ctx context.Context, tableName string, changes []tableentity.ColumnChange) error {
+	statements, err := r.alterTableStatements(ctx, tableName, changes)
+	if err != nil {
+		return err
+	}
+	return r.exec(ctx, statements, "Failed to alter table")
+}
+
+// AlterTableStatements builds the statements AlterTable runs. Unique constraint names are looked up in the database.
+// Published snapshots of the table, when there are any, are rewritten along with the columns
+// so that published reads keep seeing their values.
+func (r *TableRepository) AlterTableStatements(ctx context.Context, tableName string, changes []tableentity.ColumnChange) ([]string, error) {
+	statements, err := r.alterTableStatements(ctx, tableName, changes)
+	if err != nil {
+		return nil, err
+	}
+	return statements.all(), nil
+}
+
+func (r *TableRepository) alterTableStatements(ctx context.Context, tableName string, changes []tableentity.ColumnChange) (schemaStatements, error) {
+	snapshots, err := r.hasSnapshots(ctx, tableName)
+	if err != nil {
+		return schemaStatements{}, err
+	}
+
+	// 列から外れる enum 型は、最後に他の列が使っていなければ削除する
+	declared := make(map[string][]string)
+	released := make(map[string][]string)
+	var enumNames []string
+	for _, change := range changes {
+		if releasesEnum(change) {
+			name := change.From.EnumName
+			if _, ok := released[name]; !ok {
+				enumNames = append(enumNames, name)
+			}
+			released[name] = append(released[name], change.From.Name)
 		}
+	}
 
-		if col.PrimaryKey {
-			def += " PRIMARY KEY"
-			if col.AutoIncrement {
-				if col.Type == tableentity.TypeINT {
-					def = fmt.Sprintf("%s SERIAL PRIMARY KEY", col.Name)
-				} else if col.Type == tableentity.TypeBIGINT {
-					def = fmt.Sprintf("%s BIGSERIAL PRIMARY KEY", col.Name)
+	var out schemaStatements
+	for _, change := range changes {
+		prefix := fmt.Sprintf("ALTER TABLE %s", quoteIdentifier(tableName))
+		column := quoteIdentifier(change.Column)
+
+		switch change.Kind {
+		case tableentity.ChangeRenameColumn:
+			out.Statements = append(out.Statements, fmt.Sprintf("%s RENAME COLUMN %s TO %s", prefix, quoteIdentifier(change.From.Name), quoteIdentifier(change.To.Name)))
+			if snapshots {
+				out.Statements = append(out.Statements, updateSnapshotsSQL(tableName, change.From.Name, fmt.Sprintf(
+					"(data - %s) || jsonb_build_object(%s, data -> %s)",
+					quoteLiteral(change.From.Name), quoteLiteral(change.To.Name), quoteLiteral(change.From.Name),
+				)))
+			}
+		case tableentity.ChangeDropUnique:
+			// 制約名はリネーム前のカラム名で引く
+			constraint, err := r.uniqueConstraintName(ctx, tableName, change.From.Name)
+			if err != nil {
+				return schemaStatements{}, err
+			}
+			out.Statements = append(out.Statements, fmt.Sprintf("%s DROP CONSTRAINT %s", prefix, quoteIdentifier(constraint)))
+		case tableentity.ChangeDropColumn:
+			out.Statements = append(out.Statements, fmt.Sprintf("%s DROP COLUMN %s", prefix, column))
+			if snapshots {
+				out.Statements = append(out.Statements, updateSnapshotsSQL(tableName, change.Column, "data - "+quoteLiteral(change.Column)))
+			}
+		case tableentity.ChangeAddEnumValue:
+			if err := r.enumValueStatements(ctx, change.To.EnumName, change.To.EnumValues[len(change.From.EnumValues):], &out); err != nil {
+				return schemaStatements{}, err
+			}
+		case tableentity.ChangeAddColumn:
+			if change.To.Type == tableentity.TypeENUM {
+				if err := r.enumTypeStatements(ctx, *change.To, declared, &out); err != nil {
+					return schemaStatements{}, err
 				}
 			}
+			out.Statements = append(out.Statements, fmt.Sprintf("%s ADD COLUMN %s", prefix, buildColumnDefinition(*change.To)))
+		case tableentity.ChangeAlterType:
+			if change.To.Type == tableentity.TypeENUM && change.From.EnumName != change.To.EnumName {
+				if err := r.enumTypeStatements(ctx, *change.To, declared, &out); err != nil {
+					return schemaStatements{}, err
+				}
+			}
+			typeSQL := columnTypeSQL(*change.To)
+			if snapshots {
+				// 変換前の型で読み出せるうちに、列と同じ式でスナップショットの値を変換する
+				value := fmt.Sprintf("(jsonb_populate_record(NULL::%s, data)).%s", quoteIdentifier(tableName), column)
+				out.Statements = append(out.Statements, updateSnapshotsSQL(tableName, change.Column, fmt.Sprintf(
+					"jsonb_set(data, ARRAY[%s], COALESCE(to_jsonb(%s), 'null'::jsonb))",
+					quoteLiteral(change.Column), r.convertUsing(*change.From, *change.To, value, typeSQL),
+				)))
+			}
+			out.Statements = append(out.Statements, fmt.Sprintf(
+				"%s ALTER COLUMN %s TYPE %s USING %s",
+				prefix, column, typeSQL, r.convertUsing(*change.From, *change.To, column, typeSQL),
+			))
+		case tableentity.ChangeDropDefault:
+			out.Statements = append(out.Statements, fmt.Sprintf("%s ALTER COLUMN %s DROP DEFAULT", prefix, column))
+		case tableentity.ChangeSetDefault:
+			out.Statements = append(out.Statements, fmt.Sprintf("%s ALTER COLUMN %s SET DEFAULT %s", prefix, column, buildDefaultSQL(*change.To)))
+		case tableentity.ChangeDropNotNull:
+			out.Statements = append(out.Statements, fmt.Sprintf("%s ALTER COLUMN %s DROP NOT NULL", prefix, column))
+		case tableentity.ChangeSetNotNull:
+			out.Statements = append(out.Statements, fmt.Sprintf("%s ALTER COLUMN %s SET NOT NULL", prefix, column))
+		case tableentity.ChangeAddUnique:
+			out.Statements = append(out.Statements, fmt.Sprintf("%s ADD UNIQUE (%s)", prefix, column))
+		}
+	}
+
+	for _, name := range enumNames {
+		if _, ok := declared[name]; ok {
+			continue
+		}
+		referenced, err := r.enumReferenced(ctx, name, tableName, released[name])
+		if err != nil {
+			return schemaStatements{}, err
+		}
+		if !referenced {
+			out.Statements = append(out.Statements, fmt.Sprintf("DROP TYPE IF EXISTS %s", quoteIdentifier(name)))
+		}
+	}
+	return out, nil
+}
+
+// releasesEnum reports whether a change stops its column from using an enum type
+func releasesEnum(change tableentity.ColumnChange) bool {
+	if change.From == nil || change.From.Type != tableentity.TypeENUM {
+		return false
+	}
+	switch change.Kind {
+	case tableentity.ChangeDropColumn:
+		return true
+	case tableentity.ChangeAlterType:
+		return change.To.Type != tableentity.TypeENUM || change.To.EnumName != change.From.EnumName
+	}
+	return false
+}
+
+// enumTypeStatements makes the enum type of a column available. A type declared earlier in the
+// same batch needs nothing, a new type is created, and a type shared with other tables gets the
+// values appended to the declaration. declared collects the types of the batch.
+func (r *TableRepository) enumTypeStatements(ctx context.Context, col tableentity.Column, declared map[string][]string, out *schemaStatements) error {
+	mismatch := func(values []string) error {
+		return errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("Enum type '%s' of column '%s' is already declared with values %s", col.EnumName, col.Name, strings.Join(values, ", ")),
+			nil,
+		)
+	}
+
+	if values, ok := declared[col.EnumName]; ok {
+		if !slices.Equal(values, col.EnumValues) {
+			return mismatch(values)
 		}
+		return nil
+	}
+	declared[col.EnumName] = col.EnumValues
+
+	existing, err := r.enumLabels(ctx, []string{col.EnumName})
+	if err != nil {
+		return err
+	}
+	current, ok := existing[col.EnumName]
+	if !ok {
+		out.Statements = append(out.Statements, buildCreateEnumSQL(col))
+		return nil
+	}
+	// 共有された型には値の追加だけを行う
+	if len(col.EnumValues) < len(current) || !slices.Equal(current, col.EnumValues[:len(current)]) {
+		return mismatch(current)
+	}
+	return r.enumValueStatements(ctx, col.EnumName, col.EnumValues[len(current):], out)
+}
 
-		if col.Unique {
-			def += " UNIQUE"
+// enumValueStatements appends labels to an existing enum type. The labels of a type that only
+// exists in the current transaction, created there for another table, stay in the transaction.
+func (r *TableRepository) enumValueStatements(ctx context.Context, enumName string, values []string, out *schemaStatements) error {
+	if len(values) == 0 {
+		return nil
+	}
+	var committed bool
+	err := r.db.WithContext(ctx).Raw("SELECT to_regtype(quote_ident(?)) IS NOT NULL", enumName).Scan(&committed).Error
+	if err != nil {
+		return errors.NewAppError(
+			errors.ErrorTypeInternal,
+			"Failed to look up enum type",
+			err,
+		)
+	}
+	for _, value := range values {
+		stmt := fmt.Sprintf("ALTER TYPE %s ADD VALUE IF NOT EXISTS %s", quoteIdentifier(enumName), quoteLiteral(value))
+		if committed {
+			out.EnumValues = append(out.EnumValues, stmt)
+		} else {
+			out.Statements = append(out.Statements, stmt)
 		}
+	}
+	return nil
+}
+
+// enumReferenced reports whether any column uses an enum type, or arrays of it, besides the
+// given columns of tableName
+func (r *TableRepository) enumReferenced(ctx context.Context, enumName, tableName string, columns []string) (bool, error) {
+	var referenced bool
+	err := database.Conn(ctx, r.db).Raw(
+		`SELECT EXISTS (
+			SELECT 1
+			FROM pg_attribute a
+			JOIN pg_class c ON c.oid = a.attrelid
+			JOIN pg_type t ON a.atttypid IN (t.oid, t.typarray)
+			WHERE t.oid = to_regtype(quote_ident(?))
+				AND c.relkind IN ('r', 'p', 'v', 'm', 'f', 'c')
+				AND a.attnum > 0
+				AND NOT a.attisdropped
+				AND NOT (c.oid = to_regclass(quote_ident(?)) AND a.attname IN ?)
+		)`,
+		enumName,
+		tableName,
+		columns,
+	).Scan(&referenced).Error
+	if err != nil {
+		return false, errors.NewAppError(
+			errors.ErrorTypeInternal,
+			"Failed to look up enum type usage",
+			err,
+		)
+	}
+	return referenced, nil
+}
 
-		if col.Default != nil {
-			def += fmt.Sprintf(" DEFAULT %s", *col.Default)
+// hasSnapshots reports whether records of the table have snapshots kept by the content workflow
+func (r *TableRepository) hasSnapshots(ctx context.Context, tableName string) (bool, error) {
+	var exists bool
+	err := database.Conn(ctx, r.db).Raw(
+		"SELECT EXISTS (SELECT 1 FROM record_publications WHERE table_name = ? AND data IS NOT NULL)",
+		tableName,
+	).Scan(&exists).Error
+	if err != nil {
+		return false, errors.NewAppError(
+			errors.ErrorTypeInternal,
+			"Failed to check published snapshots",
+			err,
+		)
+	}
+	return exists, nil
+}
+
+// updateSnapshotsSQL rewrites the snapshots of a table that hold a value for column
+func updateSnapshotsSQL(tableName, column, data string) string {
+	return fmt.Sprintf(
+		"UPDATE record_publications SET data = %s WHERE table_name = %s AND data -> %s IS NOT NULL",
+		data, quoteLiteral(tableName), quoteLiteral(column),
+	)
+}
+
+// convertUsing is the USING expression of a type change. Values of a column that becomes
+// localized are kept as the default locale translation, and the reverse keeps only that translation.
+func (r *TableRepository) convertUsing(from, to tableentity.Column, column, typeSQL string) string {
+	locale := quoteLiteral(r.defaultLocale)
+	switch {
+	case !from.Localized && to.Localized:
+		return fmt.Sprintf("CASE WHEN %s IS NULL THEN NULL ELSE jsonb_build_object(%s, %s::text) END", column, locale, column)
+	case from.Localized && !to.Localized:
+		return fmt.Sprintf("(%s->>%s)::%s", column, locale, typeSQL)
+	}
+	return fmt.Sprintf("%s::%s", column, typeSQL)
+}
+
+func (r *TableRepository) uniqueConstraintName(ctx context.Context, tableName, columnName string) (string, error) {
+	var names []string
+	err := database.Conn(ctx, r.db).Raw(
+		`SELECT con.conname
+		FROM pg_constraint con
+		JOIN pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = con.conkey[1]
+		WHERE con.conrelid = to_regclass(quote_ident(?))
+			AND con.contype = 'u'
+			AND array_length(con.conkey, 1) = 1
+			AND att.attname = ?`,
+		tableName,
+		columnName,
+	).Scan(&names).Error
+	if err != nil {
+		return "", errors.NewAppError(
+			errors.ErrorTypeInternal,
+			"Failed to look up unique constraint",
+			err,
+		)
+	}
+	if len(names) == 0 {
+		return "", errors.NewAppError(
+			errors.ErrorTypeValidation,
+			fmt.Sprintf("No unique constraint found on column '%s'", columnName),
+			nil,
+		)
+	}
+	return names[0], nil
+}
+
+func buildCreateIndexSQL(tableName string, index tableentity.Index, concurrently bool) string {
+	sql := "CREATE"
+	if index.Unique {
+		sql += " UNIQUE"
+	}
+	sql += " INDEX"
+	if concurrently {
+		sql += " CONCURRENTLY"
+	}
+	sql += fmt.Sprintf(" %s ON %s", quoteIdentifier(index.Name), quoteIdentifier(tableName))
+	if index.Method != "" {
+		sql += " USING " + string(index.Method)
+	}
+	sql += fmt.Sprintf(" (%s)", strings.Join(quoteIdentifiers(index.Columns), ", "))
+
+	if len(index.Where) > 0 {
+		var conditions []string
+		for _, cond := range index.Where {
+			conditions = append(conditions, buildIndexCondition(cond))
 		}
+		sql += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return sql
+}
 
-		columnDefs = append(columnDefs, def)
+func buildDropIndexSQL(indexName string, concurrently bool) string {
+	if concurrently {
+		return "DROP INDEX CONCURRENTLY IF EXISTS " + quoteIdentifier(indexName)
 	}
+	return "DROP INDEX IF EXISTS " + quoteIdentifier(indexName)
+}
+
+func buildIndexCondition(cond tableentity.IndexCondition) string {
+	column := quoteIdentifier(cond.Column)
+	switch cond.Operator {
+	case tableentity.ConditionIsNull:
+		return column + " IS NULL"
+	case tableentity.ConditionIsNotNull:
+		return column + " IS NOT NULL"
+	case tableentity.ConditionNotEqual:
+		return fmt.Sprintf("%s <> %s", column, quoteLiteral(cond.Value))
+	default:
+		return fmt.Sprintf("%s = %s", column, quoteLiteral(cond.Value))
+	}
+}
+
+func buildCreateTableSQL(table *tableentity.Table) string {
+	var columnDefs []string
+
+	for _, col := range table.Columns {
+		if col.IsVirtual() {
+			continue
+		}
+		columnDefs = append(columnDefs, buildColumnDefinition(col))
+	}
+	columnDefs = append(columnDefs, quoteIdentifier(tableentity.VersionColumn)+" BIGINT NOT NULL DEFAULT 1")
 
 	return fmt.Sprintf(
 		"CREATE TABLE %s (\n  %s\n)",
-		table.Name,
+		quoteIdentifier(table.Name),
 		strings.Join(columnDefs, ",\n  "),
 	)
 }
+
+func buildColumnDefinition(col tableentity.Column) string {
+	name := quoteIdentifier(col.Name)
+	def := fmt.Sprintf("%s %s", name, columnTypeSQL(col))
+
+	if col.NotNull {
+		def += " NOT NULL"
+	}
+
+	if col.PrimaryKey {
+		def += " PRIMARY KEY"
+		if col.AutoIncrement {
+			if col.Type == tableentity.TypeINT {
+				def = fmt.Sprintf("%s SERIAL PRIMARY KEY", name)
+			} else if col.Type == tableentity.TypeBIGINT {
+				def = fmt.Sprintf("%s BIGSERIAL PRIMARY KEY", name)
+			}
+		}
+	}
+
+	if col.Unique {
+		def += " UNIQUE"
+	}
+
+	if col.Default != nil {
+		def += " DEFAULT " + buildDefaultSQL(col)
+	} else if col.AutoIncrement && col.Type == tableentity.TypeUUID {
+		def += " DEFAULT gen_random_uuid()"
+	}
+
+	if col.Type == tableentity.TypeASSET {
+		def += buildReferencesClause(tableentity.AssetRelation())
+	} else if col.Relation != nil {
+		def += buildReferencesClause(col.Relation)
+	}
+
+	return def
+}
+
+func buildReferencesClause(relation *tableentity.Relation) string {
+	clause := fmt.Sprintf(" REFERENCES %s (%s)", quoteIdentifier(relation.Table), quoteIdentifier(relation.Column))
+	if relation.OnDelete != "" {
+		clause += " ON DELETE " + string(relation.OnDelete)
+	}
+	if relation.OnUpdate != "" {
+		clause += " ON UPDATE " + string(relation.OnUpdate)
+	}
+	return clause
+}
+
+func columnTypeSQL(col tableentity.Column) string {
+	if col.Localized {
+		return string(tableentity.TypeJSON)
+	}
+	switch col.Type {
+	case tableentity.TypeVARCHAR:
+		if col.Length != nil {
+			return fmt.Sprintf("%s(%d)", col.Type, *col.Length)
+		}
+	case tableentity.TypeNUMERIC:
+		if col.Precision != nil && col.Scale != nil {
+			return fmt.Sprintf("%s(%d,%d)", col.Type, *col.Precision, *col.Scale)
+		}
+		if col.Precision != nil {
+			return fmt.Sprintf("%s(%d)", col.Type, *col.Precision)
+		}
+	case tableentity.TypeARRAY:
+		element := tableentity.Column{Type: col.ElementType, Length: col.Length, Precision: col.Precision, Scale: col.Scale}
+		return columnTypeSQL(element) + "[]"
+	case tableentity.TypeENUM:
+		return quoteIdentifier(col.EnumName)
+	case tableentity.TypeASSET:
+		return string(tableentity.TypeUUID)
+	}
+	return string(col.Type)
+}
+
+func buildCreateEnumSQL(col tableentity.Column) string {
+	values := make([]string, len(col.EnumValues))
+	for i, value := range col.EnumValues {
+		values[i] = quoteLiteral(value)
+	}
+	return fmt.Sprintf("CREATE TYPE %s AS ENUM (%s)", quoteIdentifier(col.EnumName), strings.Join(values, ", "))
+}
@@ -0,0 +1,301 @@
+// File: internal/infrastructure/repository/table_repository_test.go
+
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"quickflow/internal/domain/tableentity"
+	"quickflow/internal/infrastructure/database"
+)
+
+// ddl keeps the schema statements of the log along with the transaction boundaries
+func ddl(log []fakeStatement) []fakeStatement {
+	var out []fakeStatement
+	for _, stmt := range log {
+		switch {
+		case stmt.SQL == "BEGIN", stmt.SQL == "COMMIT", stmt.SQL == "ROLLBACK",
+			strings.HasPrefix(stmt.SQL, "CREATE "), strings.HasPrefix(stmt.SQL, "ALTER "):
+			out = append(out, stmt)
+		}
+	}
+	return out
+}
+
+func assertStatements(t *testing.T, got, want []fakeStatement) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d statements %v, want %d %v", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("statement %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAlterTableAddsEnumValuesBeforeTransaction(t *testing.T) {
+	db, fake := newFakeGorm(t,
+		fakeResult{Match: "SELECT to_regtype", Columns: []string{"exists"}, Rows: [][]driver.Value{{true}}},
+	)
+	repo := NewTableRepository(db, "en")
+
+	from := tableentity.Column{Name: "status", Type: tableentity.TypeENUM, EnumName: "article_status", EnumValues: []string{"draft", "published"}}
+	withValue := from
+	withValue.EnumValues = []string{"draft", "published", "archived"}
+	withDefault := withValue
+	withDefault.Default = &tableentity.DefaultValue{Value: "archived"}
+
+	err := database.NewTxManager(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+		return repo.AlterTable(ctx, "articles", []tableentity.ColumnChange{
+			{Kind: tableentity.ChangeAddEnumValue, Column: "status", From: &from, To: &withValue},
+			{Kind: tableentity.ChangeSetDefault, Column: "status", From: &withValue, To: &withDefault},
+		})
+	})
+	if err != nil {
+		t.Fatalf("AlterTable: %v", err)
+	}
+
+	// 新しい値は先にコミットされ、既定値はトランザクション内で設定される
+	assertStatements(t, ddl(fake.statements()), []fakeStatement{
+		{SQL: "BEGIN", InTx: true},
+		{SQL: `ALTER TYPE "article_status" ADD VALUE IF NOT EXISTS 'archived'`},
+		{SQL: `ALTER TABLE "articles" ALTER COLUMN "status" SET DEFAULT 'archived'::"article_status"`, InTx: true},
+		{SQL: "COMMIT", InTx: true},
+	})
+}
+
+func TestCreateTableSharedEnumValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		committed bool
+		wantInTx  bool
+	}{
+		{name: "committed type is extended outside the transaction", committed: true},
+		{name: "type created in the same transaction is extended inside it", wantInTx: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeGorm(t,
+				fakeResult{Match: "SELECT to_regtype", Columns: []string{"exists"}, Rows: [][]driver.Value{{tt.committed}}},
+				fakeResult{Match: "pg_enum", Columns: []string{"type_name", "enum_label"}, Rows: [][]driver.Value{
+					{"article_status", "draft"},
+				}},
+			)
+			repo := NewTableRepository(db, "en")
+
+			table := &tableentity.Table{
+				Name: "pages",
+				Columns: []tableentity.Column{
+					{Name: "id", Type: tableentity.TypeBIGINT, PrimaryKey: true},
+					{
+						Name: "status", Type: tableentity.TypeENUM, EnumName: "article_status",
+						EnumValues: []string{"draft", "archived"},
+						Default:    &tableentity.DefaultValue{Value: "archived"},
+					},
+				},
+			}
+			err := database.NewTxManager(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+				return repo.CreateTable(ctx, table)
+			})
+			if err != nil {
+				t.Fatalf("CreateTable: %v", err)
+			}
+
+			got := ddl(fake.statements())
+			addValue := -1
+			createTable := -1
+			for i, stmt := range got {
+				if stmt.SQL == `ALTER TYPE "article_status" ADD VALUE IF NOT EXISTS 'archived'` {
+					addValue = i
+					if stmt.InTx != tt.wantInTx {
+						t.Errorf("ADD VALUE InTx = %v, want %v", stmt.InTx, tt.wantInTx)
+					}
+				}
+				if strings.HasPrefix(stmt.SQL, `CREATE TABLE "pages"`) {
+					createTable = i
+					if !stmt.InTx {
+						t.Error("CREATE TABLE ran outside the transaction")
+					}
+				}
+			}
+			if addValue < 0 || createTable < 0 || addValue > createTable {
+				t.Errorf("ADD VALUE at %d, CREATE TABLE at %d in %v", addValue, createTable, got)
+			}
+		})
+	}
+}
+
+func TestAlterTableStatements(t *testing.T) {
+	title := tableentity.Column{Name: "title", Type: tableentity.TypeTEXT}
+	headline := tableentity.Column{Name: "headline", Type: tableentity.TypeTEXT}
+	uniqueTitle := tableentity.Column{Name: "title", Type: tableentity.TypeTEXT, Unique: true}
+	localizedTitle := tableentity.Column{Name: "title", Type: tableentity.TypeTEXT, Localized: true}
+	views := tableentity.Column{Name: "views", Type: tableentity.TypeINT}
+	bigViews := tableentity.Column{Name: "views", Type: tableentity.TypeBIGINT}
+	requiredViews := tableentity.Column{Name: "views", Type: tableentity.TypeINT, NotNull: true, Default: &tableentity.DefaultValue{Value: 0.0}}
+	status := tableentity.Column{
+		Name: "status", Type: tableentity.TypeENUM, EnumName: "articles_status",
+		EnumValues: []string{"draft", "published"}, NotNull: true,
+		Default: &tableentity.DefaultValue{Value: "draft"},
+	}
+	archivable := status
+	archivable.EnumValues = []string{"draft", "published", "archived"}
+
+	snapshots := fakeResult{Match: "FROM record_publications", Columns: []string{"exists"}, Rows: [][]driver.Value{{true}}}
+
+	tests := []struct {
+		name    string
+		changes []tableentity.ColumnChange
+		results []fakeResult
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "rename",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeRenameColumn, Column: "headline", From: &title, To: &headline}},
+			want:    []string{`ALTER TABLE "articles" RENAME COLUMN "title" TO "headline"`},
+		},
+		{
+			name:    "rename rewrites snapshots",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeRenameColumn, Column: "headline", From: &title, To: &headline}},
+			results: []fakeResult{snapshots},
+			want: []string{
+				`ALTER TABLE "articles" RENAME COLUMN "title" TO "headline"`,
+				`UPDATE record_publications SET data = (data - 'title') || jsonb_build_object('headline', data -> 'title') WHERE table_name = 'articles' AND data -> 'title' IS NOT NULL`,
+			},
+		},
+		{
+			name:    "drop column rewrites snapshots",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeDropColumn, Column: "title", From: &title}},
+			results: []fakeResult{snapshots},
+			want: []string{
+				`ALTER TABLE "articles" DROP COLUMN "title"`,
+				`UPDATE record_publications SET data = data - 'title' WHERE table_name = 'articles' AND data -> 'title' IS NOT NULL`,
+			},
+		},
+		{
+			name:    "drop unique uses the constraint name",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeDropUnique, Column: "title", From: &uniqueTitle, To: &title}},
+			results: []fakeResult{{Match: "pg_constraint", Columns: []string{"conname"}, Rows: [][]driver.Value{{"articles_title_key"}}}},
+			want:    []string{`ALTER TABLE "articles" DROP CONSTRAINT "articles_title_key"`},
+		},
+		{
+			name:    "drop unique without a constraint",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeDropUnique, Column: "title", From: &uniqueTitle, To: &title}},
+			wantErr: true,
+		},
+		{
+			name:    "alter type",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeAlterType, Column: "views", From: &views, To: &bigViews}},
+			want:    []string{`ALTER TABLE "articles" ALTER COLUMN "views" TYPE bigint USING "views"::bigint`},
+		},
+		{
+			name:    "localize keeps the value as the default locale",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeAlterType, Column: "title", From: &title, To: &localizedTitle}},
+			results: []fakeResult{snapshots},
+			want: []string{
+				`UPDATE record_publications SET data = jsonb_set(data, ARRAY['title'], COALESCE(to_jsonb(` +
+					`CASE WHEN (jsonb_populate_record(NULL::"articles", data))."title" IS NULL THEN NULL ` +
+					`ELSE jsonb_build_object('en', (jsonb_populate_record(NULL::"articles", data))."title"::text) END` +
+					`), 'null'::jsonb)) WHERE table_name = 'articles' AND data -> 'title' IS NOT NULL`,
+				`ALTER TABLE "articles" ALTER COLUMN "title" TYPE jsonb USING CASE WHEN "title" IS NULL THEN NULL ELSE jsonb_build_object('en', "title"::text) END`,
+			},
+		},
+		{
+			name:    "unlocalize keeps the default locale",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeAlterType, Column: "title", From: &localizedTitle, To: &title}},
+			want:    []string{`ALTER TABLE "articles" ALTER COLUMN "title" TYPE text USING ("title"->>'en')::text`},
+		},
+		{
+			name:    "add enum column creates the type",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeAddColumn, Column: "status", To: &status}},
+			want: []string{
+				`CREATE TYPE "articles_status" AS ENUM ('draft', 'published')`,
+				`ALTER TABLE "articles" ADD COLUMN "status" "articles_status" NOT NULL DEFAULT 'draft'::"articles_status"`,
+			},
+		},
+		{
+			name:    "add enum column shares an existing type",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeAddColumn, Column: "status", To: &status}},
+			results: []fakeResult{{Match: "pg_enum", Columns: []string{"type_name", "enum_label"}, Rows: [][]driver.Value{
+				{"articles_status", "draft"}, {"articles_status", "published"},
+			}}},
+			want: []string{`ALTER TABLE "articles" ADD COLUMN "status" "articles_status" NOT NULL DEFAULT 'draft'::"articles_status"`},
+		},
+		{
+			name:    "add enum column with other values than the existing type",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeAddColumn, Column: "status", To: &status}},
+			results: []fakeResult{{Match: "pg_enum", Columns: []string{"type_name", "enum_label"}, Rows: [][]driver.Value{
+				{"articles_status", "published"},
+			}}},
+			wantErr: true,
+		},
+		{
+			name:    "drop the last enum column drops the type",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeDropColumn, Column: "status", From: &status}},
+			want: []string{
+				`ALTER TABLE "articles" DROP COLUMN "status"`,
+				`DROP TYPE IF EXISTS "articles_status"`,
+			},
+		},
+		{
+			name:    "drop an enum column whose type is still used",
+			changes: []tableentity.ColumnChange{{Kind: tableentity.ChangeDropColumn, Column: "status", From: &status}},
+			results: []fakeResult{{Match: "pg_attribute", Columns: []string{"exists"}, Rows: [][]driver.Value{{true}}}},
+			want:    []string{`ALTER TABLE "articles" DROP COLUMN "status"`},
+		},
+		{
+			name: "enum values come first",
+			changes: []tableentity.ColumnChange{
+				{Kind: tableentity.ChangeAddEnumValue, Column: "status", From: &status, To: &archivable},
+				{Kind: tableentity.ChangeDropNotNull, Column: "status", From: &archivable, To: &archivable},
+			},
+			results: []fakeResult{{Match: "SELECT to_regtype", Columns: []string{"exists"}, Rows: [][]driver.Value{{true}}}},
+			want: []string{
+				`ALTER TYPE "articles_status" ADD VALUE IF NOT EXISTS 'archived'`,
+				`ALTER TABLE "articles" ALTER COLUMN "status" DROP NOT NULL`,
+			},
+		},
+		{
+			name: "constraints and defaults",
+			changes: []tableentity.ColumnChange{
+				{Kind: tableentity.ChangeDropDefault, Column: "title", From: &title, To: &title},
+				{Kind: tableentity.ChangeSetDefault, Column: "views", From: &views, To: &requiredViews},
+				{Kind: tableentity.ChangeSetNotNull, Column: "views", From: &views, To: &requiredViews},
+				{Kind: tableentity.ChangeAddUnique, Column: "title", From: &title, To: &uniqueTitle},
+			},
+			want: []string{
+				`ALTER TABLE "articles" ALTER COLUMN "title" DROP DEFAULT`,
+				`ALTER TABLE "articles" ALTER COLUMN "views" SET DEFAULT 0::integer`,
+				`ALTER TABLE "articles" ALTER COLUMN "views" SET NOT NULL`,
+				`ALTER TABLE "articles" ADD UNIQUE ("title")`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := newFakeGorm(t, tt.results...)
+			repo := NewTableRepository(db, "en")
+
+			got, err := repo.AlterTableStatements(context.Background(), "articles", tt.changes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AlterTableStatements error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d statements:\n%s\nwant %d:\n%s", len(got), strings.Join(got, "\n"), len(tt.want), strings.Join(tt.want, "\n"))
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("statement %d = %s\nwant %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
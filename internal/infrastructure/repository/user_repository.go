@@ -38,8 +38,21 @@ func (r *UserRepository) GetByEmail(email string) (*user.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) Update(user *user.User) error {
-	return r.db.Save(user).Error
+// Update saves the user only if it still has the version it was read with,
+// then bumps the version
+func (r *UserRepository) Update(u *user.User) error {
+	current := u.Version
+	u.Version++
+	result := r.db.Model(u).Where("version = ?", current).Select("*").Updates(u)
+	if result.Error != nil {
+		u.Version = current
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		u.Version = current
+		return user.ErrVersionConflict
+	}
+	return nil
 }
 
 func (r *UserRepository) Delete(id uint) error {
@@ -0,0 +1,94 @@
+// File: internal/infrastructure/storage/local.go
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"quickflow/pkg/errors"
+)
+
+// Local keeps assets as files below a root directory
+type Local struct {
+	root string
+}
+
+func NewLocal(root string) (*Local, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset directory %s: %w", root, err)
+	}
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create asset directory %s: %w", abs, err)
+	}
+	return &Local{root: abs}, nil
+}
+
+// Put writes the content to a temporary file first so that readers never see a partial file
+func (l *Local) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to store asset", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to store asset", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to store asset", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to store asset", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to store asset", err)
+	}
+	return nil
+}
+
+func (l *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, errors.NewAppError(errors.ErrorTypeNotFound, "Asset content not found", err)
+	}
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInternal, "Failed to read asset", err)
+	}
+	return f, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.NewAppError(errors.ErrorTypeInternal, "Failed to delete asset", err)
+	}
+	return nil
+}
+
+// path maps a storage key into the root directory and refuses keys that would leave it
+func (l *Local) path(key string) (string, error) {
+	path := filepath.Join(l.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, l.root+string(filepath.Separator)) {
+		return "", errors.NewAppError(errors.ErrorTypeInternal, fmt.Sprintf("Invalid asset storage key: %s", key), nil)
+	}
+	return path, nil
+}
@@ -0,0 +1,270 @@
+// File: internal/infrastructure/storage/s3_test.go
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"quickflow/config"
+	"quickflow/pkg/errors"
+)
+
+// fakeS3 is an in-memory S3 that checks Signature Version 4 like AWS does
+type fakeS3 struct {
+	cfg       config.S3Config
+	mu        sync.Mutex
+	objects   map[string][]byte
+	types     map[string]string
+	failWrite bool
+}
+
+func newFakeS3(cfg config.S3Config) *fakeS3 {
+	return &fakeS3{cfg: cfg, objects: map[string][]byte{}, types: map[string]string{}}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !f.validSignature(r) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+		return
+	}
+
+	key, ok := f.objectKey(r)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "<Error><Code>NoSuchBucket</Code></Error>")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		if f.failWrite {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, "<Error><Code>SlowDown</Code></Error>")
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(body)) != r.ContentLength {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		w.Write(body)
+	case http.MethodDelete:
+		// S3 は存在しないキーの削除にも 204 を返す
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// objectKey reads the key from a path style or a virtual hosted style request
+func (f *fakeS3) objectKey(r *http.Request) (string, bool) {
+	if f.cfg.UsePathStyle {
+		return strings.CutPrefix(r.URL.Path, "/"+f.cfg.Bucket+"/")
+	}
+	if !strings.HasPrefix(r.Host, f.cfg.Bucket+".") {
+		return "", false
+	}
+	return strings.TrimPrefix(r.URL.Path, "/"), true
+}
+
+// validSignature recomputes the signature from the headers the request says it signed
+func (f *fakeS3) validSignature(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	credential, rest, ok := strings.Cut(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 Credential="), ", SignedHeaders=")
+	if !ok {
+		return false
+	}
+	signedHeaders, signature, ok := strings.Cut(rest, ", Signature=")
+	if !ok {
+		return false
+	}
+	accessKey, scope, _ := strings.Cut(credential, "/")
+	if accessKey != f.cfg.AccessKeyID {
+		return false
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	payloadHash := r.Header.Get("x-amz-content-sha256")
+	canonicalRequest := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), r.URL.RawQuery, canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+	sum := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + r.Header.Get("x-amz-date") + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	parts := strings.Split(scope, "/")
+	if len(parts) != 4 || parts[1] != f.cfg.Region || parts[2] != "s3" {
+		return false
+	}
+	key := []byte("AWS4" + f.cfg.SecretAccessKey)
+	for _, part := range parts {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	return hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(signature))
+}
+
+// newTestS3 serves a fake bucket and returns a client for it. Virtual hosted style requests
+// are sent to the fake whatever their host name.
+func newTestS3(t *testing.T, pathStyle bool) (*S3, *fakeS3) {
+	t.Helper()
+	cfg := config.S3Config{
+		Region:          "ap-northeast-1",
+		Bucket:          "assets",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		UsePathStyle:    pathStyle,
+	}
+	fake := newFakeS3(cfg)
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Endpoint = "http://s3.test:" + u.Port()
+	s := NewS3(cfg)
+	s.client = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, u.Host)
+		},
+	}}
+	return s, fake
+}
+
+func TestS3RoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		pathStyle bool
+		key       string
+	}{
+		{"path style", true, "2026/10/0b8f7f5e-8d8e-4c5b-9a3f-2f7d5e6c1a2b.png"},
+		{"virtual hosted style", false, "2026/10/0b8f7f5e-8d8e-4c5b-9a3f-2f7d5e6c1a2b.png"},
+		{"key needing escapes", true, "2026/10/a b+c=d/写真.jpg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fake := newTestS3(t, tt.pathStyle)
+			ctx := context.Background()
+			content := []byte("image content")
+
+			if err := s.Put(ctx, tt.key, bytes.NewReader(content), int64(len(content)), "image/png"); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			if got := fake.types[tt.key]; got != "image/png" {
+				t.Errorf("stored content type = %q, want image/png", got)
+			}
+
+			body, err := s.Open(ctx, tt.key)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			got, err := io.ReadAll(body)
+			body.Close()
+			if err != nil {
+				t.Fatalf("reading content: %v", err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("content = %q, want %q", got, content)
+			}
+
+			if err := s.Delete(ctx, tt.key); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, ok := fake.objects[tt.key]; ok {
+				t.Errorf("object %s still stored after Delete", tt.key)
+			}
+		})
+	}
+}
+
+func TestS3Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		run      func(ctx context.Context, s *S3, fake *fakeS3) error
+		wantType errors.ErrorType
+	}{
+		{
+			name: "open missing object",
+			run: func(ctx context.Context, s *S3, _ *fakeS3) error {
+				_, err := s.Open(ctx, "missing.png")
+				return err
+			},
+			wantType: errors.ErrorTypeNotFound,
+		},
+		{
+			name: "delete missing object",
+			run: func(ctx context.Context, s *S3, _ *fakeS3) error {
+				return s.Delete(ctx, "missing.png")
+			},
+		},
+		{
+			name: "put rejected by the service",
+			run: func(ctx context.Context, s *S3, fake *fakeS3) error {
+				fake.failWrite = true
+				return s.Put(ctx, "a.png", strings.NewReader("x"), 1, "image/png")
+			},
+			wantType: errors.ErrorTypeInternal,
+		},
+		{
+			name: "wrong secret key",
+			run: func(ctx context.Context, s *S3, _ *fakeS3) error {
+				s.cfg.SecretAccessKey = "wrong"
+				return s.Put(ctx, "a.png", strings.NewReader("x"), 1, "image/png")
+			},
+			wantType: errors.ErrorTypeInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fake := newTestS3(t, true)
+			err := tt.run(context.Background(), s, fake)
+			if tt.wantType == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var appErr *errors.AppError
+			if !errors.As(err, &appErr) || appErr.Type != tt.wantType {
+				t.Fatalf("error = %v, want type %s", err, tt.wantType)
+			}
+		})
+	}
+}
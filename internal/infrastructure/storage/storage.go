@@ -0,0 +1,21 @@
+// File: internal/infrastructure/storage/storage.go
+
+package storage
+
+import (
+	"fmt"
+
+	"quickflow/config"
+	"quickflow/internal/application/asset"
+)
+
+// New returns the asset storage backend selected by the configuration
+func New(cfg config.StorageConfig) (asset.Storage, error) {
+	switch cfg.Backend {
+	case "local":
+		return NewLocal(cfg.LocalDir)
+	case "s3":
+		return NewS3(cfg.S3), nil
+	}
+	return nil, fmt.Errorf("unknown asset storage backend: %s", cfg.Backend)
+}
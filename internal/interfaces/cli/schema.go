@@ -0,0 +1,101 @@
+// File: internal/interfaces/cli/schema.go
+
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"quickflow/internal/application/table"
+)
+
+const schemaUsage = `usage: quickflow schema <plan|apply> -f <file> [--confirm-destructive]
+
+  plan    show the changes needed to match the schema file and any drift
+  apply   create and alter tables to match the schema file in one transaction`
+
+// RunSchema handles the "schema" subcommand
+func RunSchema(ctx context.Context, service *table.TableService, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", schemaUsage)
+	}
+	command := args[0]
+
+	flags := flag.NewFlagSet("schema "+command, flag.ContinueOnError)
+	flags.SetOutput(out)
+	file := flags.String("f", "", "path to a YAML or JSON schema file")
+	confirm := flags.Bool("confirm-destructive", false, "allow dropping columns with data and narrowing types")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("%s", schemaUsage)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	schemaFile, err := table.ParseSchemaFile(data)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "plan":
+		plan, err := service.PlanSchema(ctx, schemaFile)
+		if err != nil {
+			return err
+		}
+		printPlan(out, plan)
+		if !plan.HasChanges() {
+			fmt.Fprintln(out, "No changes. The database matches the schema file.")
+		}
+		return nil
+
+	case "apply":
+		plan, err := service.ApplySchema(ctx, schemaFile, *confirm, nil)
+		if err != nil {
+			return err
+		}
+		printPlan(out, plan)
+		fmt.Fprintln(out, "Apply complete.")
+		return nil
+	}
+
+	return fmt.Errorf("unknown schema command %q\n%s", command, schemaUsage)
+}
+
+func printPlan(out io.Writer, plan *table.SyncPlan) {
+	symbols := map[table.SyncAction]string{
+		table.SyncCreate:    "+",
+		table.SyncAlter:     "~",
+		table.SyncUnchanged: "=",
+	}
+
+	for _, t := range plan.Tables {
+		fmt.Fprintf(out, "%s %s (%s)\n", symbols[t.Action], t.Table, t.Action)
+		if t.Plan == nil {
+			continue
+		}
+		for _, step := range t.Plan.Steps {
+			fmt.Fprintf(out, "    - %s\n", step)
+		}
+		for _, warning := range t.Plan.Destructive {
+			fmt.Fprintf(out, "    ! destructive: %s\n", warning)
+		}
+		for _, stmt := range t.Plan.Statements {
+			fmt.Fprintf(out, "    %s;\n", stmt)
+		}
+	}
+
+	if len(plan.Drift) > 0 {
+		fmt.Fprintln(out, "\nDrift:")
+		for _, drift := range plan.Drift {
+			fmt.Fprintf(out, "  ! %s\n", drift)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// File: internal/interfaces/httpserver/handler/asset_handler.go
+
+package handler
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"quickflow/internal/application/asset"
+	"quickflow/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// multipartOverhead leaves room for the boundaries and headers of a multipart upload
+const multipartOverhead = 1 << 20
+
+type AssetHandler struct {
+	service *asset.Service
+}
+
+func NewAssetHandler(service *asset.Service) *AssetHandler {
+	return &AssetHandler{service: service}
+}
+
+// UploadAsset stores the file sent in the "file" field of a multipart form
+func (h *AssetHandler) UploadAsset(c echo.Context) error {
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, h.service.MaxSize()+multipartOverhead)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "Uploaded file is too large"})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Missing file field"})
+	}
+	file, err := header.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid file"})
+	}
+	defer file.Close()
+
+	created, err := h.service.Upload(req.Context(), asset.Upload{
+		Filename:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Body:        file,
+	})
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+func (h *AssetHandler) ListAssets(c echo.Context) error {
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid limit"})
+	}
+
+	result, err := h.service.List(c.Request().Context(), asset.ListParams{
+		Limit:  limit,
+		Cursor: c.QueryParam("cursor"),
+	})
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetAsset answers with the metadata of an asset. Transformation parameters serve the
+// transformed image instead, as GetAssetContent does.
+func (h *AssetHandler) GetAsset(c echo.Context) error {
+	params, err := transformParams(c)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+	if !params.IsZero() {
+		return h.GetAssetContent(c)
+	}
+
+	found, err := h.service.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, found)
+}
+
+// GetAssetContent serves the content of an asset, or of a derivative when transformation
+// parameters (w, h, fit, format, q, crop) are given
+func (h *AssetHandler) GetAssetContent(c echo.Context) error {
+	params, err := transformParams(c)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	found, err := h.service.Get(ctx, c.Param("id"))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	if params.IsZero() {
+		disposition := "attachment"
+		if found.IsImage() {
+			disposition = "inline"
+		}
+		return serveContent(c, found.MimeType, found.Size, found.Checksum, disposition, found.Filename, func() (io.ReadCloser, error) {
+			return h.service.Content(ctx, found)
+		})
+	}
+
+	derivative, err := h.service.Transform(ctx, found, params)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+	filename := strings.TrimSuffix(found.Filename, path.Ext(found.Filename)) + path.Ext(derivative.Variant)
+	return serveContent(c, derivative.MimeType, derivative.Size, derivative.Checksum, "inline", filename, func() (io.ReadCloser, error) {
+		return h.service.DerivativeContent(ctx, derivative)
+	})
+}
+
+func (h *AssetHandler) DeleteAsset(c echo.Context) error {
+	if err := h.service.Delete(c.Request().Context(), c.Param("id")); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// serveContent streams stored content. Content never changes, so it is cached for good and
+// revalidated by its checksum. Uploaded files are untrusted; they are sandboxed and served
+// with their recorded type only.
+func serveContent(c echo.Context, mimeType string, size int64, checksum, disposition, filename string, open func() (io.ReadCloser, error)) error {
+	header := c.Response().Header()
+	etag := `"` + checksum + `"`
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	if ifNoneMatch(c, etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	content, err := open()
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+	defer content.Close()
+
+	// 書式化できないファイル名は付けずに返す
+	if formatted := mime.FormatMediaType(disposition, map[string]string{"filename": filename}); formatted != "" {
+		disposition = formatted
+	}
+	header.Set("Content-Disposition", disposition)
+	header.Set("Content-Length", strconv.FormatInt(size, 10))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+
+	return c.Stream(http.StatusOK, mimeType, content)
+}
+
+// transformParams reads the image transformation query parameters
+func transformParams(c echo.Context) (asset.TransformParams, error) {
+	params := asset.TransformParams{
+		Fit:    c.QueryParam("fit"),
+		Format: c.QueryParam("format"),
+		Crop:   c.QueryParam("crop"),
+	}
+	for _, p := range []struct {
+		name  string
+		value *int
+	}{{"w", &params.Width}, {"h", &params.Height}, {"q", &params.Quality}} {
+		v, err := queryInt(c, p.name)
+		if err != nil {
+			return asset.TransformParams{}, errors.NewAppError(errors.ErrorTypeValidation, fmt.Sprintf("Invalid %s", p.name), nil)
+		}
+		*p.value = v
+	}
+	return params, nil
+}
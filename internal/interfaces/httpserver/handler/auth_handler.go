@@ -0,0 +1,37 @@
+// File: internal/interfaces/httpserver/handler/auth_handler.go
+
+package handler
+
+import (
+	"net/http"
+
+	"quickflow/internal/application/auth"
+	"quickflow/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AuthHandler struct {
+	service *auth.Service
+}
+
+func NewAuthHandler(service *auth.Service) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+func (h *AuthHandler) Login(c echo.Context) error {
+	var request struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	token, err := h.service.Login(request.Email, request.Password)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, token)
+}
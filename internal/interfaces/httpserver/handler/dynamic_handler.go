@@ -1 +1,375 @@
+// File: internal/interfaces/httpserver/handler/dynamic_handler.go
+
 package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"quickflow/internal/application/dynamicapi"
+	"quickflow/internal/domain/record"
+	"quickflow/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DynamicHandler struct {
+	service *dynamicapi.RecordService
+}
+
+func NewDynamicHandler(service *dynamicapi.RecordService) *DynamicHandler {
+	return &DynamicHandler{service: service}
+}
+
+func (h *DynamicHandler) ListRecords(c echo.Context) error {
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid limit"})
+	}
+	includeTotal, _ := strconv.ParseBool(c.QueryParam("total"))
+	locale, err := h.readLocale(c)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	result, err := h.service.List(c.Request().Context(), c.Param("table"), dynamicapi.ListParams{
+		Limit:        limit,
+		Filters:      filterParams(c.QueryParams()),
+		Sort:         c.QueryParam("sort"),
+		Cursor:       c.QueryParam("cursor"),
+		IncludeTotal: includeTotal,
+		Fields:       queryList(c, "fields"),
+		Expand:       queryList(c, "expand"),
+		Locale:       locale,
+	})
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetRecord answers with the record and its ETag, or 304 when If-None-Match still matches.
+// Expanded responses carry no ETag because related records change independently.
+func (h *DynamicHandler) GetRecord(c echo.Context) error {
+	locale, err := h.readLocale(c)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+	params := dynamicapi.GetParams{
+		Fields: queryList(c, "fields"),
+		Expand: queryList(c, "expand"),
+		Locale: locale,
+	}
+	rec, version, err := h.service.Get(c.Request().Context(), c.Param("table"), c.Param("id"), params)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	if len(params.Expand) == 0 {
+		setETag(c, version)
+		if notModified(c, version) {
+			return c.NoContent(http.StatusNotModified)
+		}
+	}
+	return c.JSON(http.StatusOK, rec)
+}
+
+func (h *DynamicHandler) CreateRecord(c echo.Context) error {
+	payload, err := decodePayload(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	rec, version, err := h.service.Create(c.Request().Context(), c.Param("table"), payload)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	setETag(c, version)
+	return c.JSON(http.StatusCreated, rec)
+}
+
+// UpdateRecord honours If-Match and answers 412 when the record changed in the meantime
+func (h *DynamicHandler) UpdateRecord(c echo.Context) error {
+	payload, err := decodePayload(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	rec, version, err := h.service.Update(c.Request().Context(), c.Param("table"), c.Param("id"), payload, ifMatchVersions(c))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	setETag(c, version)
+	return c.JSON(http.StatusOK, rec)
+}
+
+// PatchRecord applies an application/merge-patch+json or application/json-patch+json
+// document to a record, honouring If-Match like UpdateRecord
+func (h *DynamicHandler) PatchRecord(c echo.Context) error {
+	patch, status, err := decodePatch(c)
+	if err != nil {
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	rec, version, err := h.service.Patch(c.Request().Context(), c.Param("table"), c.Param("id"), patch, ifMatchVersions(c))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	setETag(c, version)
+	return c.JSON(http.StatusOK, rec)
+}
+
+func (h *DynamicHandler) DeleteRecord(c echo.Context) error {
+	if err := h.service.Delete(c.Request().Context(), c.Param("table"), c.Param("id")); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// BulkRecords applies one operation to an array of records. An atomic request that
+// was rolled back answers 422 with the per record results.
+func (h *DynamicHandler) BulkRecords(c echo.Context) error {
+	decoder := json.NewDecoder(c.Request().Body)
+	decoder.UseNumber()
+
+	var req dynamicapi.BulkRequest
+	if err := decoder.Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	result, err := h.service.Bulk(c.Request().Context(), c.Param("table"), req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	if !result.Committed {
+		return c.JSON(http.StatusUnprocessableEntity, result)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// TransitionRecord applies the workflow action named by the last path segment,
+// e.g. POST /api/:table/:id/publish
+func (h *DynamicHandler) TransitionRecord(action dynamicapi.WorkflowAction) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		state, err := h.service.Transition(c.Request().Context(), c.Param("table"), c.Param("id"), action)
+		if err != nil {
+			return errors.HandleHTTPError(c, err)
+		}
+
+		return c.JSON(http.StatusOK, state)
+	}
+}
+
+// GetRecordState returns the workflow state of a record
+func (h *DynamicHandler) GetRecordState(c echo.Context) error {
+	state, err := h.service.State(c.Request().Context(), c.Param("table"), c.Param("id"))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, state)
+}
+
+// ScheduleRecord replaces the publish_at and unpublish_at times of a record
+func (h *DynamicHandler) ScheduleRecord(c echo.Context) error {
+	var schedule record.Schedule
+	if err := c.Bind(&schedule); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	state, err := h.service.Schedule(c.Request().Context(), c.Param("table"), c.Param("id"), schedule)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, state)
+}
+
+// MoveRecordStage moves a draft to another review stage
+func (h *DynamicHandler) MoveRecordStage(c echo.Context) error {
+	var req dynamicapi.StageRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	state, err := h.service.MoveStage(c.Request().Context(), c.Param("table"), c.Param("id"), req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, state)
+}
+
+// ListRecordComments returns the review comments of a record
+func (h *DynamicHandler) ListRecordComments(c echo.Context) error {
+	comments, err := h.service.Comments(c.Request().Context(), c.Param("table"), c.Param("id"))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": comments})
+}
+
+// AddRecordComment leaves a review comment, optionally requesting changes
+func (h *DynamicHandler) AddRecordComment(c echo.Context) error {
+	var req dynamicapi.CommentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	comment, err := h.service.AddComment(c.Request().Context(), c.Param("table"), c.Param("id"), req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, comment)
+}
+
+// GetRecordHistory returns the workflow transition log of a record
+func (h *DynamicHandler) GetRecordHistory(c echo.Context) error {
+	transitions, err := h.service.History(c.Request().Context(), c.Param("table"), c.Param("id"))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": transitions})
+}
+
+// ListRevisions returns the revisions of a record, newest first
+func (h *DynamicHandler) ListRevisions(c echo.Context) error {
+	revisions, err := h.service.Revisions(c.Request().Context(), c.Param("table"), c.Param("id"))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": revisions})
+}
+
+// GetRevision returns one revision of a record with its snapshot
+func (h *DynamicHandler) GetRevision(c echo.Context) error {
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid revision"})
+	}
+
+	rev, err := h.service.Revision(c.Request().Context(), c.Param("table"), c.Param("id"), revision)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, rev)
+}
+
+// DiffRevisions compares two revisions given as ?from=1&to=3
+func (h *DynamicHandler) DiffRevisions(c echo.Context) error {
+	from, err := strconv.Atoi(c.QueryParam("from"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from revision"})
+	}
+	to, err := strconv.Atoi(c.QueryParam("to"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to revision"})
+	}
+
+	diff, err := h.service.DiffRevisions(c.Request().Context(), c.Param("table"), c.Param("id"), from, to)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, diff)
+}
+
+// RestoreRevision writes an old revision of a record back
+func (h *DynamicHandler) RestoreRevision(c echo.Context) error {
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid revision"})
+	}
+
+	rec, version, err := h.service.RestoreRevision(c.Request().Context(), c.Param("table"), c.Param("id"), revision)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	setETag(c, version)
+	return c.JSON(http.StatusOK, rec)
+}
+
+// MissingTranslations lists records whose localized columns lack translations
+func (h *DynamicHandler) MissingTranslations(c echo.Context) error {
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid limit"})
+	}
+
+	result, err := h.service.MissingTranslations(c.Request().Context(), c.Param("table"), dynamicapi.TranslationParams{
+		Locale: c.QueryParam("locale"),
+		Limit:  limit,
+		Cursor: c.QueryParam("cursor"),
+	})
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// decodePayload reads a JSON object body keeping numbers as json.Number
+// so that bigint values survive without float rounding
+func decodePayload(c echo.Context) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(c.Request().Body)
+	decoder.UseNumber()
+
+	var payload map[string]interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// filterPattern matches filter[field] and filter[field][operator] query keys
+var filterPattern = regexp.MustCompile(`^filter\[([^\]]+)\](?:\[([^\]]+)\])?$`)
+
+// filterParams collects filter[field][operator]=value query parameters
+func filterParams(query url.Values) []dynamicapi.FilterParam {
+	var params []dynamicapi.FilterParam
+	for key, values := range query {
+		m := filterPattern.FindStringSubmatch(key)
+		if m == nil {
+			continue
+		}
+		for _, value := range values {
+			params = append(params, dynamicapi.FilterParam{Field: m[1], Operator: m[2], Value: value})
+		}
+	}
+	return params
+}
+
+// queryList reads a comma separated query parameter such as fields=id,title
+func queryList(c echo.Context, name string) []string {
+	var items []string
+	for _, item := range strings.Split(c.QueryParam(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func queryInt(c echo.Context, name string) (int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
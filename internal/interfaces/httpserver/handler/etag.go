@@ -0,0 +1,70 @@
+// File: internal/interfaces/httpserver/handler/etag.go
+
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// formatETag renders a row version as a strong entity tag
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setETag exposes a row version; version 0 means the row has none
+func setETag(c echo.Context, version int64) {
+	if version > 0 {
+		c.Response().Header().Set("ETag", formatETag(version))
+	}
+}
+
+// ifMatchVersions parses an If-Match header into the versions it accepts.
+// An absent header or * returns nil, which accepts any version. Weak or foreign
+// tags never match a strong comparison and are reported as version -1.
+func ifMatchVersions(c echo.Context) []int64 {
+	header := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	var versions []int64
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		version, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+		if err != nil || !strings.HasPrefix(tag, `"`) {
+			version = -1
+		}
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+// notModified reports whether an If-None-Match header matches the version, using the
+// weak comparison GET requests call for
+func notModified(c echo.Context, version int64) bool {
+	if version <= 0 {
+		return false
+	}
+	return ifNoneMatch(c, formatETag(version))
+}
+
+// ifNoneMatch reports whether an If-None-Match header matches the entity tag current
+func ifNoneMatch(c echo.Context, current string) bool {
+	header := strings.TrimSpace(c.Request().Header.Get("If-None-Match"))
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == current {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+// File: internal/interfaces/httpserver/handler/locale.go
+
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// readLocale resolves the locale of a record read from ?locale= or Accept-Language and
+// describes it in the response headers. locale=* keeps every translation.
+func (h *DynamicHandler) readLocale(c echo.Context) (string, error) {
+	requested := c.QueryParam("locale")
+	tag, err := h.service.ResolveLocale(requested, c.Request().Header.Get("Accept-Language"))
+	if err != nil {
+		return "", err
+	}
+
+	if requested == "" {
+		c.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+	}
+	if tag != "" {
+		c.Response().Header().Set("Content-Language", tag)
+	}
+	return tag, nil
+}
@@ -0,0 +1,36 @@
+// File: internal/interfaces/httpserver/handler/patch.go
+
+package handler
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"quickflow/pkg/jsonpatch"
+
+	"github.com/labstack/echo/v4"
+)
+
+// decodePatch reads a patch document of the request's content type and returns the
+// status code to answer with when it cannot
+func decodePatch(c echo.Context) (jsonpatch.Patcher, int, error) {
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err != nil || (mediaType != jsonpatch.MediaTypeMergePatch && mediaType != jsonpatch.MediaTypeJSONPatch) {
+		c.Response().Header().Set("Accept-Patch", jsonpatch.MediaTypeMergePatch+", "+jsonpatch.MediaTypeJSONPatch)
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf(
+			"Content-Type must be %s or %s", jsonpatch.MediaTypeMergePatch, jsonpatch.MediaTypeJSONPatch,
+		)
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("Invalid request body")
+	}
+	patch, err := jsonpatch.Parse(mediaType, body)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return patch, 0, nil
+}
@@ -1 +1,52 @@
+// File: internal/interfaces/httpserver/handler/schema_handler.go
+
 package handler
+
+import (
+	"net/http"
+
+	"quickflow/internal/application/schema"
+	"quickflow/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SchemaHandler struct {
+	service *schema.SchemaService
+}
+
+func NewSchemaHandler(service *schema.SchemaService) *SchemaHandler {
+	return &SchemaHandler{service: service}
+}
+
+func (h *SchemaHandler) ListSchemas(c echo.Context) error {
+	schemas, err := h.service.ListSchemas(c.Request().Context())
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, schemas)
+}
+
+func (h *SchemaHandler) GetSchema(c echo.Context) error {
+	entry, err := h.service.GetSchema(c.Request().Context(), c.Param("table"))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, entry)
+}
+
+func (h *SchemaHandler) UpdateMetadata(c echo.Context) error {
+	var request schema.UpdateMetadataRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	entry, err := h.service.UpdateMetadata(c.Request().Context(), c.Param("table"), request)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, entry)
+}
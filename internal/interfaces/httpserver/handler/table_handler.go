@@ -2,7 +2,12 @@
 package handler
 
 import (
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"quickflow/internal/application/auth"
 	"quickflow/internal/application/table"
 	"quickflow/internal/domain/tableentity"
 	"quickflow/pkg/errors"
@@ -26,7 +31,19 @@ func (h *TableHandler) CreateTable(c echo.Context) error {
 		})
 	}
 
-	if err := h.service.CreateTable(c.Request().Context(), &table); err != nil {
+	if dryRun(c) {
+		plan, err := h.service.PlanCreateTable(c.Request().Context(), &table)
+		if err != nil {
+			return errors.HandleHTTPError(c, err)
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"message": "Dry run: no changes were applied",
+			"table":   table,
+			"plan":    plan,
+		})
+	}
+
+	if err := h.service.CreateTable(c.Request().Context(), &table, currentUserID(c)); err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			return c.JSON(appErr.HTTPStatusCode(), map[string]string{
 				"error": appErr.Error(),
@@ -42,3 +59,121 @@ func (h *TableHandler) CreateTable(c echo.Context) error {
 		"table":   table,
 	})
 }
+
+func (h *TableHandler) ListTables(c echo.Context) error {
+	tables, err := h.service.ListTables(c.Request().Context())
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, tables)
+}
+
+func (h *TableHandler) DescribeTable(c echo.Context) error {
+	table, err := h.service.DescribeTable(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, table)
+}
+
+func (h *TableHandler) AlterTable(c echo.Context) error {
+	var table tableentity.Table
+	if err := c.Bind(&table); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+	if table.Name == "" {
+		table.Name = c.Param("name")
+	}
+	if !strings.EqualFold(table.Name, c.Param("name")) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Table name in body does not match the URL",
+		})
+	}
+
+	if dryRun(c) {
+		plan, err := h.service.PlanAlterTable(c.Request().Context(), &table)
+		if err != nil {
+			return errors.HandleHTTPError(c, err)
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"message": "Dry run: no changes were applied",
+			"table":   table,
+			"plan":    plan,
+		})
+	}
+
+	confirm, _ := strconv.ParseBool(c.QueryParam("confirm_destructive"))
+
+	result, err := h.service.AlterTable(c.Request().Context(), &table, confirm)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Table altered successfully",
+		"table":   table,
+		"result":  result,
+	})
+}
+
+// PlanSchema diffs a YAML or JSON schema file in the request body against the database
+func (h *TableHandler) PlanSchema(c echo.Context) error {
+	file, err := readSchemaFile(c)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	plan, err := h.service.PlanSchema(c.Request().Context(), file)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, plan)
+}
+
+// ApplySchema applies a YAML or JSON schema file in the request body
+func (h *TableHandler) ApplySchema(c echo.Context) error {
+	file, err := readSchemaFile(c)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	confirm, _ := strconv.ParseBool(c.QueryParam("confirm_destructive"))
+
+	plan, err := h.service.ApplySchema(c.Request().Context(), file, confirm, currentUserID(c))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Schema applied successfully",
+		"plan":    plan,
+	})
+}
+
+func readSchemaFile(c echo.Context) (*table.SchemaFile, error) {
+	data, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "Invalid request body", err)
+	}
+	return table.ParseSchemaFile(data)
+}
+
+// dryRun reports whether the request only asks for the generated SQL
+func dryRun(c echo.Context) bool {
+	dry, _ := strconv.ParseBool(c.QueryParam("dry_run"))
+	return dry
+}
+
+// currentUserID returns the ID of the authenticated user of the request, if any
+func currentUserID(c echo.Context) *uint {
+	if principal, ok := auth.PrincipalFrom(c.Request().Context()); ok {
+		id := principal.UserID
+		return &id
+	}
+	return nil
+}
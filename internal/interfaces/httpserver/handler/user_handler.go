@@ -3,6 +3,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -52,6 +53,10 @@ func (h *UserHandler) GetUser(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
 	}
 
+	setETag(c, user.Version)
+	if notModified(c, user.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
 	return c.JSON(http.StatusOK, user)
 }
 
@@ -73,14 +78,43 @@ func (h *UserHandler) UpdateUser(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 	}
 
-	updatedUser, err := h.userService.UpdateUser(uint(id), request.Username, request.FirstName, request.LastName, request.Email, request.PhoneNumber)
+	updatedUser, err := h.userService.UpdateUser(uint(id), ifMatchVersions(c), request.Username, request.FirstName, request.LastName, request.Email, request.PhoneNumber)
+	if errors.Is(err, user.ErrVersionMismatch) {
+		return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
+	setETag(c, updatedUser.Version)
 	return c.JSON(http.StatusOK, updatedUser)
 }
 
+// PatchUser applies an application/merge-patch+json or application/json-patch+json
+// document to the user's profile, honouring If-Match like UpdateUser
+func (h *UserHandler) PatchUser(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+	}
+
+	patch, status, err := decodePatch(c)
+	if err != nil {
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	patchedUser, err := h.userService.PatchUser(uint(id), ifMatchVersions(c), patch)
+	if errors.Is(err, user.ErrVersionMismatch) {
+		return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	setETag(c, patchedUser.Version)
+	return c.JSON(http.StatusOK, patchedUser)
+}
+
 func (h *UserHandler) UpdatePassword(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -111,14 +145,14 @@ func (h *UserHandler) UpdateProfileImage(c echo.Context) error {
 	}
 
 	var request struct {
-		ImageURL string `json:"image_url"`
+		AssetID string `json:"asset_id"`
 	}
 
 	if err := c.Bind(&request); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 	}
 
-	err = h.userService.UpdateProfileImage(uint(id), request.ImageURL)
+	err = h.userService.UpdateProfileImage(c.Request().Context(), uint(id), request.AssetID)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
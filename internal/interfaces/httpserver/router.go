@@ -3,24 +3,92 @@
 package httpserver
 
 import (
+	"quickflow/internal/application/dynamicapi"
+	"quickflow/internal/domain/user"
 	"quickflow/internal/interfaces/httpserver/handler"
+	"quickflow/internal/interfaces/httpserver/middleware"
 
 	"github.com/labstack/echo/v4"
 )
 
-func SetupRoutes(e *echo.Echo, userHandler *handler.UserHandler, statusHandler *handler.StatusHandler, healthHandler *handler.HealthHandler) {
+func SetupRoutes(e *echo.Echo, userHandler *handler.UserHandler, statusHandler *handler.StatusHandler, healthHandler *handler.HealthHandler, tableHandler *handler.TableHandler, dynamicHandler *handler.DynamicHandler, schemaHandler *handler.SchemaHandler, authHandler *handler.AuthHandler, assetHandler *handler.AssetHandler) {
+
+	// スキーマ変更は任意の DDL になるため管理者に限る
+	adminOnly := middleware.RequireRole(user.RoleAdmin)
+	// アカウントの変更は本人か管理者に限る
+	selfOrAdmin := middleware.RequireSelfOrRole("id", user.RoleAdmin)
 
 	// Status page route (root)
 	e.GET("/", statusHandler.HandleStatusPage)
 
+	// Authentication routes
+	e.POST("/auth/login", authHandler.Login)
+
 	// User routes
 	userGroup := e.Group("/users")
 	{
 		userGroup.POST("", userHandler.CreateUser)
 		userGroup.GET("/:id", userHandler.GetUser)
-		userGroup.PUT("/:id", userHandler.UpdateUser)
-		userGroup.PUT("/:id/password", userHandler.UpdatePassword)
-		userGroup.DELETE("/:id", userHandler.DeleteUser)
+		userGroup.PUT("/:id", userHandler.UpdateUser, selfOrAdmin)
+		userGroup.PATCH("/:id", userHandler.PatchUser, selfOrAdmin)
+		userGroup.PUT("/:id/password", userHandler.UpdatePassword, selfOrAdmin)
+		userGroup.PUT("/:id/profile-image", userHandler.UpdateProfileImage, selfOrAdmin)
+		userGroup.DELETE("/:id", userHandler.DeleteUser, selfOrAdmin)
+	}
+
+	// Asset routes
+	assetGroup := e.Group("/assets")
+	{
+		assetGroup.GET("", assetHandler.ListAssets)
+		assetGroup.POST("", assetHandler.UploadAsset)
+		assetGroup.GET("/:id", assetHandler.GetAsset)
+		assetGroup.GET("/:id/content", assetHandler.GetAssetContent)
+		assetGroup.DELETE("/:id", assetHandler.DeleteAsset)
+	}
+
+	// Table definition routes
+	tableGroup := e.Group("/tables")
+	{
+		tableGroup.GET("", tableHandler.ListTables)
+		tableGroup.POST("", tableHandler.CreateTable, adminOnly)
+		tableGroup.GET("/:name", tableHandler.DescribeTable)
+		tableGroup.PUT("/:name", tableHandler.AlterTable, adminOnly)
+	}
+
+	// Schema catalog routes
+	schemaGroup := e.Group("/schemas")
+	{
+		schemaGroup.GET("", schemaHandler.ListSchemas)
+		schemaGroup.POST("/plan", tableHandler.PlanSchema, adminOnly)
+		schemaGroup.POST("/apply", tableHandler.ApplySchema, adminOnly)
+		schemaGroup.GET("/:table", schemaHandler.GetSchema)
+		schemaGroup.PATCH("/:table", schemaHandler.UpdateMetadata, adminOnly)
+	}
+
+	// Record routes for user-defined tables
+	apiGroup := e.Group("/api")
+	{
+		apiGroup.GET("/:table", dynamicHandler.ListRecords)
+		apiGroup.POST("/:table", dynamicHandler.CreateRecord)
+		apiGroup.POST("/:table/bulk", dynamicHandler.BulkRecords)
+		apiGroup.GET("/:table/translations/missing", dynamicHandler.MissingTranslations)
+		apiGroup.GET("/:table/:id", dynamicHandler.GetRecord)
+		apiGroup.PUT("/:table/:id", dynamicHandler.UpdateRecord)
+		apiGroup.PATCH("/:table/:id", dynamicHandler.PatchRecord)
+		apiGroup.DELETE("/:table/:id", dynamicHandler.DeleteRecord)
+		apiGroup.GET("/:table/:id/workflow", dynamicHandler.GetRecordState)
+		apiGroup.PUT("/:table/:id/schedule", dynamicHandler.ScheduleRecord)
+		apiGroup.POST("/:table/:id/stage", dynamicHandler.MoveRecordStage)
+		apiGroup.GET("/:table/:id/comments", dynamicHandler.ListRecordComments)
+		apiGroup.POST("/:table/:id/comments", dynamicHandler.AddRecordComment)
+		apiGroup.GET("/:table/:id/history", dynamicHandler.GetRecordHistory)
+		apiGroup.GET("/:table/:id/revisions", dynamicHandler.ListRevisions)
+		apiGroup.GET("/:table/:id/revisions/diff", dynamicHandler.DiffRevisions)
+		apiGroup.GET("/:table/:id/revisions/:revision", dynamicHandler.GetRevision)
+		apiGroup.POST("/:table/:id/revisions/:revision/restore", dynamicHandler.RestoreRevision)
+		apiGroup.POST("/:table/:id/publish", dynamicHandler.TransitionRecord(dynamicapi.ActionPublish))
+		apiGroup.POST("/:table/:id/unpublish", dynamicHandler.TransitionRecord(dynamicapi.ActionUnpublish))
+		apiGroup.POST("/:table/:id/archive", dynamicHandler.TransitionRecord(dynamicapi.ActionArchive))
 	}
 
 	e.GET("/health", healthHandler.Handle)
@@ -11,12 +11,21 @@ import (
 	"time"
 
 	"quickflow/config"
+	"quickflow/internal/application/asset"
+	"quickflow/internal/application/auth"
+	"quickflow/internal/application/dynamicapi"
 	"quickflow/internal/application/health"
+	"quickflow/internal/application/schema"
+	"quickflow/internal/application/table"
 	"quickflow/internal/application/user"
+	"quickflow/internal/application/validation"
 	"quickflow/internal/infrastructure/database"
 	"quickflow/internal/infrastructure/repository"
+	"quickflow/internal/infrastructure/storage"
+	"quickflow/internal/interfaces/cli"
 	"quickflow/internal/interfaces/httpserver"
 	"quickflow/internal/interfaces/httpserver/handler"
+	appmiddleware "quickflow/internal/interfaces/httpserver/middleware"
 	"quickflow/pkg/logger"
 
 	"github.com/labstack/echo/v4"
@@ -54,12 +63,49 @@ func run() error {
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	locales := cfg.LocaleSettings()
+	tableRepo := repository.NewTableRepository(db, locales.Default())
+	dynamicRepo := repository.NewDynamicRepository(db)
+	publishedRepo := repository.NewPublishedRepository(db)
+	publicationRepo := repository.NewPublicationRepository(db)
+	reviewRepo := repository.NewReviewRepository(db)
+	revisionRepo := repository.NewRevisionRepository(db)
+	schemaRepo := repository.NewSchemaRepository(db)
+	assetRepo := repository.NewAssetRepository(db)
+	txManager := database.NewTxManager(db)
+
+	// Initialize asset storage
+	assetStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		return err
+	}
 
 	// Initialize application services
-	userService := user.NewUserService(userRepo)
+	assetService := asset.NewService(assetRepo, assetStorage, int64(cfg.Storage.MaxUploadMB)<<20, asset.TransformLimits{
+		MaxDimension:    cfg.Storage.Transform.MaxDimension,
+		MaxSourcePixels: int64(cfg.Storage.Transform.MaxSourceMegapixels) * 1_000_000,
+		Sizes:           cfg.Storage.Transform.Sizes,
+		MaxVariants:     cfg.Storage.Transform.MaxVariants,
+	})
+	userService := user.NewUserService(userRepo, assetService)
+	authService := auth.NewService(userRepo, cfg.Security.JWTSecret, time.Duration(cfg.Security.TokenTTLHours)*time.Hour)
+	schemaService := schema.NewSchemaService(schemaRepo)
+	validationService := validation.NewService(dynamicRepo, locales)
+	tableService := table.NewTableService(tableRepo, schemaService, txManager)
+	recordService := dynamicapi.NewRecordService(schemaService, dynamicRepo, publishedRepo, publicationRepo, reviewRepo, revisionRepo, validationService, txManager, cfg.API.MaxPageSize, locales)
+
+	// Run a CLI subcommand instead of the server when one is given
+	if len(os.Args) > 1 && os.Args[1] == "schema" {
+		return cli.RunSchema(context.Background(), tableService, os.Args[2:], os.Stdout)
+	}
 
 	// Initialize HTTP handlers
 	userHandler := handler.NewUserHandler(userService)
+	authHandler := handler.NewAuthHandler(authService)
+	tableHandler := handler.NewTableHandler(tableService)
+	dynamicHandler := handler.NewDynamicHandler(recordService)
+	schemaHandler := handler.NewSchemaHandler(schemaService)
+	assetHandler := handler.NewAssetHandler(assetService)
 
 	// Initialize status handler
 
@@ -69,11 +115,25 @@ func run() error {
 
 	statusHandler := handler.NewStatusHandler()
 
+	// Fire scheduled publish/unpublish transitions in the background
+	scheduler := dynamicapi.NewScheduler(recordService, time.Duration(cfg.Workflow.ScheduleIntervalSeconds)*time.Second)
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	schedulerDone := make(chan struct{})
+	go func() {
+		defer close(schedulerDone)
+		scheduler.Run(schedulerCtx)
+	}()
+	defer func() {
+		stopScheduler()
+		<-schedulerDone
+	}()
+
 	// Initialize Echo instance
 	e := initializeEcho()
+	e.Use(appmiddleware.Authenticate(authService))
 
 	// Setup routes
-	httpserver.SetupRoutes(e, userHandler, statusHandler, healthHandler)
+	httpserver.SetupRoutes(e, userHandler, statusHandler, healthHandler, tableHandler, dynamicHandler, schemaHandler, authHandler, assetHandler)
 
 	// Start server
 	return startServer(e, cfg.Server.Port)
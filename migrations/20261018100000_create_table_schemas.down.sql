@@ -0,0 +1,2 @@
+-- Drop table_schemas catalog table
+DROP TABLE IF EXISTS table_schemas;
@@ -0,0 +1,11 @@
+-- Create table_schemas catalog table
+CREATE TABLE table_schemas (
+    id BIGSERIAL PRIMARY KEY,
+    table_name VARCHAR(63) NOT NULL UNIQUE,
+    display_name VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    definition JSONB NOT NULL,
+    owner_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
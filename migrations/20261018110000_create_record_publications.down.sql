@@ -0,0 +1,2 @@
+-- Drop record_publications
+DROP TABLE IF EXISTS record_publications;
@@ -0,0 +1,13 @@
+-- Create record_publications for the draft/publish workflow of user-defined tables
+CREATE TABLE record_publications (
+    table_name VARCHAR(63) NOT NULL,
+    record_key TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    data JSONB,
+    published_at TIMESTAMPTZ,
+    updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (table_name, record_key)
+);
+
+CREATE INDEX idx_record_publications_published ON record_publications(table_name) WHERE status = 'published';
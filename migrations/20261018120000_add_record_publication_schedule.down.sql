@@ -0,0 +1,7 @@
+-- Drop publish/unpublish schedules from record_publications
+DROP INDEX IF EXISTS idx_record_publications_unpublish_at;
+DROP INDEX IF EXISTS idx_record_publications_publish_at;
+ALTER TABLE record_publications
+    DROP COLUMN IF EXISTS scheduled_by,
+    DROP COLUMN IF EXISTS unpublish_at,
+    DROP COLUMN IF EXISTS publish_at;
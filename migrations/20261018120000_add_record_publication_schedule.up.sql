@@ -0,0 +1,8 @@
+-- Add publish/unpublish schedules to record_publications
+ALTER TABLE record_publications
+    ADD COLUMN publish_at TIMESTAMPTZ,
+    ADD COLUMN unpublish_at TIMESTAMPTZ,
+    ADD COLUMN scheduled_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_record_publications_publish_at ON record_publications(publish_at) WHERE publish_at IS NOT NULL;
+CREATE INDEX idx_record_publications_unpublish_at ON record_publications(unpublish_at) WHERE unpublish_at IS NOT NULL;
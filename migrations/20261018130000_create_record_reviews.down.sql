@@ -0,0 +1,4 @@
+-- Drop editorial stages, the transition log and review comments
+DROP TABLE IF EXISTS record_comments;
+DROP TABLE IF EXISTS record_transitions;
+ALTER TABLE record_publications DROP COLUMN IF EXISTS stage;
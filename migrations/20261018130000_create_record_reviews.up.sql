@@ -0,0 +1,30 @@
+-- Add editorial stages, the transition log and review comments of workflow records
+ALTER TABLE record_publications ADD COLUMN stage VARCHAR(63);
+
+CREATE TABLE record_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    table_name VARCHAR(63) NOT NULL,
+    record_key TEXT NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    from_stage VARCHAR(63),
+    to_stage VARCHAR(63),
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    comment TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_record_transitions_record ON record_transitions(table_name, record_key, id);
+
+CREATE TABLE record_comments (
+    id BIGSERIAL PRIMARY KEY,
+    table_name VARCHAR(63) NOT NULL,
+    record_key TEXT NOT NULL,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    changes_requested BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_record_comments_record ON record_comments(table_name, record_key, id);
@@ -0,0 +1,2 @@
+-- Drop record_revisions
+DROP TABLE IF EXISTS record_revisions;
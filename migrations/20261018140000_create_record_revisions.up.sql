@@ -0,0 +1,13 @@
+-- Create record_revisions for the revision history of user-defined tables
+CREATE TABLE record_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    table_name VARCHAR(63) NOT NULL,
+    record_key TEXT NOT NULL,
+    revision INTEGER NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    data JSONB NOT NULL,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    restored_from INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (table_name, record_key, revision)
+);
@@ -0,0 +1,13 @@
+-- Drop optimistic concurrency versions
+DO $$
+DECLARE
+    t RECORD;
+BEGIN
+    FOR t IN SELECT table_name FROM table_schemas LOOP
+        IF to_regclass(quote_ident(t.table_name)) IS NOT NULL THEN
+            EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS _version', t.table_name);
+        END IF;
+    END LOOP;
+END $$;
+
+ALTER TABLE users DROP COLUMN IF EXISTS version;
@@ -0,0 +1,13 @@
+-- Add optimistic concurrency versions to users and every catalogued user-defined table
+ALTER TABLE users ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+DO $$
+DECLARE
+    t RECORD;
+BEGIN
+    FOR t IN SELECT table_name FROM table_schemas LOOP
+        IF to_regclass(quote_ident(t.table_name)) IS NOT NULL THEN
+            EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS _version BIGINT NOT NULL DEFAULT 1', t.table_name);
+        END IF;
+    END LOOP;
+END $$;
@@ -0,0 +1,3 @@
+-- Drop assets
+ALTER TABLE users DROP COLUMN IF EXISTS profile_image_asset_id;
+DROP TABLE IF EXISTS assets;
@@ -0,0 +1,19 @@
+-- Create assets for uploaded media and let users reference one as their profile image.
+-- Assets cannot be deleted while a user or record references them.
+CREATE TABLE assets (
+    id UUID PRIMARY KEY,
+    filename VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    width INTEGER,
+    height INTEGER,
+    storage_key VARCHAR(512) NOT NULL UNIQUE,
+    uploaded_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_assets_checksum ON assets(checksum);
+CREATE INDEX idx_assets_created_at ON assets(created_at, id);
+
+ALTER TABLE users ADD COLUMN profile_image_asset_id UUID REFERENCES assets(id);
@@ -0,0 +1,2 @@
+-- Drop asset_derivatives
+DROP TABLE IF EXISTS asset_derivatives;
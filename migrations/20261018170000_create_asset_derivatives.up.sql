@@ -0,0 +1,13 @@
+-- Create asset_derivatives to cache transformed versions of image assets
+CREATE TABLE asset_derivatives (
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    variant VARCHAR(128) NOT NULL,
+    mime_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    storage_key VARCHAR(512) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (asset_id, variant)
+);
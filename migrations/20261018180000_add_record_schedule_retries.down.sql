@@ -0,0 +1,5 @@
+-- Drop schedule retry tracking from record_publications
+ALTER TABLE record_publications
+    DROP COLUMN IF EXISTS schedule_retry_at,
+    DROP COLUMN IF EXISTS schedule_error,
+    DROP COLUMN IF EXISTS schedule_attempts;
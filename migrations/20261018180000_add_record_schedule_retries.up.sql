@@ -0,0 +1,5 @@
+-- Track failed schedule runs so that failing schedules back off instead of blocking the queue
+ALTER TABLE record_publications
+    ADD COLUMN schedule_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN schedule_error TEXT,
+    ADD COLUMN schedule_retry_at TIMESTAMPTZ;
@@ -20,13 +20,31 @@ const (
 	ErrorTypeNotFound ErrorType = "NOT_FOUND"
 	// ErrorTypeUnauthorized represents unauthorized access errors
 	ErrorTypeUnauthorized ErrorType = "UNAUTHORIZED"
+	// ErrorTypeForbidden represents authenticated users lacking a permission
+	ErrorTypeForbidden ErrorType = "FORBIDDEN"
+	// ErrorTypePreconditionFailed represents a write whose If-Match version is stale
+	ErrorTypePreconditionFailed ErrorType = "PRECONDITION_FAILED"
+	// ErrorTypeCanceled represents a request the client gave up on before it completed
+	ErrorTypeCanceled ErrorType = "CANCELED"
 )
 
+// StatusClientClosedRequest is the non-standard status logged for requests the client closed
+const StatusClientClosedRequest = 499
+
 // AppError is a custom error type for the application
 type AppError struct {
 	Type    ErrorType
 	Message string
 	Err     error
+	// Violations lists every invalid field of a validation error
+	Violations []Violation
+}
+
+// Violation describes one failed rule of an input field
+type Violation struct {
+	Field   string `json:"field,omitempty"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 // Error returns the error message
@@ -51,6 +69,15 @@ func NewAppError(errType ErrorType, message string, err error) *AppError {
 	}
 }
 
+// NewValidationError creates a validation AppError reporting every violation at once
+func NewValidationError(message string, violations []Violation) *AppError {
+	return &AppError{
+		Type:       ErrorTypeValidation,
+		Message:    message,
+		Violations: violations,
+	}
+}
+
 // HTTPStatusCode maps AppError types to HTTP status codes
 func (e *AppError) HTTPStatusCode() int {
 	switch e.Type {
@@ -62,6 +89,12 @@ func (e *AppError) HTTPStatusCode() int {
 		return http.StatusNotFound
 	case ErrorTypeUnauthorized:
 		return http.StatusUnauthorized
+	case ErrorTypeForbidden:
+		return http.StatusForbidden
+	case ErrorTypePreconditionFailed:
+		return http.StatusPreconditionFailed
+	case ErrorTypeCanceled:
+		return StatusClientClosedRequest
 	default:
 		return http.StatusInternalServerError
 	}
@@ -13,6 +13,12 @@ func HandleHTTPError(c echo.Context, err error) error {
 	if IsAppError(err) {
 		// If it's an AppError, use a custom message and HTTP status code
 		if As(err, &appErr) {
+			if len(appErr.Violations) > 0 {
+				return c.JSON(appErr.HTTPStatusCode(), map[string]interface{}{
+					"error":      appErr.Message,
+					"violations": appErr.Violations,
+				})
+			}
 			return c.JSON(appErr.HTTPStatusCode(), map[string]string{
 				"error":   appErr.Message,
 				"details": err.Error(),
@@ -0,0 +1,507 @@
+// File: pkg/expr/eval.go
+
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Values are nil, bool, float64, string, time.Time, []interface{} and map[string]interface{}.
+// Integers and json.Number variables are converted to float64.
+//
+// null follows SQL semantics: comparisons and arithmetic with null give null,
+// && and || use three valued logic and a rule that evaluates to null passes.
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+var patterns sync.Map
+
+type node interface {
+	eval(vars map[string]interface{}) (interface{}, error)
+}
+
+// Eval evaluates the expression with the given variables.
+// Variables the expression refers to but vars does not hold are null.
+func (p *Program) Eval(vars map[string]interface{}) (interface{}, error) {
+	normalized := make(map[string]interface{}, len(vars))
+	for name, value := range vars {
+		normalized[name] = normalize(value)
+	}
+	return p.root.eval(normalized)
+}
+
+// Check evaluates the expression as a rule. It passes unless the result is false;
+// a null result passes like a SQL CHECK constraint.
+func (p *Program) Check(vars map[string]interface{}) (bool, error) {
+	result, err := p.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	switch v := result.(type) {
+	case nil:
+		return true, nil
+	case bool:
+		return v, nil
+	}
+	return false, fmt.Errorf("rule must evaluate to a boolean, got %s", typeName(result))
+}
+
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return f
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = normalize(item)
+		}
+		return items
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for name, item := range v {
+			fields[name] = normalize(item)
+		}
+		return fields
+	}
+	return value
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+type identNode struct {
+	name string
+}
+
+func (n *identNode) eval(vars map[string]interface{}) (interface{}, error) {
+	return vars[n.name], nil
+}
+
+type memberNode struct {
+	target node
+	name   string
+}
+
+func (n *memberNode) eval(vars map[string]interface{}) (interface{}, error) {
+	target, err := n.target.eval(vars)
+	if err != nil || target == nil {
+		return nil, err
+	}
+	fields, ok := target.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot read field %s of %s", n.name, typeName(target))
+	}
+	return fields[n.name], nil
+}
+
+type listNode struct {
+	items []node
+}
+
+func (n *listNode) eval(vars map[string]interface{}) (interface{}, error) {
+	items := make([]interface{}, len(n.items))
+	for i, item := range n.items {
+		value, err := item.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = value
+	}
+	return items, nil
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(vars map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(vars)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	switch n.op {
+	case "!":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("! needs a boolean, got %s", typeName(value))
+		}
+		return !b, nil
+	default:
+		f, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("- needs a number, got %s", typeName(value))
+		}
+		return -f, nil
+	}
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n *andNode) eval(vars map[string]interface{}) (interface{}, error) {
+	left, err := evalBool(n.left, vars, "&&")
+	if err != nil {
+		return nil, err
+	}
+	if left != nil && !*left {
+		return false, nil
+	}
+	right, err := evalBool(n.right, vars, "&&")
+	if err != nil {
+		return nil, err
+	}
+	if right != nil && !*right {
+		return false, nil
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	return true, nil
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n *orNode) eval(vars map[string]interface{}) (interface{}, error) {
+	left, err := evalBool(n.left, vars, "||")
+	if err != nil {
+		return nil, err
+	}
+	if left != nil && *left {
+		return true, nil
+	}
+	right, err := evalBool(n.right, vars, "||")
+	if err != nil {
+		return nil, err
+	}
+	if right != nil && *right {
+		return true, nil
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	return false, nil
+}
+
+// impliesNode は !left || right と同じ
+type impliesNode struct {
+	left, right node
+}
+
+func (n *impliesNode) eval(vars map[string]interface{}) (interface{}, error) {
+	return (&orNode{left: &unaryNode{op: "!", operand: n.left}, right: n.right}).eval(vars)
+}
+
+func evalBool(n node, vars map[string]interface{}, op string) (*bool, error) {
+	value, err := n.eval(vars)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%s needs booleans, got %s", op, typeName(value))
+	}
+	return &b, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(vars map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	}
+
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	switch n.op {
+	case "in":
+		items, ok := right.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("in needs a list, got %s", typeName(right))
+		}
+		for _, item := range items {
+			if equal(left, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case "<", "<=", ">", ">=":
+		c, err := compare(left, right)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	}
+
+	if n.op == "+" {
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
+		}
+	}
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s needs numbers, got %s and %s", n.op, typeName(left), typeName(right))
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	default:
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(l, r), nil
+	}
+}
+
+// coerceTimes lets a timestamp be compared with an ISO 8601 string literal
+func coerceTimes(left, right interface{}) (interface{}, interface{}) {
+	if _, ok := left.(time.Time); ok {
+		if s, ok := right.(string); ok {
+			if t, err := parseTime(s); err == nil {
+				return left, t
+			}
+		}
+	}
+	if _, ok := right.(time.Time); ok {
+		if s, ok := left.(string); ok {
+			if t, err := parseTime(s); err == nil {
+				return t, right
+			}
+		}
+	}
+	return left, right
+}
+
+func equal(left, right interface{}) bool {
+	left, right = coerceTimes(left, right)
+	if l, ok := left.(time.Time); ok {
+		r, ok := right.(time.Time)
+		return ok && l.Equal(r)
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+func compare(left, right interface{}) (int, error) {
+	left, right = coerceTimes(left, right)
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			switch {
+			case l < r:
+				return -1, nil
+			case l > r:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r), nil
+		}
+	case time.Time:
+		if r, ok := right.(time.Time); ok {
+			return l.Compare(r), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s with %s", typeName(left), typeName(right))
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case time.Time:
+		return "timestamp"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+type function struct {
+	minArgs, maxArgs int
+	call             func(args []interface{}) (interface{}, error)
+}
+
+// functions are the built-in functions. They return null when a required argument is null.
+var functions = map[string]function{
+	"size": {1, 1, func(args []interface{}) (interface{}, error) {
+		switch v := args[0].(type) {
+		case nil:
+			return nil, nil
+		case string:
+			return float64(len([]rune(v))), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("size needs a string, list or map, got %s", typeName(args[0]))
+	}},
+	"matches": {2, 2, stringFunc("matches", func(s []string) (interface{}, error) {
+		re, err := compilePattern(s[1])
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(s[0]), nil
+	})},
+	"startsWith": {2, 2, stringFunc("startsWith", func(s []string) (interface{}, error) {
+		return strings.HasPrefix(s[0], s[1]), nil
+	})},
+	"endsWith": {2, 2, stringFunc("endsWith", func(s []string) (interface{}, error) {
+		return strings.HasSuffix(s[0], s[1]), nil
+	})},
+	"contains": {2, 2, stringFunc("contains", func(s []string) (interface{}, error) {
+		return strings.Contains(s[0], s[1]), nil
+	})},
+	"lower": {1, 1, stringFunc("lower", func(s []string) (interface{}, error) {
+		return strings.ToLower(s[0]), nil
+	})},
+	"upper": {1, 1, stringFunc("upper", func(s []string) (interface{}, error) {
+		return strings.ToUpper(s[0]), nil
+	})},
+	"timestamp": {1, 1, stringFunc("timestamp", func(s []string) (interface{}, error) {
+		return parseTime(s[0])
+	})},
+	"now": {0, 0, func([]interface{}) (interface{}, error) {
+		return time.Now(), nil
+	}},
+	"today": {0, 0, func([]interface{}) (interface{}, error) {
+		return time.Now().UTC().Truncate(24 * time.Hour), nil
+	}},
+}
+
+func stringFunc(name string, fn func(args []string) (interface{}, error)) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		strs := make([]string, len(args))
+		for i, arg := range args {
+			if arg == nil {
+				return nil, nil
+			}
+			s, ok := arg.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s needs strings, got %s", name, typeName(arg))
+			}
+			strs[i] = s
+		}
+		return fn(strs)
+	}
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+type callNode struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n *callNode) eval(vars map[string]interface{}) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	return n.fn.call(args)
+}
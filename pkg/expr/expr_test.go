@@ -0,0 +1,227 @@
+// File: pkg/expr/expr_test.go
+
+package expr
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEvalPrecedence(t *testing.T) {
+	tests := []struct {
+		src  string
+		want interface{}
+	}{
+		{"1 + 2 * 3", 7.0},
+		{"(1 + 2) * 3", 9.0},
+		{"10 - 4 - 3", 3.0},
+		{"12 / 2 / 3", 2.0},
+		{"7 % 4 + 1", 4.0},
+		{"-2 * 3", -6.0},
+		{"--2", 2.0},
+		{"'a' + 'b' == 'ab'", true},
+		{"1 + 2 == 3 && 2 < 1 || true", true},
+		{"true || false && false", true},
+		{"(true || false) && false", false},
+		{"1 < 2 == true", true},
+		{"!true == false", true},
+		{"!(1 < 2)", false},
+		{"2 in [1, 2] && 3 in [1, 2]", false},
+		{"1 + 1 in [2]", true},
+		// implies は最も弱く、右結合
+		{"true implies false || true", true},
+		{"false implies false implies false", true},
+		{"(false implies false) implies false", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			p, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			got, err := p.Eval(nil)
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalNull(t *testing.T) {
+	vars := map[string]interface{}{"x": nil, "meta": nil, "n": 1}
+	tests := []struct {
+		src  string
+		want interface{}
+	}{
+		{"x > 1", nil},
+		{"x + 1", nil},
+		{"-x", nil},
+		{"!x", nil},
+		{"x in [1]", nil},
+		{"x == null", true},
+		{"x != null", false},
+		{"n == null", false},
+		{"missing == null", true},
+		{"meta.author", nil},
+		{"size(x)", nil},
+		{"startsWith(x, 'a')", nil},
+		// && と || は三値論理
+		{"x > 1 && false", false},
+		{"x > 1 && true", nil},
+		{"x > 1 || true", true},
+		{"x > 1 || false", nil},
+		{"x > 1 implies false", nil},
+		{"false implies x > 1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			p, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			got, err := p.Eval(vars)
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalVariables(t *testing.T) {
+	publishedAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	vars := map[string]interface{}{
+		"count":        int64(3),
+		"price":        json.Number("2.5"),
+		"tags":         []interface{}{"news", "tech"},
+		"meta":         map[string]interface{}{"author": "hanako", "score": 4},
+		"published_at": publishedAt,
+	}
+	tests := []struct {
+		src  string
+		want interface{}
+	}{
+		{"count * price", 7.5},
+		{"'tech' in tags", true},
+		{"meta.author == 'hanako' && meta.score > 3", true},
+		{"size(tags) == 2", true},
+		{"published_at > '2026-10-01'", true},
+		{"published_at == '2026-10-18T09:00:00Z'", true},
+		{"published_at < timestamp('2026-10-18')", false},
+		{"matches(meta.author, '^h[a-z]+$')", true},
+		{"upper(meta.author)", "HANAKO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			p, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			got, err := p.Eval(vars)
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []string{
+		"1 / 0",
+		"5 % 0",
+		"'a' < 1",
+		"1 && true",
+		"!'a'",
+		"-'a'",
+		"1 in 'abc'",
+		"'a' - 'b'",
+		"n.field",
+		"matches('a', '(')",
+	}
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			p, err := Compile(src)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			if got, err := p.Eval(map[string]interface{}{"n": 1}); err == nil {
+				t.Errorf("got %#v, want an error", got)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"1 +",
+		"(1",
+		"[1, 2",
+		"'abc",
+		"1 2",
+		"a.",
+		"unknown(1)",
+		"size(1, 2)",
+		"now(1)",
+		"in [1]",
+		"a # b",
+	}
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			if _, err := Compile(src); err == nil {
+				t.Errorf("Compile(%q) succeeded, want an error", src)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		src     string
+		vars    map[string]interface{}
+		want    bool
+		wantErr bool
+	}{
+		{src: "x > 1", vars: map[string]interface{}{"x": 2}, want: true},
+		{src: "x > 1", vars: map[string]interface{}{"x": 0}, want: false},
+		// null の結果は SQL の CHECK 制約と同じく通す
+		{src: "x > 1", vars: nil, want: true},
+		{src: "x + 1", vars: map[string]interface{}{"x": 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			p, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			got, err := p.Check(tt.vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	p, err := Compile("meta.author != null && size(tags) > min || status in ['a', other]")
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	want := []string{"meta", "min", "other", "status", "tags"}
+	if got := p.Identifiers(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
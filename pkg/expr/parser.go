@@ -0,0 +1,289 @@
+// File: pkg/expr/parser.go
+
+package expr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Program is a compiled expression that can be evaluated many times
+type Program struct {
+	source string
+	root   node
+	idents map[string]bool
+}
+
+// Compile parses an expression such as `status == 'published' implies published_at != null`.
+//
+// The language is a small CEL-like subset:
+//   - literals: numbers, 'strings' or "strings", true, false, null and [lists]
+//   - variables, with dotted access into maps (meta.author)
+//   - operators by increasing precedence: implies, ||, &&, == !=, < <= > >= in, + -, * / %, ! and unary -
+//   - the functions listed in functions
+func Compile(src string) (*Program, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, idents: make(map[string]bool)}
+	root, err := p.parseImplies()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+	}
+	return &Program{source: src, root: root, idents: p.idents}, nil
+}
+
+// Source returns the expression text the program was compiled from
+func (p *Program) Source() string {
+	return p.source
+}
+
+// Identifiers lists the top level variable names the expression refers to
+func (p *Program) Identifiers() []string {
+	names := make([]string, 0, len(p.idents))
+	for name := range p.idents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	idents map[string]bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token when it is one of the given operators or keywords
+func (p *parser) accept(texts ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokenOperator && tok.kind != tokenIdent {
+		return "", false
+	}
+	for _, text := range texts {
+		if tok.text == text {
+			p.next()
+			return text, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(text string) error {
+	if _, ok := p.accept(text); !ok {
+		tok := p.peek()
+		return fmt.Errorf("expected %q at %d", text, tok.pos)
+	}
+	return nil
+}
+
+// implies は右結合: a implies b implies c は a implies (b implies c)
+func (p *parser) parseImplies() (node, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("implies"); ok {
+		right, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		return &impliesNode{left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseEquality()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseEquality()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseEquality() (node, error) {
+	return p.parseBinary(p.parseRelation, "==", "!=")
+}
+
+func (p *parser) parseRelation() (node, error) {
+	return p.parseBinary(p.parseAdditive, "<", "<=", ">", ">=", "in")
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *parser) parseBinary(operand func() (node, error), ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("."); !ok {
+			return n, nil
+		}
+		tok := p.next()
+		if tok.kind != tokenIdent {
+			return nil, fmt.Errorf("expected a field name at %d", tok.pos)
+		}
+		n = &memberNode{target: n, name: tok.text}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber, tokenString:
+		return &literalNode{value: tok.value}, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		case "in", "implies":
+			return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+		}
+
+		if _, ok := p.accept("("); ok {
+			fn, ok := functions[tok.text]
+			if !ok {
+				return nil, fmt.Errorf("unknown function %s at %d", tok.text, tok.pos)
+			}
+			args, err := p.parseList(")")
+			if err != nil {
+				return nil, err
+			}
+			if len(args) < fn.minArgs || len(args) > fn.maxArgs {
+				return nil, fmt.Errorf("wrong number of arguments to %s at %d", tok.text, tok.pos)
+			}
+			return &callNode{name: tok.text, fn: fn, args: args}, nil
+		}
+		p.idents[tok.text] = true
+		return &identNode{name: tok.text}, nil
+
+	case tokenOperator:
+		switch tok.text {
+		case "(":
+			n, err := p.parseImplies()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{items: items}, nil
+		}
+	}
+
+	if tok.kind == tokenEOF {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+}
+
+// parseList reads comma separated expressions up to the closing token
+func (p *parser) parseList(closing string) ([]node, error) {
+	var items []node
+	if _, ok := p.accept(closing); ok {
+		return items, nil
+	}
+	for {
+		item, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if _, ok := p.accept(closing); ok {
+			return items, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
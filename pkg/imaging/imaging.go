@@ -0,0 +1,181 @@
+// File: pkg/imaging/imaging.go
+
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/draw"
+
+	// WebP は読み込みのみ x/image に任せ、書き出しは webp.go で行う
+	_ "golang.org/x/image/webp"
+)
+
+// Format is an image format the pipeline can write
+type Format string
+
+const (
+	JPEG Format = "jpeg"
+	PNG  Format = "png"
+	GIF  Format = "gif"
+	WebP Format = "webp"
+)
+
+// ParseFormat accepts a format name as used in query strings; jpg is an alias of jpeg
+func ParseFormat(name string) (Format, bool) {
+	switch name {
+	case "jpeg", "jpg":
+		return JPEG, true
+	case "png":
+		return PNG, true
+	case "gif":
+		return GIF, true
+	case "webp":
+		return WebP, true
+	}
+	return "", false
+}
+
+// FormatOf returns the format of a MIME type, or false when the pipeline cannot read it
+func FormatOf(mimeType string) (Format, bool) {
+	switch mimeType {
+	case "image/jpeg":
+		return JPEG, true
+	case "image/png":
+		return PNG, true
+	case "image/gif":
+		return GIF, true
+	case "image/webp":
+		return WebP, true
+	}
+	return "", false
+}
+
+func (f Format) MimeType() string {
+	return "image/" + string(f)
+}
+
+func (f Format) Extension() string {
+	if f == JPEG {
+		return ".jpg"
+	}
+	return "." + string(f)
+}
+
+// Fit decides how an image is laid into a box of both a width and a height
+type Fit string
+
+const (
+	// FitContain scales the image to fit inside the box, keeping its aspect ratio
+	FitContain Fit = "contain"
+	// FitCover scales the image to fill the box, cropping what overflows around the center
+	FitCover Fit = "cover"
+	// FitFill stretches the image to the box
+	FitFill Fit = "fill"
+)
+
+// ParseFit accepts a fit name; the empty name is FitContain
+func ParseFit(name string) (Fit, bool) {
+	switch Fit(name) {
+	case "", FitContain:
+		return FitContain, true
+	case FitCover, FitFill:
+		return Fit(name), true
+	}
+	return "", false
+}
+
+// Options describe a transformation. Crop, when not empty, selects a region of the source
+// before it is scaled. A zero Width or Height follows the aspect ratio of the other;
+// both zero keep the size.
+type Options struct {
+	Crop   image.Rectangle
+	Width  int
+	Height int
+	Fit    Fit
+}
+
+// Dimensions returns the size of the image Transform makes from a source of the given size,
+// along with the region of the (cropped) source that is scaled into it
+func Dimensions(srcWidth, srcHeight int, opts Options) (int, int, image.Rectangle) {
+	region := image.Rect(0, 0, srcWidth, srcHeight)
+	if !opts.Crop.Empty() {
+		region = opts.Crop.Intersect(region)
+	}
+	w, h := region.Dx(), region.Dy()
+	if w == 0 || h == 0 {
+		return 0, 0, region
+	}
+
+	switch {
+	case opts.Width == 0 && opts.Height == 0:
+		return w, h, region
+	case opts.Width == 0:
+		return scaled(w, opts.Height, h), opts.Height, region
+	case opts.Height == 0:
+		return opts.Width, scaled(h, opts.Width, w), region
+	}
+
+	switch opts.Fit {
+	case FitFill:
+	case FitCover:
+		// 箱と同じ縦横比になるよう中央を切り出す
+		if w*opts.Height > opts.Width*h {
+			cw := scaled(h, opts.Width, opts.Height)
+			region.Min.X += (w - cw) / 2
+			region.Max.X = region.Min.X + cw
+		} else {
+			ch := scaled(w, opts.Height, opts.Width)
+			region.Min.Y += (h - ch) / 2
+			region.Max.Y = region.Min.Y + ch
+		}
+	default:
+		if w*opts.Height > opts.Width*h {
+			return opts.Width, scaled(h, opts.Width, w), region
+		}
+		return scaled(w, opts.Height, h), opts.Height, region
+	}
+	return opts.Width, opts.Height, region
+}
+
+// Transform crops and scales an image with a Catmull-Rom filter
+func Transform(src image.Image, opts Options) image.Image {
+	bounds := src.Bounds()
+	w, h, region := Dimensions(bounds.Dx(), bounds.Dy(), opts)
+	region = region.Add(bounds.Min)
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w == region.Dx() && h == region.Dy() {
+		draw.Draw(dst, dst.Bounds(), src, region.Min, draw.Src)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, region, draw.Src, nil)
+	}
+	return dst
+}
+
+// Encode writes an image in the given format. Quality applies to JPEG only;
+// WebP is always written losslessly.
+func Encode(w io.Writer, img image.Image, format Format, quality int) error {
+	switch format {
+	case JPEG:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case PNG:
+		return png.Encode(w, img)
+	case GIF:
+		return gif.Encode(w, img, &gif.Options{NumColors: 256, Drawer: draw.FloydSteinberg})
+	case WebP:
+		return EncodeWebP(w, img)
+	}
+	return fmt.Errorf("unsupported image format: %s", format)
+}
+
+// scaled returns length * numerator / denominator rounded, and at least 1
+func scaled(length, numerator, denominator int) int {
+	return max(1, int(math.Round(float64(length)*float64(numerator)/float64(denominator))))
+}
@@ -0,0 +1,230 @@
+// File: pkg/imaging/imaging_test.go
+
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// testImage fills an NRGBA image with a pixel function
+func testImage(width, height int, pixel func(x, y int) color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, pixel(x, y))
+		}
+	}
+	return img
+}
+
+func TestEncodeWebPRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"single pixel", testImage(1, 1, func(int, int) color.NRGBA {
+			return color.NRGBA{10, 20, 30, 255}
+		})},
+		{"single color", testImage(17, 9, func(int, int) color.NRGBA {
+			return color.NRGBA{200, 100, 50, 255}
+		})},
+		{"two colors", testImage(16, 16, func(x, y int) color.NRGBA {
+			if (x+y)%2 == 0 {
+				return color.NRGBA{0, 0, 0, 255}
+			}
+			return color.NRGBA{255, 255, 255, 255}
+		})},
+		{"gradient", testImage(64, 48, func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(x * 4), uint8(y * 5), uint8(x ^ y), 255}
+		})},
+		{"translucent", testImage(33, 21, func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(x * 7), uint8(y * 11), 128, uint8(x*y) | 1}
+		})},
+		{"every value", testImage(256, 4, func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(x), uint8(255 - x), uint8(x * (y + 1)), uint8(x*13 + y)}
+		})},
+		{"skewed histogram", testImage(300, 200, func(x, y int) color.NRGBA {
+			// ほとんどが同じ値で、まれな値が多数ある分布は符号長の上限に掛かる
+			v := uint8(0)
+			if x%50 == 0 {
+				v = uint8(y)
+			}
+			return color.NRGBA{v, v, v, 255}
+		})},
+		{"offset bounds", testImage(40, 30, func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(x * 6), uint8(y * 8), 0, 255}
+		}).SubImage(image.Rect(5, 7, 29, 25))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EncodeWebP(&buf, tt.img); err != nil {
+				t.Fatalf("EncodeWebP: %v", err)
+			}
+			decoded, err := webp.Decode(&buf)
+			if err != nil {
+				t.Fatalf("webp.Decode: %v", err)
+			}
+
+			bounds := tt.img.Bounds()
+			if got := decoded.Bounds(); got.Dx() != bounds.Dx() || got.Dy() != bounds.Dy() {
+				t.Fatalf("decoded size %dx%d, want %dx%d", got.Dx(), got.Dy(), bounds.Dx(), bounds.Dy())
+			}
+			// 可逆圧縮なので全画素が一致する
+			for y := 0; y < bounds.Dy(); y++ {
+				for x := 0; x < bounds.Dx(); x++ {
+					want := color.NRGBAModel.Convert(tt.img.At(bounds.Min.X+x, bounds.Min.Y+y))
+					got := color.NRGBAModel.Convert(decoded.At(decoded.Bounds().Min.X+x, decoded.Bounds().Min.Y+y))
+					if got != want {
+						t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeWebPInvalidSize(t *testing.T) {
+	for _, rect := range []image.Rectangle{
+		image.Rect(0, 0, 0, 10),
+		image.Rect(0, 0, maxWebPDimension+1, 1),
+	} {
+		if err := EncodeWebP(&bytes.Buffer{}, image.NewNRGBA(rect)); err == nil {
+			t.Errorf("EncodeWebP(%v) succeeded, want an error", rect)
+		}
+	}
+}
+
+func TestCodeLengthsLimit(t *testing.T) {
+	// フィボナッチ数列の頻度は制限なしだと最も深い木になる
+	counts := make([]int, 30)
+	a, b := 1, 1
+	for i := range counts {
+		counts[i] = a
+		a, b = b, a+b
+	}
+	lengths := codeLengths(counts, maxCodeLength)
+
+	kraft := 0.0
+	for symbol, n := range lengths {
+		if n == 0 || int(n) > maxCodeLength {
+			t.Fatalf("symbol %d has code length %d", symbol, n)
+		}
+		kraft += 1 / float64(uint(1)<<n)
+	}
+	if kraft != 1 {
+		t.Errorf("Kraft sum = %v, want a complete code", kraft)
+	}
+}
+
+func TestDimensions(t *testing.T) {
+	tests := []struct {
+		name                string
+		srcWidth, srcHeight int
+		opts                Options
+		wantWidth           int
+		wantHeight          int
+		wantRegion          image.Rectangle
+	}{
+		{"keep size", 400, 300, Options{}, 400, 300, image.Rect(0, 0, 400, 300)},
+		{"width only", 400, 300, Options{Width: 200}, 200, 150, image.Rect(0, 0, 400, 300)},
+		{"height only", 400, 300, Options{Height: 100}, 133, 100, image.Rect(0, 0, 400, 300)},
+		{"contain wide", 400, 300, Options{Width: 100, Height: 100, Fit: FitContain}, 100, 75, image.Rect(0, 0, 400, 300)},
+		{"contain tall", 300, 400, Options{Width: 100, Height: 100, Fit: FitContain}, 75, 100, image.Rect(0, 0, 300, 400)},
+		{"cover wide", 400, 300, Options{Width: 100, Height: 100, Fit: FitCover}, 100, 100, image.Rect(50, 0, 350, 300)},
+		{"cover tall", 300, 400, Options{Width: 100, Height: 100, Fit: FitCover}, 100, 100, image.Rect(0, 50, 300, 350)},
+		{"fill", 400, 300, Options{Width: 100, Height: 100, Fit: FitFill}, 100, 100, image.Rect(0, 0, 400, 300)},
+		{"crop", 400, 300, Options{Crop: image.Rect(10, 20, 110, 70)}, 100, 50, image.Rect(10, 20, 110, 70)},
+		{"crop then scale", 400, 300, Options{Crop: image.Rect(0, 0, 200, 100), Width: 50}, 50, 25, image.Rect(0, 0, 200, 100)},
+		{"tiny side stays visible", 1000, 1, Options{Width: 10}, 10, 1, image.Rect(0, 0, 1000, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h, region := Dimensions(tt.srcWidth, tt.srcHeight, tt.opts)
+			if w != tt.wantWidth || h != tt.wantHeight || region != tt.wantRegion {
+				t.Errorf("Dimensions = %dx%d %v, want %dx%d %v", w, h, region, tt.wantWidth, tt.wantHeight, tt.wantRegion)
+			}
+		})
+	}
+}
+
+func TestTransformAndEncode(t *testing.T) {
+	src := testImage(80, 60, func(x, y int) color.NRGBA {
+		return color.NRGBA{uint8(x * 3), uint8(y * 4), 90, 255}
+	})
+	for _, format := range []Format{JPEG, PNG, GIF, WebP} {
+		t.Run(string(format), func(t *testing.T) {
+			out := Transform(src, Options{Width: 40, Height: 40, Fit: FitCover})
+			var buf bytes.Buffer
+			if err := Encode(&buf, out, format, 80); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			cfg, name, err := image.DecodeConfig(&buf)
+			if err != nil {
+				t.Fatalf("DecodeConfig: %v", err)
+			}
+			if name != string(format) || cfg.Width != 40 || cfg.Height != 40 {
+				t.Errorf("decoded %s %dx%d, want %s 40x40", name, cfg.Width, cfg.Height, format)
+			}
+		})
+	}
+}
+
+func TestTransformCopiesUnscaledRegion(t *testing.T) {
+	src := testImage(20, 20, func(x, y int) color.NRGBA {
+		return color.NRGBA{uint8(x), uint8(y), 0, 255}
+	})
+	out := Transform(src, Options{Crop: image.Rect(5, 6, 15, 16)})
+	want := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(want, want.Rect, src, image.Pt(5, 6), draw.Src)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			if got := color.NRGBAModel.Convert(out.At(x, y)); got != want.At(x, y) {
+				t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, got, want.At(x, y))
+			}
+		}
+	}
+}
+
+func TestParseFormatAndFit(t *testing.T) {
+	formats := []struct {
+		name string
+		want Format
+		ok   bool
+	}{
+		{"jpeg", JPEG, true},
+		{"jpg", JPEG, true},
+		{"png", PNG, true},
+		{"gif", GIF, true},
+		{"webp", WebP, true},
+		{"bmp", "", false},
+	}
+	for _, tt := range formats {
+		if got, ok := ParseFormat(tt.name); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	fits := []struct {
+		name string
+		want Fit
+		ok   bool
+	}{
+		{"", FitContain, true},
+		{"contain", FitContain, true},
+		{"cover", FitCover, true},
+		{"fill", FitFill, true},
+		{"stretch", "", false},
+	}
+	for _, tt := range fits {
+		if got, ok := ParseFit(tt.name); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseFit(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+// File: pkg/imaging/webp.go
+
+package imaging
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+)
+
+const (
+	// maxWebPDimension is the largest width or height a WebP image can have
+	maxWebPDimension = 1 << 14
+	// vp8lSignature starts every lossless bitstream
+	vp8lSignature = 0x2f
+	// maxCodeLength and maxCodeLengthCodeLength bound the prefix codes of the bitstream
+	maxCodeLength           = 15
+	maxCodeLengthCodeLength = 7
+	// greenAlphabet holds the 256 green literals and the 24 length prefixes, which are never used here
+	greenAlphabet    = 256 + 24
+	distanceAlphabet = 40
+)
+
+// codeLengthCodeOrder is the order code length code lengths are written in
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// EncodeWebP writes an image as a lossless WebP (VP8L). It entropy codes the pixels without
+// transforms, backward references or a color cache; files are larger than those of libwebp
+// but decode anywhere WebP does.
+func EncodeWebP(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > maxWebPDimension || height > maxWebPDimension {
+		return fmt.Errorf("webp: invalid image size %dx%d", width, height)
+	}
+
+	pixels, ok := img.(*image.NRGBA)
+	if !ok || pixels.Rect.Min != (image.Point{}) || pixels.Stride != 4*width {
+		pixels = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(pixels, pixels.Rect, img, bounds.Min, draw.Src)
+	}
+
+	green := make([]int, greenAlphabet)
+	red := make([]int, 256)
+	blue := make([]int, 256)
+	alpha := make([]int, 256)
+	for i := 0; i < len(pixels.Pix); i += 4 {
+		red[pixels.Pix[i]]++
+		green[pixels.Pix[i+1]]++
+		blue[pixels.Pix[i+2]]++
+		alpha[pixels.Pix[i+3]]++
+	}
+
+	bw := &bitWriter{}
+	bw.writeBits(vp8lSignature, 8)
+	bw.writeBits(uint32(width-1), 14)
+	bw.writeBits(uint32(height-1), 14)
+	var alphaUsed uint32
+	if alpha[0xff] != width*height {
+		alphaUsed = 1
+	}
+	bw.writeBits(alphaUsed, 1)
+	bw.writeBits(0, 3) // version
+	bw.writeBits(0, 1) // no transforms
+	bw.writeBits(0, 1) // no color cache
+	bw.writeBits(0, 1) // a single prefix code group
+
+	greenCode := bw.writePrefixCode(green)
+	redCode := bw.writePrefixCode(red)
+	blueCode := bw.writePrefixCode(blue)
+	alphaCode := bw.writePrefixCode(alpha)
+	bw.writePrefixCode(make([]int, distanceAlphabet))
+
+	for i := 0; i < len(pixels.Pix); i += 4 {
+		greenCode.write(bw, int(pixels.Pix[i+1]))
+		redCode.write(bw, int(pixels.Pix[i]))
+		blueCode.write(bw, int(pixels.Pix[i+2]))
+		alphaCode.write(bw, int(pixels.Pix[i+3]))
+	}
+	data := bw.bytes()
+
+	// RIFF コンテナ: チャンクは偶数長に揃える
+	padding := len(data) & 1
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+len(data)+padding))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if padding == 1 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+// bitWriter packs values least significant bit first, as VP8L reads them
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nBits uint
+}
+
+func (b *bitWriter) writeBits(value uint32, n uint) {
+	b.acc |= uint64(value) << b.nBits
+	b.nBits += n
+	for b.nBits >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nBits -= 8
+	}
+}
+
+func (b *bitWriter) bytes() []byte {
+	if b.nBits > 0 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc, b.nBits = 0, 0
+	}
+	return b.buf
+}
+
+// prefixCode maps symbols to their bit reversed canonical codes
+type prefixCode struct {
+	codes   []uint32
+	lengths []uint8
+}
+
+func (p prefixCode) write(b *bitWriter, symbol int) {
+	if n := p.lengths[symbol]; n > 0 {
+		b.writeBits(p.codes[symbol], uint(n))
+	}
+}
+
+// writePrefixCode writes the code for a histogram and returns it. One or two literals use the
+// simple code; anything else is written as code lengths, themselves prefix coded.
+func (b *bitWriter) writePrefixCode(histogram []int) prefixCode {
+	var used []int
+	for symbol, count := range histogram {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+	if len(used) == 0 {
+		used = []int{0}
+	}
+
+	if len(used) <= 2 && used[len(used)-1] < 256 {
+		code := prefixCode{codes: make([]uint32, len(histogram)), lengths: make([]uint8, len(histogram))}
+		b.writeBits(1, 1)
+		b.writeBits(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			b.writeBits(0, 1)
+			b.writeBits(uint32(used[0]), 1)
+		} else {
+			b.writeBits(1, 1)
+			b.writeBits(uint32(used[0]), 8)
+		}
+		if len(used) == 2 {
+			b.writeBits(uint32(used[1]), 8)
+			code.lengths[used[0]], code.lengths[used[1]] = 1, 1
+			code.codes[used[1]] = 1
+		}
+		return code
+	}
+
+	lengths := codeLengths(histogram, maxCodeLength)
+	tokens := codeLengthTokens(lengths)
+	clHistogram := make([]int, len(codeLengthCodeOrder))
+	for _, t := range tokens {
+		clHistogram[t.symbol]++
+	}
+	clLengths := codeLengths(clHistogram, maxCodeLengthCodeLength)
+	clCode := canonicalCode(clLengths)
+
+	count := 4
+	for i, symbol := range codeLengthCodeOrder {
+		if clLengths[symbol] > 0 && i+1 > count {
+			count = i + 1
+		}
+	}
+	b.writeBits(0, 1)
+	b.writeBits(uint32(count-4), 4)
+	for _, symbol := range codeLengthCodeOrder[:count] {
+		b.writeBits(uint32(clLengths[symbol]), 3)
+	}
+	b.writeBits(0, 1) // code lengths cover the whole alphabet
+	for _, t := range tokens {
+		clCode.write(b, t.symbol)
+		if t.extraBits > 0 {
+			b.writeBits(t.extra, t.extraBits)
+		}
+	}
+	return canonicalCode(lengths)
+}
+
+// codeLengthToken is a code length, or a run of zero lengths with its repeat count
+type codeLengthToken struct {
+	symbol    int
+	extra     uint32
+	extraBits uint
+}
+
+// codeLengthTokens run length encodes zero code lengths with symbols 17 and 18
+func codeLengthTokens(lengths []uint8) []codeLengthToken {
+	var tokens []codeLengthToken
+	for i := 0; i < len(lengths); {
+		if lengths[i] != 0 {
+			tokens = append(tokens, codeLengthToken{symbol: int(lengths[i])})
+			i++
+			continue
+		}
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == 0 && run < 138 {
+			run++
+		}
+		switch {
+		case run >= 11:
+			tokens = append(tokens, codeLengthToken{symbol: 18, extra: uint32(run - 11), extraBits: 7})
+		case run >= 3:
+			tokens = append(tokens, codeLengthToken{symbol: 17, extra: uint32(run - 3), extraBits: 3})
+		default:
+			run = 1
+			tokens = append(tokens, codeLengthToken{symbol: 0})
+		}
+		i += run
+	}
+	return tokens
+}
+
+// canonicalCode assigns canonical codes to code lengths, bit reversed for the writer.
+// A code with a single symbol takes no bits, as the decoder expects.
+func canonicalCode(lengths []uint8) prefixCode {
+	code := prefixCode{codes: make([]uint32, len(lengths)), lengths: make([]uint8, len(lengths))}
+	used := 0
+	for _, n := range lengths {
+		if n > 0 {
+			used++
+		}
+	}
+	if used <= 1 {
+		return code
+	}
+
+	var counts [maxCodeLength + 1]uint32
+	for _, n := range lengths {
+		counts[n]++
+	}
+	counts[0] = 0
+	var next [maxCodeLength + 1]uint32
+	var c uint32
+	for n := 1; n <= maxCodeLength; n++ {
+		c = (c + counts[n-1]) << 1
+		next[n] = c
+	}
+	for symbol, n := range lengths {
+		if n == 0 {
+			continue
+		}
+		code.codes[symbol] = reverse(next[n], n)
+		code.lengths[symbol] = n
+		next[n]++
+	}
+	return code
+}
+
+func reverse(code uint32, n uint8) uint32 {
+	var r uint32
+	for i := uint8(0); i < n; i++ {
+		r = r<<1 | code&1
+		code >>= 1
+	}
+	return r
+}
+
+// codeLengths builds Huffman code lengths no longer than limit. Counts are halved until the
+// tree is shallow enough, which always ends once every count is one.
+func codeLengths(histogram []int, limit int) []uint8 {
+	counts := append([]int(nil), histogram...)
+	for {
+		lengths, depth := huffman(counts)
+		if depth <= limit {
+			return lengths
+		}
+		for i, c := range counts {
+			if c > 0 {
+				counts[i] = (c + 1) / 2
+			}
+		}
+	}
+}
+
+type huffmanNode struct {
+	weight      int
+	symbol      int
+	left, right *huffmanNode
+}
+
+// huffmanQueue orders nodes by weight, then by symbol so that the output is deterministic
+type huffmanQueue []*huffmanNode
+
+func (q huffmanQueue) Len() int { return len(q) }
+func (q huffmanQueue) Less(i, j int) bool {
+	if q[i].weight != q[j].weight {
+		return q[i].weight < q[j].weight
+	}
+	return q[i].symbol < q[j].symbol
+}
+func (q huffmanQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *huffmanQueue) Push(x interface{}) { *q = append(*q, x.(*huffmanNode)) }
+func (q *huffmanQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// huffman returns the code lengths of a Huffman tree over the used symbols and its depth
+func huffman(counts []int) ([]uint8, int) {
+	lengths := make([]uint8, len(counts))
+	var q huffmanQueue
+	for symbol, c := range counts {
+		if c > 0 {
+			q = append(q, &huffmanNode{weight: c, symbol: symbol})
+		}
+	}
+	switch len(q) {
+	case 0:
+		return lengths, 0
+	case 1:
+		lengths[q[0].symbol] = 1
+		return lengths, 1
+	}
+
+	heap.Init(&q)
+	next := len(counts)
+	for q.Len() > 1 {
+		a := heap.Pop(&q).(*huffmanNode)
+		b := heap.Pop(&q).(*huffmanNode)
+		heap.Push(&q, &huffmanNode{weight: a.weight + b.weight, symbol: next, left: a, right: b})
+		next++
+	}
+
+	depth := 0
+	var walk func(n *huffmanNode, d int)
+	walk = func(n *huffmanNode, d int) {
+		if n.left == nil {
+			lengths[n.symbol] = uint8(d)
+			depth = max(depth, d)
+			return
+		}
+		walk(n.left, d+1)
+		walk(n.right, d+1)
+	}
+	walk(q[0], 0)
+	return lengths, depth
+}
@@ -0,0 +1,350 @@
+// File: pkg/jsonpatch/jsonpatch_test.go
+
+package jsonpatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// mustDecode decodes a JSON document the way request bodies are decoded
+func mustDecode(t *testing.T, data string) interface{} {
+	t.Helper()
+	value, err := decode([]byte(data))
+	if err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	return value
+}
+
+// assertJSON compares a document with the expected JSON text
+func assertJSON(t *testing.T, got interface{}, want string) {
+	t.Helper()
+	encoded, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("encoding result: %v", err)
+	}
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(encoded, &gotValue); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got %s, want %s", encoded, want)
+	}
+}
+
+// TestMergePatchRFC7396 runs the examples of RFC 7396 Appendix A
+func TestMergePatchRFC7396(t *testing.T) {
+	tests := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		// RFC 7396 section 3 の例
+		{
+			`{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"This will be unchanged"}`,
+			`{"title":"Hello!","phoneNumber":"+01-123-456-7890","author":{"familyName":null},"tags":["example"]}`,
+			`{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"This will be unchanged","phoneNumber":"+01-123-456-7890"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target+" + "+tt.patch, func(t *testing.T) {
+			patch, err := ParseMergePatch([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("ParseMergePatch: %v", err)
+			}
+			target := mustDecode(t, tt.target)
+			got, err := patch.Apply(target)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+			// 入力の文書は変更しない
+			assertJSON(t, target, tt.target)
+		})
+	}
+}
+
+// TestPatchRFC6902 runs the examples of RFC 6902 Appendix A. A.13 is left out: it is about
+// duplicate members, which encoding/json resolves before the patch is seen.
+func TestPatchRFC6902(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "A.1 adding an object member",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz","value":"qux"}]`,
+			want:  `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name:  "A.2 adding an array element",
+			doc:   `{"foo":["bar","baz"]}`,
+			patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			want:  `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name:  "A.3 removing an object member",
+			doc:   `{"baz":"qux","foo":"bar"}`,
+			patch: `[{"op":"remove","path":"/baz"}]`,
+			want:  `{"foo":"bar"}`,
+		},
+		{
+			name:  "A.4 removing an array element",
+			doc:   `{"foo":["bar","qux","baz"]}`,
+			patch: `[{"op":"remove","path":"/foo/1"}]`,
+			want:  `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:  "A.5 replacing a value",
+			doc:   `{"baz":"qux","foo":"bar"}`,
+			patch: `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			want:  `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:  "A.6 moving a value",
+			doc:   `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			want:  `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:  "A.7 moving an array element",
+			doc:   `{"foo":["all","grass","cows","eat"]}`,
+			patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			want:  `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name:  "A.8 testing a value: success",
+			doc:   `{"baz":"qux","foo":["a",2,"c"]}`,
+			patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			want:  `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name:    "A.9 testing a value: error",
+			doc:     `{"baz":"qux"}`,
+			patch:   `[{"op":"test","path":"/baz","value":"bar"}]`,
+			wantErr: true,
+		},
+		{
+			name:  "A.10 adding a nested member object",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+			want:  `{"foo":"bar","child":{"grandchild":{}}}`,
+		},
+		{
+			name:  "A.11 ignoring unrecognized elements",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`,
+			want:  `{"foo":"bar","baz":"qux"}`,
+		},
+		{
+			name:    "A.12 adding to a nonexistent target",
+			doc:     `{"foo":"bar"}`,
+			patch:   `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			wantErr: true,
+		},
+		{
+			name:  "A.14 ~ escape ordering",
+			doc:   `{"/":9,"~1":10}`,
+			patch: `[{"op":"test","path":"/~01","value":10}]`,
+			want:  `{"/":9,"~1":10}`,
+		},
+		{
+			name:    "A.15 comparing strings and numbers",
+			doc:     `{"/":9,"~1":10}`,
+			patch:   `[{"op":"test","path":"/~01","value":"10"}]`,
+			wantErr: true,
+		},
+		{
+			name:  "A.16 adding an array value",
+			doc:   `{"foo":["bar"]}`,
+			patch: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			want:  `{"foo":["bar",["abc","def"]]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runPatch(t, tt.doc, tt.patch, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestPatchEdgeCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "replace the whole document",
+			doc:   `{"a":1}`,
+			patch: `[{"op":"replace","path":"","value":[1]}]`,
+			want:  `[1]`,
+		},
+		{
+			name:  "copy a subtree",
+			doc:   `{"a":{"b":[1,2]}}`,
+			patch: `[{"op":"copy","from":"/a/b","path":"/c"}]`,
+			want:  `{"a":{"b":[1,2]},"c":[1,2]}`,
+		},
+		{
+			name:  "test an explicit null",
+			doc:   `{"a":null}`,
+			patch: `[{"op":"test","path":"/a","value":null}]`,
+			want:  `{"a":null}`,
+		},
+		{
+			name:  "test numbers by value",
+			doc:   `{"a":[1.0,{"b":10}]}`,
+			patch: `[{"op":"test","path":"/a","value":[1,{"b":1e1}]}]`,
+			want:  `{"a":[1.0,{"b":10}]}`,
+		},
+		{
+			name:    "a failing operation rejects the whole patch",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"add","path":"/b","value":2},{"op":"test","path":"/b","value":3}]`,
+			wantErr: true,
+		},
+		{
+			name:    "replace a missing member",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"replace","path":"/b","value":2}]`,
+			wantErr: true,
+		},
+		{
+			name:    "remove the whole document",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"remove","path":""}]`,
+			wantErr: true,
+		},
+		{
+			name:    "move a value into its child",
+			doc:     `{"a":{"b":1}}`,
+			patch:   `[{"op":"move","from":"/a","path":"/a/c"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "array index with a leading zero",
+			doc:     `{"a":[1,2]}`,
+			patch:   `[{"op":"remove","path":"/a/01"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "array index out of bounds",
+			doc:     `{"a":[1,2]}`,
+			patch:   `[{"op":"add","path":"/a/3","value":3}]`,
+			wantErr: true,
+		},
+		{
+			name:    "append with - outside add",
+			doc:     `{"a":[1,2]}`,
+			patch:   `[{"op":"remove","path":"/a/-"}]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runPatch(t, tt.doc, tt.patch, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func runPatch(t *testing.T, doc, patchText, want string, wantErr bool) {
+	t.Helper()
+	patch, err := ParsePatch([]byte(patchText))
+	if err != nil {
+		t.Fatalf("ParsePatch: %v", err)
+	}
+	target := mustDecode(t, doc)
+	got, err := patch.Apply(target)
+	if wantErr {
+		if err == nil {
+			t.Fatalf("got %v, want an error", got)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	assertJSON(t, got, want)
+	assertJSON(t, target, doc)
+}
+
+func TestParsePatchErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{"not an array", `{"op":"add","path":"/a","value":1}`},
+		{"operation not an object", `["add"]`},
+		{"unknown op", `[{"op":"merge","path":"/a"}]`},
+		{"missing path", `[{"op":"remove"}]`},
+		{"missing value", `[{"op":"add","path":"/a"}]`},
+		{"missing from", `[{"op":"move","path":"/a"}]`},
+		{"pointer without a leading slash", `[{"op":"remove","path":"a"}]`},
+		{"trailing data", `[] []`},
+		{"invalid JSON", `[{"op":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParsePatch([]byte(tt.patch)); err == nil {
+				t.Errorf("ParsePatch(%s) succeeded, want an error", tt.patch)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		body      string
+		wantErr   bool
+	}{
+		{MediaTypeMergePatch, `{"a":null}`, false},
+		{MediaTypeJSONPatch, `[{"op":"remove","path":"/a"}]`, false},
+		{MediaTypeJSONPatch, `{"a":null}`, true},
+		{"application/json", `{"a":null}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			patch, err := Parse(tt.mediaType, []byte(tt.body))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Parse succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got, err := patch.Apply(map[string]interface{}{"a": "x", "b": "y"})
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			assertJSON(t, got, `{"b":"y"}`)
+		})
+	}
+}
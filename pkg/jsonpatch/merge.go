@@ -0,0 +1,96 @@
+// File: pkg/jsonpatch/merge.go
+
+// Package jsonpatch applies JSON Merge Patch (RFC 7396) and JSON Patch (RFC 6902)
+// documents to decoded JSON values.
+//
+// Documents are the values encoding/json decodes into interface{}: nil, bool,
+// json.Number or float64, string, []interface{} and map[string]interface{}.
+// Inputs are never modified; patched documents share unchanged subtrees with them.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// MediaTypeMergePatch is the content type of JSON Merge Patch documents
+	MediaTypeMergePatch = "application/merge-patch+json"
+	// MediaTypeJSONPatch is the content type of JSON Patch documents
+	MediaTypeJSONPatch = "application/json-patch+json"
+)
+
+// Patcher is a parsed patch document
+type Patcher interface {
+	Apply(doc interface{}) (interface{}, error)
+}
+
+// Parse decodes a patch document of the given media type
+func Parse(mediaType string, data []byte) (Patcher, error) {
+	switch mediaType {
+	case MediaTypeMergePatch:
+		return ParseMergePatch(data)
+	case MediaTypeJSONPatch:
+		return ParsePatch(data)
+	}
+	return nil, fmt.Errorf("unsupported patch media type %q", mediaType)
+}
+
+// MergePatch is an RFC 7396 merge patch
+type MergePatch struct {
+	patch interface{}
+}
+
+// ParseMergePatch decodes a merge patch document
+func ParseMergePatch(data []byte) (*MergePatch, error) {
+	patch, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return &MergePatch{patch: patch}, nil
+}
+
+// Apply merges the patch into doc. Object members set to null are removed;
+// any other patch value replaces the target as a whole.
+func (m *MergePatch) Apply(doc interface{}) (interface{}, error) {
+	return merge(doc, m.patch), nil
+}
+
+func merge(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	result := make(map[string]interface{}, len(targetObject)+len(patchObject))
+	if ok {
+		for name, value := range targetObject {
+			result[name] = value
+		}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(result, name)
+			continue
+		}
+		result[name] = merge(result[name], value)
+	}
+	return result
+}
+
+// decode reads a single JSON value keeping numbers as json.Number
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid patch document: %v", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("invalid patch document: trailing data")
+	}
+	return value, nil
+}
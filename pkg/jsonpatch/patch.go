@@ -0,0 +1,363 @@
+// File: pkg/jsonpatch/patch.go
+
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Operation is one step of a JSON Patch
+type Operation struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+}
+
+// Patch is an RFC 6902 JSON Patch. Operations apply in order and the patch
+// fails as a whole when any of them fails.
+type Patch []Operation
+
+// ParsePatch decodes a JSON Patch document and checks its operations
+func ParsePatch(data []byte) (Patch, error) {
+	raw, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid patch document: must be an array of operations")
+	}
+
+	patch := make(Patch, len(items))
+	for i, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("operation %d: must be an object", i)
+		}
+		op := Operation{}
+		op.Op, _ = object["op"].(string)
+		path, ok := object["path"].(string)
+		if !ok {
+			return nil, fmt.Errorf("operation %d: path is required", i)
+		}
+		op.Path = path
+		// 明示的な null と value の欠落を区別する
+		var hasValue bool
+		op.Value, hasValue = object["value"]
+
+		switch op.Op {
+		case "add", "replace", "test":
+			if !hasValue {
+				return nil, fmt.Errorf("operation %d: %s requires a value", i, op.Op)
+			}
+		case "move", "copy":
+			from, ok := object["from"].(string)
+			if !ok {
+				return nil, fmt.Errorf("operation %d: %s requires from", i, op.Op)
+			}
+			op.From = from
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d: unknown op %q", i, op.Op)
+		}
+		if _, err := parsePointer(op.Path); err != nil {
+			return nil, fmt.Errorf("operation %d: %v", i, err)
+		}
+		if op.Op == "move" || op.Op == "copy" {
+			if _, err := parsePointer(op.From); err != nil {
+				return nil, fmt.Errorf("operation %d: %v", i, err)
+			}
+		}
+		patch[i] = op
+	}
+	return patch, nil
+}
+
+// Apply runs the operations against doc
+func (p Patch) Apply(doc interface{}) (interface{}, error) {
+	var err error
+	for i, op := range p {
+		doc, err = op.apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %v", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func (op Operation) apply(doc interface{}) (interface{}, error) {
+	path, _ := parsePointer(op.Path)
+	switch op.Op {
+	case "add":
+		return add(doc, path, op.Value)
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+	case "replace":
+		if len(path) == 0 {
+			return op.Value, nil
+		}
+		if _, err := get(doc, path); err != nil {
+			return nil, err
+		}
+		doc, _, err := remove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, op.Value)
+	case "move":
+		from, _ := parsePointer(op.From)
+		if isPrefix(from, path) && len(from) < len(path) {
+			return nil, fmt.Errorf("cannot move a value into one of its children")
+		}
+		doc, value, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	case "copy":
+		from, _ := parsePointer(op.From)
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	case "test":
+		value, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(value, op.Value) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		// ~1 を先に戻すと "~01" が "/" になってしまう
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q does not exist", token)
+			}
+			doc = value
+		case []interface{}:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("cannot reference %q in a scalar value", token)
+		}
+	}
+	return doc, nil
+}
+
+// add returns a copy of doc with value added at path. Object members are set,
+// array elements are inserted and "-" appends to an array.
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token, rest := path[0], path[1:]
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		result := copyObject(node)
+		if len(rest) == 0 {
+			result[token] = value
+			return result, nil
+		}
+		child, ok := node[token]
+		if !ok {
+			return nil, fmt.Errorf("member %q does not exist", token)
+		}
+		updated, err := add(child, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		result[token] = updated
+		return result, nil
+
+	case []interface{}:
+		if len(rest) == 0 {
+			i := len(node)
+			if token != "-" {
+				var err error
+				if i, err = arrayIndex(token, len(node)); err != nil {
+					return nil, err
+				}
+			}
+			result := make([]interface{}, 0, len(node)+1)
+			result = append(result, node[:i]...)
+			result = append(result, value)
+			return append(result, node[i:]...), nil
+		}
+		i, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := add(node[i], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		result := append([]interface{}(nil), node...)
+		result[i] = updated
+		return result, nil
+	}
+	return nil, fmt.Errorf("cannot reference %q in a scalar value", token)
+}
+
+// remove returns a copy of doc without the value at path, and the removed value
+func remove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+	token, rest := path[0], path[1:]
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("member %q does not exist", token)
+		}
+		result := copyObject(node)
+		if len(rest) == 0 {
+			delete(result, token)
+			return result, child, nil
+		}
+		updated, removed, err := remove(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		result[token] = updated
+		return result, removed, nil
+
+	case []interface{}:
+		i, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			result := make([]interface{}, 0, len(node)-1)
+			result = append(result, node[:i]...)
+			return append(result, node[i+1:]...), node[i], nil
+		}
+		updated, removed, err := remove(node[i], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		result := append([]interface{}(nil), node...)
+		result[i] = updated
+		return result, removed, nil
+	}
+	return nil, nil, fmt.Errorf("cannot reference %q in a scalar value", token)
+}
+
+// arrayIndex parses an array reference token no larger than max
+func arrayIndex(token string, max int) (int, error) {
+	// 先頭ゼロや符号付きの添字は RFC 6901 で許されない
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.ContainsAny(token, "+-") {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index %d is out of bounds", i)
+	}
+	return i, nil
+}
+
+func copyObject(object map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(object)+1)
+	for name, value := range object {
+		result[name] = value
+	}
+	return result
+}
+
+// equal compares two JSON values as the test operation requires: numbers by value,
+// arrays element-wise and objects regardless of member order
+func equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for name, value := range x {
+			other, ok := y[name]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if n, ok := number(a); ok {
+		m, ok := number(b)
+		return ok && n.Cmp(m) == 0
+	}
+	return a == b
+}
+
+func number(v interface{}) (*big.Float, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, _, err := big.ParseFloat(string(n), 10, 256, big.ToNearestEven)
+		return f, err == nil
+	case float64:
+		return big.NewFloat(n), true
+	}
+	return nil, false
+}
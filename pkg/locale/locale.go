@@ -0,0 +1,132 @@
+// File: pkg/locale/locale.go
+
+package locale
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tagPattern accepts BCP 47 style tags such as ja, en or pt-br
+var tagPattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// Normalize lowercases a tag and replaces underscores, so that ja_JP and ja-jp compare equal
+func Normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// IsValid reports whether the normalized tag is well formed
+func IsValid(tag string) bool {
+	return tagPattern.MatchString(tag)
+}
+
+// Settings are the locales content may be written in and the chain that reads fall back
+// through when the requested locale has no translation
+type Settings struct {
+	Supported []string
+	Fallback  []string
+}
+
+// NewSettings normalizes and checks the locale lists. An empty fallback chain falls back
+// through the supported locales in order.
+func NewSettings(supported, fallback []string) (Settings, error) {
+	var s Settings
+	seen := make(map[string]bool)
+	for _, tag := range supported {
+		tag = Normalize(tag)
+		if !IsValid(tag) {
+			return Settings{}, fmt.Errorf("invalid locale: %q", tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			s.Supported = append(s.Supported, tag)
+		}
+	}
+	if len(s.Supported) == 0 {
+		return Settings{}, fmt.Errorf("at least one locale is required")
+	}
+
+	for _, tag := range fallback {
+		tag = Normalize(tag)
+		if !seen[tag] {
+			return Settings{}, fmt.Errorf("fallback locale %q is not supported", tag)
+		}
+		s.Fallback = append(s.Fallback, tag)
+	}
+	if len(s.Fallback) == 0 {
+		s.Fallback = s.Supported
+	}
+	return s, nil
+}
+
+// Default is the locale of reads that ask for none, and of existing values when a column becomes localized
+func (s Settings) Default() string {
+	return s.Fallback[0]
+}
+
+// IsSupported reports whether tag is one of the configured locales
+func (s Settings) IsSupported(tag string) bool {
+	for _, supported := range s.Supported {
+		if supported == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve picks the translation for tag, falling back through the chain. Empty translations
+// count as missing. It returns the locale the value was taken from, or false when none has one.
+func (s Settings) Resolve(translations map[string]string, tag string) (string, string, bool) {
+	for _, candidate := range append([]string{tag}, s.Fallback...) {
+		if value := translations[candidate]; value != "" {
+			return value, candidate, true
+		}
+	}
+	return "", "", false
+}
+
+// Negotiate picks the supported locale an Accept-Language header prefers.
+// A range such as ja-JP also matches ja; the default locale is used when nothing matches.
+func (s Settings) Negotiate(acceptLanguage string) string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+	var ranges []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		tag := Normalize(fields[0])
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if v, err := strconv.ParseFloat(q, 64); err == nil {
+					quality = v
+				}
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, weighted{tag: tag, quality: quality})
+		}
+	}
+	// 同じ q 値はヘッダーに書かれた順を保つ
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	for _, r := range ranges {
+		if r.tag == "*" {
+			return s.Default()
+		}
+		if s.IsSupported(r.tag) {
+			return r.tag
+		}
+		if primary, _, ok := strings.Cut(r.tag, "-"); ok && s.IsSupported(primary) {
+			return primary
+		}
+	}
+	return s.Default()
+}
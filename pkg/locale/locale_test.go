@@ -0,0 +1,157 @@
+// File: pkg/locale/locale_test.go
+
+package locale
+
+import (
+	"reflect"
+	"testing"
+)
+
+func mustSettings(t *testing.T, supported, fallback []string) Settings {
+	t.Helper()
+	s, err := NewSettings(supported, fallback)
+	if err != nil {
+		t.Fatalf("NewSettings: %v", err)
+	}
+	return s
+}
+
+func TestNewSettings(t *testing.T) {
+	tests := []struct {
+		name          string
+		supported     []string
+		fallback      []string
+		wantSupported []string
+		wantFallback  []string
+		wantErr       bool
+	}{
+		{
+			name:          "fallback defaults to the supported order",
+			supported:     []string{"ja", "en"},
+			wantSupported: []string{"ja", "en"},
+			wantFallback:  []string{"ja", "en"},
+		},
+		{
+			name:          "tags are normalized and deduplicated",
+			supported:     []string{"ja_JP", " EN ", "ja-jp"},
+			fallback:      []string{"EN"},
+			wantSupported: []string{"ja-jp", "en"},
+			wantFallback:  []string{"en"},
+		},
+		{name: "no locales", wantErr: true},
+		{name: "invalid tag", supported: []string{"japanese!"}, wantErr: true},
+		{name: "unsupported fallback", supported: []string{"ja"}, fallback: []string{"en"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSettings(tt.supported, tt.fallback)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(s.Supported, tt.wantSupported) {
+				t.Errorf("Supported = %v, want %v", s.Supported, tt.wantSupported)
+			}
+			if !reflect.DeepEqual(s.Fallback, tt.wantFallback) {
+				t.Errorf("Fallback = %v, want %v", s.Fallback, tt.wantFallback)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	s := mustSettings(t, []string{"ja", "en", "pt-br"}, []string{"en", "ja"})
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", "en"},
+		{"ja", "ja"},
+		{"JA_jp", "ja"},
+		{"ja-JP, en;q=0.8", "ja"},
+		{"fr, en;q=0.5", "en"},
+		{"en;q=0.5, ja;q=0.9", "ja"},
+		{"pt-BR", "pt-br"},
+		// pt-br からは pt に落ちるが、pt から pt-br へは広げない
+		{"pt", "en"},
+		{"fr, de", "en"},
+		{"*", "en"},
+		{"ja;q=0, en", "en"},
+		{"de;q=0.9, *;q=0.5, ja;q=0.1", "en"},
+		// 同じ q 値は書かれた順
+		{"ja;q=0.5, en;q=0.5", "ja"},
+		{"ja;q=abc", "ja"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			if got := s.Negotiate(tt.acceptLanguage); got != tt.want {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	s := mustSettings(t, []string{"ja", "en", "fr"}, []string{"en", "ja"})
+	tests := []struct {
+		name         string
+		translations map[string]string
+		tag          string
+		wantValue    string
+		wantLocale   string
+		wantOK       bool
+	}{
+		{
+			name:         "requested locale",
+			translations: map[string]string{"ja": "こんにちは", "en": "Hello"},
+			tag:          "ja",
+			wantValue:    "こんにちは",
+			wantLocale:   "ja",
+			wantOK:       true,
+		},
+		{
+			name:         "falls back through the chain",
+			translations: map[string]string{"ja": "こんにちは"},
+			tag:          "fr",
+			wantValue:    "こんにちは",
+			wantLocale:   "ja",
+			wantOK:       true,
+		},
+		{
+			name:         "fallback order wins over the supported order",
+			translations: map[string]string{"ja": "こんにちは", "en": "Hello"},
+			tag:          "fr",
+			wantValue:    "Hello",
+			wantLocale:   "en",
+			wantOK:       true,
+		},
+		{
+			name:         "empty translation counts as missing",
+			translations: map[string]string{"fr": "", "en": "Hello"},
+			tag:          "fr",
+			wantValue:    "Hello",
+			wantLocale:   "en",
+			wantOK:       true,
+		},
+		{
+			name:         "locale outside the chain is not used",
+			translations: map[string]string{"fr": "Bonjour"},
+			tag:          "ja",
+		},
+		{
+			name: "no translations",
+			tag:  "en",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, locale, ok := s.Resolve(tt.translations, tt.tag)
+			if value != tt.wantValue || locale != tt.wantLocale || ok != tt.wantOK {
+				t.Errorf("Resolve = (%q, %q, %v), want (%q, %q, %v)",
+					value, locale, ok, tt.wantValue, tt.wantLocale, tt.wantOK)
+			}
+		})
+	}
+}